@@ -51,6 +51,9 @@ type ServerConfig struct {
 	SessionDuration      time.Duration `mapstructure:"session_duration" yaml:"session_duration" default:"720h"`
 	AllowInsecureNoAuth  bool          `mapstructure:"allow_insecure_no_auth" yaml:"allow_insecure_no_auth"`
 	TrustedProxies       []string      `mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
+	// RequireTaskVersion rejects task edits that do not name the version they
+	// were made to. Off, such edits apply to the current version.
+	RequireTaskVersion bool `mapstructure:"require_task_version" yaml:"require_task_version"`
 }
 
 type SchedulerConfig struct {
@@ -58,6 +61,16 @@ type SchedulerConfig struct {
 	OverdueFrequency         time.Duration `mapstructure:"overdue_frequency" yaml:"overdue_frequency" default:"1d"`
 	NotificationCleanup      time.Duration `mapstructure:"notification_cleanup" yaml:"notification_cleanup" default:"10m"`
 	AccountDeletionFrequency time.Duration `mapstructure:"account_deletion_frequency" yaml:"account_deletion_frequency" default:"15m"`
+	HabitPeriodFrequency     time.Duration `mapstructure:"habit_period_frequency" yaml:"habit_period_frequency" default:"5m"`
+	ArchiveCleanupFrequency  time.Duration `mapstructure:"archive_cleanup_frequency" yaml:"archive_cleanup_frequency" default:"1h"`
+	// ArchiveRetention is how long archived tasks are kept before the archive
+	// cleanup deletes them. Zero keeps them forever.
+	ArchiveRetention      time.Duration `mapstructure:"archive_retention" yaml:"archive_retention"`
+	TrashCleanupFrequency time.Duration `mapstructure:"trash_cleanup_frequency" yaml:"trash_cleanup_frequency" default:"1h"`
+	// TrashRetention is how long deleted tasks stay in the trash, and can be
+	// restored, before the trash cleanup deletes them for good. Zero keeps
+	// them forever.
+	TrashRetention time.Duration `mapstructure:"trash_retention" yaml:"trash_retention"`
 }
 
 func LoadConfig(configFile string) *Config {
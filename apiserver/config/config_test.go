@@ -36,6 +36,10 @@ entra:
 scheduler_jobs:
   due_frequency: 5m
   overdue_frequency: 24h
+  archive_cleanup_frequency: 30m
+  archive_retention: 720h
+  trash_cleanup_frequency: 15m
+  trash_retention: 168h
 `)
 
 	assert.NoError(t, err)
@@ -60,6 +64,10 @@ scheduler_jobs:
 
 	assert.Equal(t, 5*time.Minute, cfg.SchedulerJobs.DueFrequency)
 	assert.Equal(t, 24*time.Hour, cfg.SchedulerJobs.OverdueFrequency)
+	assert.Equal(t, 30*time.Minute, cfg.SchedulerJobs.ArchiveCleanupFrequency)
+	assert.Equal(t, 720*time.Hour, cfg.SchedulerJobs.ArchiveRetention)
+	assert.Equal(t, 15*time.Minute, cfg.SchedulerJobs.TrashCleanupFrequency)
+	assert.Equal(t, 168*time.Hour, cfg.SchedulerJobs.TrashRetention)
 }
 
 func TestParseTrustedProxies(t *testing.T) {
@@ -0,0 +1,142 @@
+package apis
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	authMW "taskwiz.app/core/internal/middleware/auth"
+	models "taskwiz.app/core/internal/models"
+	cService "taskwiz.app/core/internal/services/calendars"
+	"taskwiz.app/core/internal/telemetry"
+	auth "taskwiz.app/core/internal/utils/auth"
+	middleware "taskwiz.app/core/internal/utils/middleware"
+)
+
+const maxCalendarFileSize = 1 << 20
+
+type CalendarsAPIHandler struct {
+	cs *cService.CalendarService
+}
+
+func CalendarsAPI(cs *cService.CalendarService) *CalendarsAPIHandler {
+	return &CalendarsAPIHandler{
+		cs: cs,
+	}
+}
+
+func (h *CalendarsAPIHandler) getCalendars(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+	status, response := h.cs.GetUserCalendars(c, currentIdentity.UserID)
+	c.JSON(status, response)
+}
+
+func (h *CalendarsAPIHandler) createCalendar(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.CreateHolidayCalendarReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "calendar_bind_failed", "calendar-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.cs.CreateCalendar(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+// importCalendar creates a calendar from an uploaded iCalendar file, sent as
+// the multipart field "file" alongside the calendar "name".
+func (h *CalendarsAPIHandler) importCalendar(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		telemetry.TrackWarning(c, "calendar_bind_failed", "calendar-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Calendar file is required",
+		})
+		return
+	}
+	if fileHeader.Size > maxCalendarFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "Calendar file is too large",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		telemetry.TrackWarning(c, "calendar_bind_failed", "calendar-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to read calendar file",
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCalendarFileSize))
+	if err != nil {
+		telemetry.TrackWarning(c, "calendar_bind_failed", "calendar-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to read calendar file",
+		})
+		return
+	}
+
+	req := models.CreateHolidayCalendarReq{
+		Name: c.PostForm("name"),
+		ICS:  string(data),
+	}
+
+	status, response := h.cs.CreateCalendar(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+func (h *CalendarsAPIHandler) updateCalendar(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.UpdateHolidayCalendarReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "calendar_bind_failed", "calendar-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.cs.UpdateCalendar(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+func (h *CalendarsAPIHandler) deleteCalendar(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	calendarIDRaw := c.Param("id")
+	calendarID, err := strconv.Atoi(calendarIDRaw)
+	if err != nil {
+		telemetry.TrackWarning(c, "calendar_invalid_param", "calendar-handler", "Invalid calendar ID: "+calendarIDRaw, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid calendar ID",
+		})
+		return
+	}
+
+	status, response := h.cs.DeleteCalendar(c, currentIdentity.UserID, calendarID)
+	c.JSON(status, response)
+}
+
+func CalendarRoutes(r *gin.Engine, h *CalendarsAPIHandler, authGate *authMW.AuthMiddleware) {
+	calendarRoutes := r.Group("api/v1/calendars")
+	calendarRoutes.Use(authGate.MiddlewareFunc(), middleware.DeletionGuardMiddleware())
+	{
+		calendarRoutes.GET("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getCalendars)
+		calendarRoutes.POST("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.createCalendar)
+		calendarRoutes.POST("/import", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.importCalendar)
+		calendarRoutes.PUT("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.updateCalendar)
+		calendarRoutes.DELETE("/:id", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteCalendar)
+	}
+}
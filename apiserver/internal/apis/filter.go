@@ -0,0 +1,120 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	authMW "taskwiz.app/core/internal/middleware/auth"
+	models "taskwiz.app/core/internal/models"
+	fService "taskwiz.app/core/internal/services/filters"
+	"taskwiz.app/core/internal/telemetry"
+	auth "taskwiz.app/core/internal/utils/auth"
+	middleware "taskwiz.app/core/internal/utils/middleware"
+)
+
+type FiltersAPIHandler struct {
+	fs *fService.FilterService
+}
+
+func FiltersAPI(fs *fService.FilterService) *FiltersAPIHandler {
+	return &FiltersAPIHandler{
+		fs: fs,
+	}
+}
+
+func (h *FiltersAPIHandler) getFilters(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+	status, response := h.fs.GetUserFilters(c, currentIdentity.UserID)
+	c.JSON(status, response)
+}
+
+func (h *FiltersAPIHandler) createFilter(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.CreateSavedFilterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "filter_bind_failed", "filter-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.fs.CreateFilter(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+func (h *FiltersAPIHandler) updateFilter(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.UpdateSavedFilterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "filter_bind_failed", "filter-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.fs.UpdateFilter(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+func (h *FiltersAPIHandler) deleteFilter(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	filterIDRaw := c.Param("id")
+	filterID, err := strconv.Atoi(filterIDRaw)
+	if err != nil {
+		telemetry.TrackWarning(c, "filter_invalid_param", "filter-handler", "Invalid filter ID: "+filterIDRaw, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid filter ID",
+		})
+		return
+	}
+
+	status, response := h.fs.DeleteFilter(c, currentIdentity.UserID, filterID)
+	c.JSON(status, response)
+}
+
+// evaluateFilter lists the tasks matching a saved filter, a page at a time
+// with the cursor and limit query parameters of the task list.
+func (h *FiltersAPIHandler) evaluateFilter(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	filterIDRaw := c.Param("id")
+	filterID, err := strconv.Atoi(filterIDRaw)
+	if err != nil {
+		telemetry.TrackWarning(c, "filter_invalid_param", "filter-handler", "Invalid filter ID: "+filterIDRaw, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid filter ID",
+		})
+		return
+	}
+
+	var req models.EvaluateSavedFilterReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		telemetry.TrackWarning(c, "filter_bind_failed", "filter-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+	req.ID = filterID
+
+	status, response := h.fs.EvaluateFilter(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
+func FilterRoutes(r *gin.Engine, h *FiltersAPIHandler, authGate *authMW.AuthMiddleware) {
+	filterRoutes := r.Group("api/v1/filters")
+	filterRoutes.Use(authGate.MiddlewareFunc(), middleware.DeletionGuardMiddleware())
+	{
+		filterRoutes.GET("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getFilters)
+		filterRoutes.POST("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.createFilter)
+		filterRoutes.PUT("", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.updateFilter)
+		filterRoutes.DELETE("/:id", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteFilter)
+		filterRoutes.GET("/:id/tasks", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.evaluateFilter)
+	}
+}
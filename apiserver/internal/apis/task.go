@@ -1,10 +1,10 @@
 package apis
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	limiter "github.com/ulule/limiter/v3"
@@ -29,65 +29,48 @@ func TasksAPI(tService *tService.TaskService) *TasksAPIHandler {
 func (h *TasksAPIHandler) getTasks(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 
-	status, response := h.tService.GetUserTasks(c, currentIdentity.UserID)
-	c.JSON(status, response)
-}
-
-func (h *TasksAPIHandler) getTasksDueBefore(c *gin.Context) {
-	currentIdentity := auth.CurrentIdentity(c)
-
-	raw := c.Query("before")
-	if raw == "" {
-		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Missing 'before' query parameter", nil)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "'before' query parameter is required",
-		})
-		return
-	}
-
-	before, err := time.Parse(time.RFC3339, raw)
-	if err != nil {
-		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid 'before' format: "+raw, nil)
+	var req models.ListTasksReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "'before' must be in RFC 3339 / ISO 8601 format (e.g. 2025-01-15T00:00:00Z)",
+			"error": err,
 		})
 		return
 	}
 
-	status, response := h.tService.GetTasksDueBefore(c, currentIdentity.UserID, before.UTC())
+	status, response := h.tService.ListTasks(c, currentIdentity.UserID, req)
 	c.JSON(status, response)
 }
 
-func (h *TasksAPIHandler) getTasksByLabel(c *gin.Context) {
+func (h *TasksAPIHandler) getArchivedTasks(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 
-	rawID := c.Param("labelId")
-	labelID, err := strconv.Atoi(rawID)
-	if err != nil {
-		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid label ID: "+rawID, nil)
+	var req models.ListTasksReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid label ID",
+			"error": err,
 		})
 		return
 	}
 
-	status, response := h.tService.GetTasksByLabel(c, currentIdentity.UserID, labelID)
+	status, response := h.tService.ListArchivedTasks(c, currentIdentity.UserID, req)
 	c.JSON(status, response)
 }
 
-func (h *TasksAPIHandler) searchTasks(c *gin.Context) {
+func (h *TasksAPIHandler) getDeletedTasks(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 
-	query := strings.TrimSpace(c.Query("q"))
-	if query == "" {
-		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Missing 'q' query parameter", nil)
+	var req models.ListTasksReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "'q' query parameter is required",
+			"error": err,
 		})
 		return
 	}
 
-	status, response := h.tService.SearchTasksByTitle(c, currentIdentity.UserID, query)
+	status, response := h.tService.ListDeletedTasks(c, currentIdentity.UserID, req)
 	c.JSON(status, response)
 }
 
@@ -111,7 +94,22 @@ func (h *TasksAPIHandler) getActivity(c *gin.Context) {
 		return
 	}
 
-	status, response := h.tService.GetRecentActivity(c, currentIdentity.UserID, beforeID, limit)
+	status, response := h.tService.GetRecentActivity(c, currentIdentity.UserID, c.Query("cursor"), beforeID, limit)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) searchTasks(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid limit: "+limitStr, nil)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	status, response := h.tService.SearchTasks(c, currentIdentity.UserID, c.Query("q"), limit)
 	c.JSON(status, response)
 }
 
@@ -129,6 +127,37 @@ func (h *TasksAPIHandler) getTask(c *gin.Context) {
 	}
 
 	status, response := h.tService.GetTask(c, currentIdentity.UserID, id)
+	respondWithTask(c, status, response)
+}
+
+// taskETag is the entity tag of a task at the given version.
+func taskETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the task version named by the request's If-Match
+// header, or 0 if it has none.
+func ifMatchVersion(c *gin.Context) (int, error) {
+	raw := c.GetHeader("If-Match")
+	if raw == "" {
+		return 0, nil
+	}
+	tag := strings.Trim(strings.TrimPrefix(strings.TrimSpace(raw), "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, errors.New("invalid If-Match header")
+	}
+	return version, nil
+}
+
+// respondWithTask writes a response and, if it carries a task, sets the
+// task's version as the ETag.
+func respondWithTask(c *gin.Context, status int, response interface{}) {
+	if body, ok := response.(gin.H); ok {
+		if task, ok := body["task"].(*models.Task); ok {
+			c.Header("ETag", taskETag(task.Version))
+		}
+	}
 	c.JSON(status, response)
 }
 
@@ -148,6 +177,34 @@ func (h *TasksAPIHandler) createTask(c *gin.Context) {
 	c.JSON(status, response)
 }
 
+func (h *TasksAPIHandler) previewOccurrences(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.PreviewOccurrencesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if rawCount := c.Query("count"); rawCount != "" {
+		count, err := strconv.Atoi(rawCount)
+		if err != nil {
+			telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid count: "+rawCount, nil)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid count",
+			})
+			return
+		}
+		req.Count = count
+	}
+
+	status, response := h.tService.PreviewOccurrences(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
 func (h *TasksAPIHandler) editTask(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 
@@ -160,8 +217,55 @@ func (h *TasksAPIHandler) editTask(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid If-Match: "+c.GetHeader("If-Match"), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid If-Match header",
+		})
+		return
+	}
+	if version > 0 {
+		TaskReq.Version = version
+	}
+
 	status, response := h.tService.EditTask(c, currentIdentity.UserID, TaskReq)
-	c.JSON(status, response)
+	respondWithTask(c, status, response)
+}
+
+func (h *TasksAPIHandler) patchTask(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid If-Match: "+c.GetHeader("If-Match"), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid If-Match header",
+		})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.tService.PatchTask(c, currentIdentity.UserID, id, version, patch)
+	respondWithTask(c, status, response)
 }
 
 func (h *TasksAPIHandler) deleteTask(c *gin.Context) {
@@ -181,6 +285,23 @@ func (h *TasksAPIHandler) deleteTask(c *gin.Context) {
 	c.JSON(status, response)
 }
 
+func (h *TasksAPIHandler) restoreDeletedTask(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.RestoreDeletedTask(c, currentIdentity.UserID, id)
+	c.JSON(status, response)
+}
+
 func (h *TasksAPIHandler) skipTask(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 
@@ -221,7 +342,45 @@ func (h *TasksAPIHandler) updateDueDate(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid If-Match: "+c.GetHeader("If-Match"), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid If-Match header",
+		})
+		return
+	}
+	if version > 0 {
+		dueDateReq.Version = version
+	}
+
 	status, response := h.tService.UpdateDueDate(c, currentIdentity.UserID, id, dueDateReq)
+	respondWithTask(c, status, response)
+}
+
+func (h *TasksAPIHandler) restoreTask(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.RestoreTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.RestoreTask(c, currentIdentity.UserID, id, req)
 	c.JSON(status, response)
 }
 
@@ -248,7 +407,33 @@ func (h *TasksAPIHandler) completeTask(c *gin.Context) {
 		return
 	}
 
-	status, response := h.tService.CompleteTask(c, currentIdentity.UserID, id, endRecurrence)
+	status, response := h.tService.CompleteTask(c, currentIdentity.UserID, id, endRecurrence, c.Query("completed_date"))
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) backfillTask(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.BackfillTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.BackfillTask(c, currentIdentity.UserID, id, req)
 	c.JSON(status, response)
 }
 
@@ -277,7 +462,19 @@ func (h *TasksAPIHandler) revertAction(c *gin.Context) {
 		}
 	}
 
-	status, response := h.tService.RevertAction(c, currentIdentity.UserID, id, historyID)
+	steps := 1
+	if stepsStr := c.Query("undo_steps"); stepsStr != "" {
+		steps, err = strconv.Atoi(stepsStr)
+		if err != nil || steps <= 0 {
+			telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid undo_steps: "+stepsStr, nil)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid undo_steps value",
+			})
+			return
+		}
+	}
+
+	status, response := h.tService.RevertAction(c, currentIdentity.UserID, id, historyID, steps)
 	c.JSON(status, response)
 }
 
@@ -298,23 +495,367 @@ func (h *TasksAPIHandler) GetTaskHistory(c *gin.Context) {
 	c.JSON(status, response)
 }
 
+func (h *TasksAPIHandler) getTaskAudit(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.GetTaskAudit(c, currentIdentity.UserID, id)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) updateTaskHistory(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.UpdateTaskHistoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawHistoryID := c.Param("historyId")
+	historyID, err := strconv.Atoi(rawHistoryID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid history ID: "+rawHistoryID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid history ID",
+		})
+		return
+	}
+
+	status, response := h.tService.UpdateTaskHistory(c, currentIdentity.UserID, id, historyID, req)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) deleteTaskHistory(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawHistoryID := c.Param("historyId")
+	historyID, err := strconv.Atoi(rawHistoryID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid history ID: "+rawHistoryID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid history ID",
+		})
+		return
+	}
+
+	status, response := h.tService.DeleteTaskHistory(c, currentIdentity.UserID, id, historyID)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) getOccurrenceOverrides(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.GetOccurrenceOverrides(c, currentIdentity.UserID, id)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) setOccurrenceOverride(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.OccurrenceOverrideReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.SetOccurrenceOverride(c, currentIdentity.UserID, id, req)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) deleteOccurrenceOverride(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawOverrideID := c.Param("overrideId")
+	overrideID, err := strconv.Atoi(rawOverrideID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid override ID: "+rawOverrideID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid override ID",
+		})
+		return
+	}
+
+	status, response := h.tService.DeleteOccurrenceOverride(c, currentIdentity.UserID, id, overrideID)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) getChecklist(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.GetChecklist(c, currentIdentity.UserID, id)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) addChecklistItem(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.CreateChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.AddChecklistItem(c, currentIdentity.UserID, id, req)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) updateChecklistItem(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.UpdateChecklistItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawItemID := c.Param("itemId")
+	itemID, err := strconv.Atoi(rawItemID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid checklist item ID: "+rawItemID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid checklist item ID",
+		})
+		return
+	}
+
+	status, response := h.tService.UpdateChecklistItem(c, currentIdentity.UserID, id, itemID, req)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) deleteChecklistItem(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawItemID := c.Param("itemId")
+	itemID, err := strconv.Atoi(rawItemID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid checklist item ID: "+rawItemID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid checklist item ID",
+		})
+		return
+	}
+
+	status, response := h.tService.DeleteChecklistItem(c, currentIdentity.UserID, id, itemID)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) getDependencies(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.GetDependencies(c, currentIdentity.UserID, id)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) addDependency(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.AddDependencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "task_bind_failed", "task-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	status, response := h.tService.AddDependency(c, currentIdentity.UserID, id, req)
+	c.JSON(status, response)
+}
+
+func (h *TasksAPIHandler) removeDependency(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	rawID := c.Param("id")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid task ID: "+rawID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task ID",
+		})
+		return
+	}
+
+	rawBlockerID := c.Param("blockerId")
+	blockerID, err := strconv.Atoi(rawBlockerID)
+	if err != nil {
+		telemetry.TrackWarning(c, "task_invalid_param", "task-handler", "Invalid blocker ID: "+rawBlockerID, nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid blocker ID",
+		})
+		return
+	}
+
+	status, response := h.tService.RemoveDependency(c, currentIdentity.UserID, id, blockerID)
+	c.JSON(status, response)
+}
+
 func TaskRoutes(router *gin.Engine, h *TasksAPIHandler, auth *authMW.AuthMiddleware, limiter *limiter.Limiter) {
 	tasksRoutes := router.Group("api/v1/tasks")
 	tasksRoutes.Use(auth.MiddlewareFunc(), middleware.RateLimitMiddleware(limiter), middleware.DeletionGuardMiddleware())
 	{
 		tasksRoutes.GET("/", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getTasks)
-		tasksRoutes.GET("/due", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getTasksDueBefore)
-		tasksRoutes.GET("/label/:labelId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getTasksByLabel)
-		tasksRoutes.GET("/search", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.searchTasks)
+		tasksRoutes.GET("/archived", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getArchivedTasks)
+		tasksRoutes.GET("/trash", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getDeletedTasks)
+		tasksRoutes.POST("/trash/:id/restore", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.restoreDeletedTask)
 		tasksRoutes.GET("/activity", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getActivity)
+		tasksRoutes.GET("/search", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.searchTasks)
+		tasksRoutes.POST("/preview-occurrences", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.previewOccurrences)
 		tasksRoutes.PUT("/", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.editTask)
 		tasksRoutes.POST("/", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.createTask)
 		tasksRoutes.GET("/:id", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getTask)
+		tasksRoutes.PATCH("/:id", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.patchTask)
 		tasksRoutes.GET("/:id/history", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.GetTaskHistory)
+		tasksRoutes.PUT("/:id/history/:historyId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.updateTaskHistory)
+		tasksRoutes.DELETE("/:id/history/:historyId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteTaskHistory)
+		tasksRoutes.GET("/:id/audit", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getTaskAudit)
 		tasksRoutes.POST("/:id/do", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.completeTask)
+		tasksRoutes.POST("/:id/backfill", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.backfillTask)
 		tasksRoutes.POST("/:id/undo", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.revertAction)
 		tasksRoutes.POST("/:id/skip", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.skipTask)
 		tasksRoutes.PUT("/:id/dueDate", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.updateDueDate)
+		tasksRoutes.POST("/:id/restore", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.restoreTask)
+		tasksRoutes.GET("/:id/overrides", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getOccurrenceOverrides)
+		tasksRoutes.POST("/:id/overrides", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.setOccurrenceOverride)
+		tasksRoutes.DELETE("/:id/overrides/:overrideId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteOccurrenceOverride)
+		tasksRoutes.GET("/:id/checklist", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getChecklist)
+		tasksRoutes.POST("/:id/checklist", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.addChecklistItem)
+		tasksRoutes.PUT("/:id/checklist/:itemId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.updateChecklistItem)
+		tasksRoutes.DELETE("/:id/checklist/:itemId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteChecklistItem)
+		tasksRoutes.GET("/:id/dependencies", authMW.ScopeMiddleware(models.ApiTokenScopeTaskRead), h.getDependencies)
+		tasksRoutes.POST("/:id/dependencies", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.addDependency)
+		tasksRoutes.DELETE("/:id/dependencies/:blockerId", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.removeDependency)
 		tasksRoutes.DELETE("/:id", authMW.ScopeMiddleware(models.ApiTokenScopeTaskWrite), h.deleteTask)
 	}
 }
@@ -81,6 +81,7 @@ func (h *UsersAPIHandler) GetUserProfile(c *gin.Context) {
 		"user": gin.H{
 			"notifications":         notificationSettings,
 			"deletion_requested_at": user.DeletionRequestedAt,
+			"timezone":              user.Timezone,
 		},
 	})
 }
@@ -101,6 +102,22 @@ func (h *UsersAPIHandler) UpdateNotificationSettings(c *gin.Context) {
 	c.JSON(status, response)
 }
 
+func (h *UsersAPIHandler) UpdateTimezone(c *gin.Context) {
+	currentIdentity := auth.CurrentIdentity(c)
+
+	var req models.UpdateTimezoneReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		telemetry.TrackWarning(c, "user_bind_failed", "user-handler", err.Error(), nil)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	status, response := h.userService.UpdateTimezone(c, currentIdentity.UserID, req)
+	c.JSON(status, response)
+}
+
 func (h *UsersAPIHandler) RequestDeletion(c *gin.Context) {
 	currentIdentity := auth.CurrentIdentity(c)
 	status, response := h.userService.RequestDeletion(c, currentIdentity.UserID)
@@ -156,6 +173,7 @@ func UserRoutes(router *gin.Engine, h *UsersAPIHandler, authMiddleware *authMW.A
 	{
 		userRoutes.GET("/profile", authMW.ScopeMiddleware(models.ApiTokenScopeUserRead), h.GetUserProfile)
 		userRoutes.PUT("/notifications", authMW.ScopeMiddleware(models.ApiTokenScopeUserWrite), middleware.DeletionGuardMiddleware(), h.UpdateNotificationSettings)
+		userRoutes.PUT("/timezone", authMW.ScopeMiddleware(models.ApiTokenScopeUserWrite), middleware.DeletionGuardMiddleware(), h.UpdateTimezone)
 		userRoutes.POST("/deletion", authMW.ScopeMiddleware(models.ApiTokenScopeUserWrite), h.RequestDeletion)
 		userRoutes.DELETE("/deletion", authMW.ScopeMiddleware(models.ApiTokenScopeUserWrite), h.CancelDeletion)
 	}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&FrequencyRRuleMigration{})
+}
+
+type FrequencyRRuleMigration struct{}
+
+func (m *FrequencyRRuleMigration) Version() int {
+	return 10
+}
+
+func (m *FrequencyRRuleMigration) Name() string {
+	return "frequency_rrule"
+}
+
+func (m *FrequencyRRuleMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		return dbCtx.Exec("ALTER TABLE tasks ADD COLUMN frequency_rrule TEXT DEFAULT NULL").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *FrequencyRRuleMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN frequency_rrule").Error
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&UserTimezoneMigration{})
+}
+
+type UserTimezoneMigration struct{}
+
+func (m *UserTimezoneMigration) Version() int {
+	return 11
+}
+
+func (m *UserTimezoneMigration) Name() string {
+	return "user_timezone"
+}
+
+func (m *UserTimezoneMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		return dbCtx.Exec("ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT ''").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *UserTimezoneMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE users DROP COLUMN timezone").Error
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&FrequencyMonthDaysMigration{})
+}
+
+type FrequencyMonthDaysMigration struct{}
+
+func (m *FrequencyMonthDaysMigration) Version() int {
+	return 12
+}
+
+func (m *FrequencyMonthDaysMigration) Name() string {
+	return "frequency_month_days"
+}
+
+func (m *FrequencyMonthDaysMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		stmts := []string{
+			"ALTER TABLE tasks ADD COLUMN frequency_day_of_month INTEGER DEFAULT NULL",
+			"ALTER TABLE tasks ADD COLUMN frequency_clamp_to_last_day BOOLEAN DEFAULT false",
+			"ALTER TABLE tasks ADD COLUMN frequency_week_of_month INTEGER DEFAULT NULL",
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *FrequencyMonthDaysMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	for _, column := range []string{"frequency_week_of_month", "frequency_clamp_to_last_day", "frequency_day_of_month"} {
+		if err := dbCtx.Exec("ALTER TABLE tasks DROP COLUMN " + column).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskCatchUpMigration{})
+}
+
+type TaskCatchUpMigration struct{}
+
+func (m *TaskCatchUpMigration) Version() int {
+	return 13
+}
+
+func (m *TaskCatchUpMigration) Name() string {
+	return "task_catch_up"
+}
+
+func (m *TaskCatchUpMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		return dbCtx.Exec("ALTER TABLE tasks ADD COLUMN catch_up VARCHAR(16) NOT NULL DEFAULT 'next_occurrence'").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskCatchUpMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN catch_up").Error
+}
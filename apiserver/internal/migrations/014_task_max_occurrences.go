@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskMaxOccurrencesMigration{})
+}
+
+type TaskMaxOccurrencesMigration struct{}
+
+func (m *TaskMaxOccurrencesMigration) Version() int {
+	return 14
+}
+
+func (m *TaskMaxOccurrencesMigration) Name() string {
+	return "task_max_occurrences"
+}
+
+func (m *TaskMaxOccurrencesMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		return dbCtx.Exec("ALTER TABLE tasks ADD COLUMN max_occurrences INTEGER").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskMaxOccurrencesMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN max_occurrences").Error
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&OccurrenceOverridesMigration{})
+}
+
+type OccurrenceOverridesMigration struct{}
+
+func (m *OccurrenceOverridesMigration) Version() int {
+	return 15
+}
+
+func (m *OccurrenceOverridesMigration) Name() string {
+	return "occurrence_overrides"
+}
+
+func (m *OccurrenceOverridesMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite":
+		stmts := []string{
+			`CREATE TABLE occurrence_overrides (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				original_date DATETIME NOT NULL,
+				new_date DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX idx_occurrence_overrides_task_original ON occurrence_overrides(task_id, original_date)`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case "mysql":
+		// As with sessions, derive task_id from the actual type of tasks.id
+		// so the foreign key matches on deployments created by AutoMigrate.
+		var taskIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tasks' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&taskIDType); err != nil {
+			return fmt.Errorf("failed to detect tasks.id column type: %s", err.Error())
+		}
+		if taskIDType == "" {
+			return fmt.Errorf("tasks.id column type could not be determined")
+		}
+
+		stmts := []string{
+			fmt.Sprintf(`CREATE TABLE occurrence_overrides (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				task_id %s NOT NULL,
+				original_date DATETIME NOT NULL,
+				new_date DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				CONSTRAINT fk_tasks_occurrence_overrides FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`, taskIDType),
+			`CREATE UNIQUE INDEX idx_occurrence_overrides_task_original ON occurrence_overrides(task_id, original_date)`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *OccurrenceOverridesMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("DROP TABLE IF EXISTS occurrence_overrides").Error
+}
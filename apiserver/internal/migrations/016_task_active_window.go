@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskActiveWindowMigration{})
+}
+
+type TaskActiveWindowMigration struct{}
+
+func (m *TaskActiveWindowMigration) Version() int {
+	return 16
+}
+
+func (m *TaskActiveWindowMigration) Name() string {
+	return "task_active_window"
+}
+
+func (m *TaskActiveWindowMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		stmts := []string{
+			"ALTER TABLE tasks ADD COLUMN active_start_month INTEGER DEFAULT NULL",
+			"ALTER TABLE tasks ADD COLUMN active_end_month INTEGER DEFAULT NULL",
+			"ALTER TABLE tasks ADD COLUMN active_start_date DATETIME DEFAULT NULL",
+			"ALTER TABLE tasks ADD COLUMN active_end_date DATETIME DEFAULT NULL",
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskActiveWindowMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	for _, column := range []string{"active_end_date", "active_start_date", "active_end_month", "active_start_month"} {
+		if err := dbCtx.Exec("ALTER TABLE tasks DROP COLUMN " + column).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&HolidayCalendarsMigration{})
+}
+
+type HolidayCalendarsMigration struct{}
+
+func (m *HolidayCalendarsMigration) Version() int {
+	return 17
+}
+
+func (m *HolidayCalendarsMigration) Name() string {
+	return "holiday_calendars"
+}
+
+func (m *HolidayCalendarsMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	var stmts []string
+	switch dialect {
+	case "sqlite":
+		stmts = []string{
+			`CREATE TABLE holiday_calendars (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE holidays (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				calendar_id INTEGER NOT NULL,
+				date VARCHAR(10) NOT NULL,
+				name VARCHAR(100),
+				FOREIGN KEY (calendar_id) REFERENCES holiday_calendars(id) ON DELETE CASCADE
+			)`,
+		}
+	case "mysql":
+		// As with sessions, derive user_id from the actual type of users.id
+		// so the foreign key matches on deployments created by AutoMigrate.
+		var userIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&userIDType); err != nil {
+			return fmt.Errorf("failed to detect users.id column type: %s", err.Error())
+		}
+		if userIDType == "" {
+			return fmt.Errorf("users.id column type could not be determined")
+		}
+
+		stmts = []string{
+			fmt.Sprintf(`CREATE TABLE holiday_calendars (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				user_id %s NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				CONSTRAINT fk_users_holiday_calendars FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			)`, userIDType),
+			`CREATE TABLE holidays (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				calendar_id INT NOT NULL,
+				date VARCHAR(10) NOT NULL,
+				name VARCHAR(100),
+				CONSTRAINT fk_holiday_calendars_holidays FOREIGN KEY (calendar_id) REFERENCES holiday_calendars(id) ON DELETE CASCADE
+			)`,
+		}
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	stmts = append(stmts,
+		`CREATE INDEX idx_holiday_calendars_user_id ON holiday_calendars(user_id)`,
+		`CREATE UNIQUE INDEX idx_holidays_calendar_date ON holidays(calendar_id, date)`,
+		// Tasks keep a plain reference; deleting a calendar clears it in the
+		// same transaction.
+		"ALTER TABLE tasks ADD COLUMN business_days VARCHAR(8) DEFAULT NULL",
+		"ALTER TABLE tasks ADD COLUMN holiday_calendar_id INTEGER DEFAULT NULL",
+	)
+
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *HolidayCalendarsMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	stmts := []string{
+		"ALTER TABLE tasks DROP COLUMN holiday_calendar_id",
+		"ALTER TABLE tasks DROP COLUMN business_days",
+		"DROP TABLE IF EXISTS holidays",
+		"DROP TABLE IF EXISTS holiday_calendars",
+	}
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskHabitsMigration{})
+}
+
+type TaskHabitsMigration struct{}
+
+func (m *TaskHabitsMigration) Version() int {
+	return 18
+}
+
+func (m *TaskHabitsMigration) Name() string {
+	return "task_habits"
+}
+
+func (m *TaskHabitsMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		stmts := []string{
+			"ALTER TABLE tasks ADD COLUMN habit_target INTEGER DEFAULT NULL",
+			"ALTER TABLE tasks ADD COLUMN habit_progress INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE task_histories ADD COLUMN progress INTEGER DEFAULT NULL",
+			"ALTER TABLE task_histories ADD COLUMN target INTEGER DEFAULT NULL",
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskHabitsMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	stmts := []string{
+		"ALTER TABLE task_histories DROP COLUMN target",
+		"ALTER TABLE task_histories DROP COLUMN progress",
+		"ALTER TABLE tasks DROP COLUMN habit_progress",
+		"ALTER TABLE tasks DROP COLUMN habit_target",
+	}
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&ChecklistItemsMigration{})
+}
+
+type ChecklistItemsMigration struct{}
+
+func (m *ChecklistItemsMigration) Version() int {
+	return 19
+}
+
+func (m *ChecklistItemsMigration) Name() string {
+	return "checklist_items"
+}
+
+func (m *ChecklistItemsMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite":
+		stmts := []string{
+			`CREATE TABLE checklist_items (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				title VARCHAR(255) NOT NULL,
+				position INTEGER NOT NULL DEFAULT 0,
+				is_done BOOLEAN NOT NULL DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX idx_checklist_items_task_position ON checklist_items(task_id, position)`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case "mysql":
+		// As with sessions, derive task_id from the actual type of tasks.id
+		// so the foreign key matches on deployments created by AutoMigrate.
+		var taskIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tasks' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&taskIDType); err != nil {
+			return fmt.Errorf("failed to detect tasks.id column type: %s", err.Error())
+		}
+		if taskIDType == "" {
+			return fmt.Errorf("tasks.id column type could not be determined")
+		}
+
+		stmts := []string{
+			fmt.Sprintf(`CREATE TABLE checklist_items (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				task_id %s NOT NULL,
+				title VARCHAR(255) NOT NULL,
+				position INT NOT NULL DEFAULT 0,
+				is_done BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				CONSTRAINT fk_tasks_checklist_items FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`, taskIDType),
+			`CREATE INDEX idx_checklist_items_task_position ON checklist_items(task_id, position)`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *ChecklistItemsMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("DROP TABLE IF EXISTS checklist_items").Error
+}
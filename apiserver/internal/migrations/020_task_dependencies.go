@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskDependenciesMigration{})
+}
+
+type TaskDependenciesMigration struct{}
+
+func (m *TaskDependenciesMigration) Version() int {
+	return 20
+}
+
+func (m *TaskDependenciesMigration) Name() string {
+	return "task_dependencies"
+}
+
+func (m *TaskDependenciesMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite":
+		stmts := []string{
+			`CREATE TABLE task_dependencies (
+				task_id INTEGER NOT NULL,
+				blocker_id INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (task_id, blocker_id),
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+				FOREIGN KEY (blocker_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX idx_task_dependencies_blocker_id ON task_dependencies(blocker_id)`,
+			`ALTER TABLE tasks ADD COLUMN enforce_dependencies BOOLEAN NOT NULL DEFAULT 0`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case "mysql":
+		// As with sessions, derive the column types from the actual type of
+		// tasks.id so the foreign keys match on deployments created by
+		// AutoMigrate.
+		var taskIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tasks' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&taskIDType); err != nil {
+			return fmt.Errorf("failed to detect tasks.id column type: %s", err.Error())
+		}
+		if taskIDType == "" {
+			return fmt.Errorf("tasks.id column type could not be determined")
+		}
+
+		stmts := []string{
+			fmt.Sprintf(`CREATE TABLE task_dependencies (
+				task_id %s NOT NULL,
+				blocker_id %s NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (task_id, blocker_id),
+				CONSTRAINT fk_task_dependencies_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+				CONSTRAINT fk_task_dependencies_blocker FOREIGN KEY (blocker_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`, taskIDType, taskIDType),
+			`CREATE INDEX idx_task_dependencies_blocker_id ON task_dependencies(blocker_id)`,
+			`ALTER TABLE tasks ADD COLUMN enforce_dependencies BOOLEAN NOT NULL DEFAULT FALSE`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskDependenciesMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	if err := dbCtx.Exec("DROP TABLE IF EXISTS task_dependencies").Error; err != nil {
+		return err
+	}
+	return dbCtx.Exec("ALTER TABLE tasks DROP COLUMN enforce_dependencies").Error
+}
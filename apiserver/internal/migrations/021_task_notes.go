@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskNotesMigration{})
+}
+
+type TaskNotesMigration struct{}
+
+func (m *TaskNotesMigration) Version() int {
+	return 21
+}
+
+func (m *TaskNotesMigration) Name() string {
+	return "task_notes"
+}
+
+func (m *TaskNotesMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		// MySQL does not allow a literal default on TEXT columns, so notes
+		// are nullable and read back as an empty string.
+		return dbCtx.Exec("ALTER TABLE tasks ADD COLUMN notes TEXT NULL").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskNotesMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN notes").Error
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskPriorityMigration{})
+}
+
+type TaskPriorityMigration struct{}
+
+func (m *TaskPriorityMigration) Version() int {
+	return 22
+}
+
+func (m *TaskPriorityMigration) Name() string {
+	return "task_priority"
+}
+
+func (m *TaskPriorityMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+		return dbCtx.Exec("ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0").Error
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskPriorityMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN priority").Error
+}
@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskSearchIndexMigration{})
+}
+
+// TaskSearchIndexMigration creates task_search, a full-text index over task
+// titles and the names of their labels. SQLite uses an FTS5 table keyed by the
+// task's rowid; MySQL a plain table with a FULLTEXT index. Both are filled
+// from the existing tasks and kept in sync by the repositories.
+type TaskSearchIndexMigration struct{}
+
+func (m *TaskSearchIndexMigration) Version() int {
+	return 23
+}
+
+func (m *TaskSearchIndexMigration) Name() string {
+	return "task_search_index"
+}
+
+func (m *TaskSearchIndexMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite":
+		stmts := []string{
+			`CREATE VIRTUAL TABLE task_search USING fts5(title, labels, tokenize = 'unicode61 remove_diacritics 2')`,
+			`INSERT INTO task_search (rowid, title, labels)
+				SELECT t.id, t.title, COALESCE((
+					SELECT group_concat(l.name, ' ') FROM task_labels tl
+					JOIN labels l ON l.id = tl.label_id
+					WHERE tl.task_id = t.id
+				), '')
+				FROM tasks t`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case "mysql":
+		// As with sessions, derive the column type from the actual type of
+		// tasks.id so the foreign key matches on deployments created by
+		// AutoMigrate.
+		var taskIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tasks' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&taskIDType); err != nil {
+			return fmt.Errorf("failed to detect tasks.id column type: %s", err.Error())
+		}
+		if taskIDType == "" {
+			return fmt.Errorf("tasks.id column type could not be determined")
+		}
+
+		stmts := []string{
+			fmt.Sprintf(`CREATE TABLE task_search (
+				task_id %s NOT NULL PRIMARY KEY,
+				title TEXT NOT NULL,
+				labels TEXT NOT NULL,
+				FULLTEXT INDEX ft_task_search (title, labels),
+				CONSTRAINT fk_task_search_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			) ENGINE=InnoDB`, taskIDType),
+			`INSERT INTO task_search (task_id, title, labels)
+				SELECT t.id, t.title, COALESCE((
+					SELECT GROUP_CONCAT(l.name SEPARATOR ' ') FROM task_labels tl
+					JOIN labels l ON l.id = tl.label_id
+					WHERE tl.task_id = t.id
+				), '')
+				FROM tasks t`,
+		}
+		for _, stmt := range stmts {
+			if err := dbCtx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func (m *TaskSearchIndexMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("DROP TABLE IF EXISTS task_search").Error
+}
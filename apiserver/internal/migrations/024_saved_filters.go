@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&SavedFiltersMigration{})
+}
+
+type SavedFiltersMigration struct{}
+
+func (m *SavedFiltersMigration) Version() int {
+	return 24
+}
+
+func (m *SavedFiltersMigration) Name() string {
+	return "saved_filters"
+}
+
+func (m *SavedFiltersMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	var stmts []string
+	switch dialect {
+	case "sqlite":
+		stmts = []string{
+			`CREATE TABLE saved_filters (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				definition TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			)`,
+		}
+	case "mysql":
+		// As with holiday calendars, derive user_id from the actual type of
+		// users.id so the foreign key matches.
+		var userIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&userIDType); err != nil {
+			return fmt.Errorf("failed to detect users.id column type: %s", err.Error())
+		}
+		if userIDType == "" {
+			return fmt.Errorf("users.id column type could not be determined")
+		}
+
+		stmts = []string{
+			fmt.Sprintf(`CREATE TABLE saved_filters (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				user_id %s NOT NULL,
+				name VARCHAR(100) NOT NULL,
+				definition TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT NULL,
+				CONSTRAINT fk_users_saved_filters FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			)`, userIDType),
+		}
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	stmts = append(stmts,
+		`CREATE UNIQUE INDEX idx_saved_filters_user_name ON saved_filters(user_id, name)`,
+	)
+
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SavedFiltersMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("DROP TABLE IF EXISTS saved_filters").Error
+}
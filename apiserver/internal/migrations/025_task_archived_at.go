@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskArchivedAtMigration{})
+}
+
+type TaskArchivedAtMigration struct{}
+
+func (m *TaskArchivedAtMigration) Version() int {
+	return 25
+}
+
+func (m *TaskArchivedAtMigration) Name() string {
+	return "task_archived_at"
+}
+
+func (m *TaskArchivedAtMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	stmts := []string{
+		"ALTER TABLE tasks ADD COLUMN archived_at DATETIME DEFAULT NULL",
+		"CREATE INDEX idx_tasks_archived_at ON tasks(archived_at)",
+		// Tasks archived before this migration count from their last
+		// completion, or from their last change if they were never completed.
+		`UPDATE tasks SET archived_at = COALESCE(
+			(SELECT MAX(h.completed_date) FROM task_histories h WHERE h.task_id = tasks.id),
+			updated_at,
+			created_at
+		) WHERE is_active = 0`,
+	}
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *TaskArchivedAtMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	dropIndex := "DROP INDEX IF EXISTS idx_tasks_archived_at"
+	if db.Name() == "mysql" {
+		dropIndex = "DROP INDEX idx_tasks_archived_at ON tasks"
+	}
+	if err := dbCtx.Exec(dropIndex).Error; err != nil {
+		return err
+	}
+
+	return dbCtx.Exec("ALTER TABLE tasks DROP COLUMN archived_at").Error
+}
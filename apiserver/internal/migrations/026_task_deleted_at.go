@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskDeletedAtMigration{})
+}
+
+type TaskDeletedAtMigration struct{}
+
+func (m *TaskDeletedAtMigration) Version() int {
+	return 26
+}
+
+func (m *TaskDeletedAtMigration) Name() string {
+	return "task_deleted_at"
+}
+
+func (m *TaskDeletedAtMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	stmts := []string{
+		"ALTER TABLE tasks ADD COLUMN deleted_at DATETIME DEFAULT NULL",
+		"CREATE INDEX idx_tasks_deleted_at ON tasks(deleted_at)",
+	}
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *TaskDeletedAtMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	dropIndex := "DROP INDEX IF EXISTS idx_tasks_deleted_at"
+	if db.Name() == "mysql" {
+		dropIndex = "DROP INDEX idx_tasks_deleted_at ON tasks"
+	}
+	if err := dbCtx.Exec(dropIndex).Error; err != nil {
+		return err
+	}
+
+	return dbCtx.Exec("ALTER TABLE tasks DROP COLUMN deleted_at").Error
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskAuditsMigration{})
+}
+
+type TaskAuditsMigration struct{}
+
+func (m *TaskAuditsMigration) Version() int {
+	return 27
+}
+
+func (m *TaskAuditsMigration) Name() string {
+	return "task_audits"
+}
+
+func (m *TaskAuditsMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+	dialect := db.Name()
+
+	// No foreign key on user_id: the actor is the task's owner, so the
+	// cascade from tasks already removes their entries.
+	var stmts []string
+	switch dialect {
+	case "sqlite":
+		stmts = []string{
+			`CREATE TABLE task_audits (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				user_id INTEGER NOT NULL,
+				changes TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`,
+		}
+	case "mysql":
+		// As with task dependencies, derive task_id from the actual type of
+		// tasks.id so the foreign key matches.
+		var taskIDType string
+		row := dbCtx.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'tasks' AND COLUMN_NAME = 'id'`).Row()
+		if err := row.Scan(&taskIDType); err != nil {
+			return fmt.Errorf("failed to detect tasks.id column type: %s", err.Error())
+		}
+		if taskIDType == "" {
+			return fmt.Errorf("tasks.id column type could not be determined")
+		}
+
+		stmts = []string{
+			fmt.Sprintf(`CREATE TABLE task_audits (
+				id INT AUTO_INCREMENT PRIMARY KEY,
+				task_id %s NOT NULL,
+				user_id INT NOT NULL,
+				changes TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				CONSTRAINT fk_task_audits_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			)`, taskIDType),
+		}
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	// History entries get the time they were recorded, so the activity feed
+	// can order them among audit entries. Existing entries are assumed to
+	// have been recorded when completed, or else when due.
+	stmts = append(stmts,
+		`CREATE INDEX idx_task_audits_task_id ON task_audits(task_id, created_at)`,
+		`ALTER TABLE task_histories ADD COLUMN recorded_at DATETIME DEFAULT NULL`,
+		`UPDATE task_histories SET recorded_at = COALESCE(completed_date, due_date, CURRENT_TIMESTAMP)`,
+		`CREATE INDEX idx_task_histories_recorded_at ON task_histories(recorded_at)`,
+	)
+
+	for _, stmt := range stmts {
+		if err := dbCtx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *TaskAuditsMigration) Down(ctx context.Context, db *gorm.DB) error {
+	dbCtx := db.WithContext(ctx)
+
+	dropIndex := "DROP INDEX IF EXISTS idx_task_histories_recorded_at"
+	if db.Name() == "mysql" {
+		dropIndex = "DROP INDEX idx_task_histories_recorded_at ON task_histories"
+	}
+	if err := dbCtx.Exec(dropIndex).Error; err != nil {
+		return err
+	}
+	if err := dbCtx.Exec("ALTER TABLE task_histories DROP COLUMN recorded_at").Error; err != nil {
+		return err
+	}
+
+	return dbCtx.Exec("DROP TABLE IF EXISTS task_audits").Error
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register(&TaskVersionMigration{})
+}
+
+type TaskVersionMigration struct{}
+
+func (m *TaskVersionMigration) Version() int {
+	return 28
+}
+
+func (m *TaskVersionMigration) Name() string {
+	return "task_version"
+}
+
+func (m *TaskVersionMigration) Up(ctx context.Context, db *gorm.DB) error {
+	dialect := db.Name()
+
+	switch dialect {
+	case "sqlite", "mysql":
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks ADD COLUMN version INT NOT NULL DEFAULT 1").Error
+}
+
+func (m *TaskVersionMigration) Down(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Exec("ALTER TABLE tasks DROP COLUMN version").Error
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskAudit records an edit of a task: which fields changed, from what to
+// what, by whom and when.
+type TaskAudit struct {
+	ID        int           `json:"id" gorm:"primary_key"`
+	TaskID    int           `json:"task_id" gorm:"column:task_id;not null;index:idx_task_audits_task_id"`
+	UserID    int           `json:"user_id" gorm:"column:user_id;not null"`
+	Changes   []FieldChange `json:"changes" gorm:"column:changes;type:text;not null;serializer:json"`
+	CreatedAt time.Time     `json:"created_at" gorm:"column:created_at;not null;index:idx_task_audits_task_id"`
+}
+
+// FieldChange is the value of a task field before and after an edit, as it
+// appears in the task's JSON.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
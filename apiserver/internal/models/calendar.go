@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// HolidayCalendar is a user-defined list of non-working days that tasks
+// restricted to business days are kept off.
+type HolidayCalendar struct {
+	ID        int       `json:"id" gorm:"primary_key"`
+	Name      string    `json:"name" gorm:"column:name;type:varchar(100);not null"`
+	UserID    int       `json:"-" gorm:"column:user_id;not null;index:idx_holiday_calendars_user_id"`
+	CreatedAt time.Time `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+
+	Holidays []Holiday `json:"holidays" gorm:"foreignKey:CalendarID;constraint:OnDelete:CASCADE"`
+}
+
+// Holiday is a single non-working day. Date is a calendar date (YYYY-MM-DD)
+// interpreted in the user's time zone.
+type Holiday struct {
+	ID         int    `json:"-" gorm:"primary_key"`
+	CalendarID int    `json:"-" gorm:"column:calendar_id;not null;uniqueIndex:idx_holidays_calendar_date"`
+	Date       string `json:"date" gorm:"column:date;type:varchar(10);not null;uniqueIndex:idx_holidays_calendar_date"`
+	Name       string `json:"name" gorm:"column:name;type:varchar(100)"`
+}
+
+// CreateHolidayCalendarReq takes the holidays as a list, as the contents of an
+// iCalendar file, or both.
+type CreateHolidayCalendarReq struct {
+	Name     string    `json:"name" binding:"required"`
+	Holidays []Holiday `json:"holidays"`
+	ICS      string    `json:"ics"`
+}
+
+type UpdateHolidayCalendarReq struct {
+	ID int `json:"id" binding:"required"`
+	CreateHolidayCalendarReq
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedFilter is a named view of the user's tasks, such as "Overdue work",
+// that any of their clients can open.
+type SavedFilter struct {
+	ID        int        `json:"id" gorm:"primary_key"`
+	Name      string     `json:"name" gorm:"column:name;type:varchar(100);not null;uniqueIndex:idx_saved_filters_user_name"`
+	UserID    int        `json:"-" gorm:"column:user_id;not null;uniqueIndex:idx_saved_filters_user_name"`
+	Filter    TaskFilter `json:"filter" gorm:"column:definition;type:text;not null;serializer:json"`
+	CreatedAt time.Time  `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+	UpdatedAt *time.Time `json:"-" gorm:"column:updated_at;default:NULL;autoUpdateTime"`
+}
+
+type CreateSavedFilterReq struct {
+	Name   string     `json:"name" binding:"required"`
+	Filter TaskFilter `json:"filter"`
+}
+
+type UpdateSavedFilterReq struct {
+	ID int `json:"id" binding:"required"`
+	CreateSavedFilterReq
+}
+
+// EvaluateSavedFilterReq selects the page of a saved filter's tasks to
+// return.
+type EvaluateSavedFilterReq struct {
+	ID     int    `json:"id" form:"-"`
+	Cursor string `json:"cursor" form:"cursor"`
+	Limit  int    `json:"limit" form:"limit"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type FrequencyType string
 
 const (
@@ -9,6 +11,7 @@ const (
 	RepeatMonthly = "monthly"
 	RepeatYearly  = "yearly"
 	RepeatCustom  = "custom"
+	RepeatRRule   = "rrule"
 )
 
 type IntervalUnit string
@@ -27,13 +30,60 @@ const (
 	Interval       RepeatOn = "interval"
 	DaysOfTheWeek  RepeatOn = "days_of_the_week"
 	DayOfTheMonths RepeatOn = "day_of_the_months"
+	// DayOfTheMonth repeats on a fixed day of the month, e.g. the 3rd.
+	DayOfTheMonth RepeatOn = "day_of_the_month"
+	// WeekdayOfTheMonth repeats on an ordinal weekday, e.g. the second Tuesday.
+	WeekdayOfTheMonth RepeatOn = "weekday_of_month"
+)
+
+// LastWeekOfMonth is the WeekOfMonth value selecting the last occurrence of a
+// weekday in the month.
+const LastWeekOfMonth = -1
+
+// CatchUpPolicy decides how a fixed-schedule task is rescheduled when it is
+// completed or skipped after later occurrences have already passed.
+type CatchUpPolicy string
+
+const (
+	// CatchUpNextOccurrence moves to the occurrence following the one that
+	// was due, even if that is still in the past.
+	CatchUpNextOccurrence CatchUpPolicy = "next_occurrence"
+	// CatchUpAfterNow moves to the first occurrence that is still ahead.
+	CatchUpAfterNow CatchUpPolicy = "after_now"
+	// CatchUpSkipMissed moves to the first occurrence that is still ahead and
+	// records every occurrence passed over as skipped.
+	CatchUpSkipMissed CatchUpPolicy = "skip_missed"
+)
+
+// BusinessDayRoll keeps a task's due dates on working days by moving those
+// that land on a weekend or holiday to the next or previous working day.
+type BusinessDayRoll string
+
+const (
+	BusinessDaysForward  BusinessDayRoll = "forward"
+	BusinessDaysBackward BusinessDayRoll = "backward"
 )
 
 type Frequency struct {
-	Type   FrequencyType `json:"type" validate:"required" gorm:"type:varchar(9)"`
-	On     RepeatOn      `json:"on" validate:"required_if=Type interval custom" gorm:"type:varchar(18);default:null"`
-	Every  int           `json:"every" validate:"required_if=On interval" gorm:"type:int;default:null"`
-	Unit   IntervalUnit  `json:"unit" validate:"required_if=On interval" gorm:"type:varchar(9);default:null"`
-	Days   []int32       `json:"days" validate:"required_if=Type custom On days_of_the_week,dive,gte=0,lte=6" gorm:"serializer:json"`
-	Months []int32       `json:"months" validate:"required_if=Type custom On day_of_the_months,dive,gte=0,lte=11" gorm:"serializer:json"`
+	Type           FrequencyType `json:"type" validate:"required" gorm:"type:varchar(9)"`
+	On             RepeatOn      `json:"on" validate:"required_if=Type interval custom" gorm:"type:varchar(18);default:null"`
+	Every          int           `json:"every" validate:"required_if=On interval" gorm:"type:int;default:null"`
+	Unit           IntervalUnit  `json:"unit" validate:"required_if=On interval" gorm:"type:varchar(9);default:null"`
+	Days           []int32       `json:"days" validate:"required_if=Type custom On days_of_the_week,dive,gte=0,lte=6" gorm:"serializer:json"`
+	Months         []int32       `json:"months" validate:"required_if=Type custom On day_of_the_months,dive,gte=0,lte=11" gorm:"serializer:json"`
+	RRule          string        `json:"rrule,omitempty" validate:"required_if=Type rrule" gorm:"column:rrule;type:text;default:null"`
+	DayOfMonth     int           `json:"day_of_month,omitempty" validate:"required_if=On day_of_the_month" gorm:"column:day_of_month;type:int;default:null"`
+	ClampToLastDay bool          `json:"clamp_to_last_day,omitempty" gorm:"column:clamp_to_last_day;default:false"`
+	WeekOfMonth    int           `json:"week_of_month,omitempty" validate:"required_if=On weekday_of_month" gorm:"column:week_of_month;type:int;default:null"`
+}
+
+// ActiveWindow limits a recurring task to part of the year. StartMonth and
+// EndMonth (1-12, inclusive) select a range of months that may wrap around the
+// new year; StartDate and EndDate select an explicit range instead. A zero
+// ActiveWindow leaves the task active all year.
+type ActiveWindow struct {
+	StartMonth int        `json:"start_month,omitempty" gorm:"column:start_month;type:int;default:null"`
+	EndMonth   int        `json:"end_month,omitempty" gorm:"column:end_month;type:int;default:null"`
+	StartDate  *time.Time `json:"start_date,omitempty" gorm:"column:start_date;default:null"`
+	EndDate    *time.Time `json:"end_date,omitempty" gorm:"column:end_date;default:null"`
 }
@@ -2,40 +2,200 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+	"taskwiz.app/core/internal/utils/search"
 )
 
 type Task struct {
-	ID           int                        `json:"id" gorm:"primary_key"`
-	Title        string                     `json:"title" gorm:"column:title;not null"`
-	Frequency    Frequency                  `json:"frequency" gorm:"embedded;embeddedPrefix:frequency_"`
-	NextDueDate  *time.Time                 `json:"next_due_date" gorm:"column:next_due_date;index"`
-	EndDate      *time.Time                 `json:"end_date" gorm:"column:end_date;default:NULL"`
-	IsRolling    bool                       `json:"is_rolling" gorm:"column:is_rolling;default:false"`
-	CreatedBy    int                        `json:"-" gorm:"column:created_by;not null;index:idx_tasks_created_by"`
-	IsActive     bool                       `json:"-" gorm:"column:is_active;default:true;index:idx_tasks_is_active"`
-	Notification NotificationTriggerOptions `json:"notification" gorm:"embedded;embeddedPrefix:notification_"`
-	CreatedAt    time.Time                  `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
-	UpdatedAt    *time.Time                 `json:"-" gorm:"column:updated_at;default:NULL;autoUpdateTime"`
-
-	Labels        []Label        `json:"labels" gorm:"many2many:task_labels;constraint:OnDelete:CASCADE"`
-	History       []TaskHistory  `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
-	Notifications []Notification `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
+	ID                int                        `json:"id" gorm:"primary_key"`
+	Title             string                     `json:"title" gorm:"column:title;not null"`
+	Notes             string                     `json:"notes,omitempty" gorm:"column:notes;type:text"`
+	Priority          Priority                   `json:"priority" gorm:"column:priority;not null;default:0"`
+	Frequency         Frequency                  `json:"frequency" gorm:"embedded;embeddedPrefix:frequency_"`
+	ActiveWindow      ActiveWindow               `json:"active_window" gorm:"embedded;embeddedPrefix:active_"`
+	NextDueDate       *time.Time                 `json:"next_due_date" gorm:"column:next_due_date;index"`
+	EndDate           *time.Time                 `json:"end_date" gorm:"column:end_date;default:NULL"`
+	MaxOccurrences    int                        `json:"max_occurrences,omitempty" gorm:"column:max_occurrences;type:int;default:null"`
+	IsRolling         bool                       `json:"is_rolling" gorm:"column:is_rolling;default:false"`
+	CatchUp           CatchUpPolicy              `json:"catch_up" gorm:"column:catch_up;type:varchar(16);not null;default:'next_occurrence'"`
+	BusinessDays      BusinessDayRoll            `json:"business_days,omitempty" gorm:"column:business_days;type:varchar(8);default:null"`
+	HolidayCalendarID *int                       `json:"holiday_calendar_id" gorm:"column:holiday_calendar_id;default:null"`
+	HabitTarget       int                        `json:"habit_target,omitempty" gorm:"column:habit_target;type:int;default:null"`
+	HabitProgress     int                        `json:"habit_progress" gorm:"column:habit_progress;not null;default:0"`
+	EnforceDeps       bool                       `json:"enforce_dependencies" gorm:"column:enforce_dependencies;not null;default:false"`
+	Blocked           bool                       `json:"blocked" gorm:"-"`
+	CreatedBy         int                        `json:"-" gorm:"column:created_by;not null;index:idx_tasks_created_by"`
+	IsActive          bool                       `json:"-" gorm:"column:is_active;default:true;index:idx_tasks_is_active"`
+	ArchivedAt        *time.Time                 `json:"archived_at,omitempty" gorm:"column:archived_at;default:NULL"`
+	Notification      NotificationTriggerOptions `json:"notification" gorm:"embedded;embeddedPrefix:notification_"`
+	CreatedAt         time.Time                  `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+	UpdatedAt         *time.Time                 `json:"-" gorm:"column:updated_at;default:NULL;autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt             `json:"deleted_at,omitzero" gorm:"column:deleted_at;index:idx_tasks_deleted_at"`
+	Version           int                        `json:"version" gorm:"column:version;not null;default:1"`
+
+	Labels          []Label              `json:"labels" gorm:"many2many:task_labels;constraint:OnDelete:CASCADE"`
+	History         []TaskHistory        `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
+	Notifications   []Notification       `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
+	Overrides       []OccurrenceOverride `json:"overrides,omitempty" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
+	Checklist       []ChecklistItem      `json:"checklist,omitempty" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE;"`
+	HolidayCalendar *HolidayCalendar     `json:"-" gorm:"foreignKey:HolidayCalendarID"`
 }
 
+// Priority ranks how urgent a task is, from PriorityNone up to PriorityUrgent.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// TaskSortKey names a field task lists can be ordered by.
+type TaskSortKey string
+
+const (
+	TaskSortDue      TaskSortKey = "due"
+	TaskSortPriority TaskSortKey = "priority"
+	TaskSortTitle    TaskSortKey = "title"
+	TaskSortCreated  TaskSortKey = "created"
+	TaskSortUpdated  TaskSortKey = "updated"
+	TaskSortArchived TaskSortKey = "archived"
+	TaskSortDeleted  TaskSortKey = "deleted"
+)
+
+// TaskSort orders a task list by one key. Tasks without a value for the key,
+// such as those with no due date, come last in either direction.
+type TaskSort struct {
+	Key        TaskSortKey
+	Descending bool
+}
+
+// LabelMatch decides whether a task list filtered by several labels keeps
+// tasks carrying any of them or only those carrying all of them.
+type LabelMatch string
+
+const (
+	LabelMatchAny LabelMatch = "any"
+	LabelMatchAll LabelMatch = "all"
+)
+
+// TaskQuery selects a page of a user's active tasks. Zero-valued filters are
+// not applied.
+type TaskQuery struct {
+	Labels           []int
+	LabelMatch       LabelMatch
+	DueAfter         *time.Time
+	DueBefore        *time.Time
+	Overdue          bool
+	Recurring        *bool
+	HasNotifications *bool
+	Search           *search.Query
+	SearchNotes      bool
+	// Location is the time zone dates in Search are read in; nil means UTC.
+	Location *time.Location
+	Sort     []TaskSort
+	// AfterID resumes the list after the task with this ID, in the order
+	// given by Sort.
+	AfterID int
+	// Limit bounds the page size; zero returns every matching task.
+	Limit int
+	// Archived lists the user's archived tasks instead of their active ones.
+	Archived bool
+	// Deleted lists the tasks in the user's trash, whether active or
+	// archived, instead.
+	Deleted bool
+}
+
+// TaskSearchResult is a task found by a full-text search. Snippet is the
+// task's title and MatchedLabels the names of its labels that matched, both
+// HTML-escaped with the matching words wrapped in <mark> tags.
+type TaskSearchResult struct {
+	Task          *Task    `json:"task"`
+	Score         float64  `json:"score"`
+	Snippet       string   `json:"snippet"`
+	MatchedLabels []string `json:"matched_labels,omitempty"`
+}
+
+// OccurrenceOverride moves a single occurrence of a recurring task to a new
+// date, or cancels it when NewDate is nil, without changing the series.
+type OccurrenceOverride struct {
+	ID           int        `json:"id" gorm:"primary_key"`
+	TaskID       int        `json:"task_id" gorm:"column:task_id;not null;uniqueIndex:idx_occurrence_overrides_task_original"`
+	OriginalDate time.Time  `json:"original_date" gorm:"column:original_date;not null;uniqueIndex:idx_occurrence_overrides_task_original"`
+	NewDate      *time.Time `json:"new_date" gorm:"column:new_date"`
+	CreatedAt    time.Time  `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+// TaskDependency records that TaskID is blocked by BlockerID: the task should
+// not be done while the blocker is still open.
+type TaskDependency struct {
+	TaskID    int       `json:"task_id" gorm:"column:task_id;primaryKey"`
+	BlockerID int       `json:"blocker_id" gorm:"column:blocker_id;primaryKey;index:idx_task_dependencies_blocker_id"`
+	CreatedAt time.Time `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+// ChecklistItem is one step of a task. Items are kept in Position order and
+// are unchecked again whenever a recurring task moves on to its next
+// occurrence.
+type ChecklistItem struct {
+	ID        int       `json:"id" gorm:"primary_key"`
+	TaskID    int       `json:"task_id" gorm:"column:task_id;not null;index:idx_checklist_items_task_position"`
+	Title     string    `json:"title" gorm:"column:title;type:varchar(255);not null"`
+	Position  int       `json:"position" gorm:"column:position;not null;default:0;index:idx_checklist_items_task_position"`
+	IsDone    bool      `json:"is_done" gorm:"column:is_done;not null;default:false"`
+	CreatedAt time.Time `json:"-" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+// TaskHistory records one occurrence of a task. For habit tasks it records a
+// whole period: Progress completions out of Target, completed once the target
+// was met.
 type TaskHistory struct {
 	ID            int        `json:"id" gorm:"primary_key"`
 	TaskID        int        `json:"task_id" gorm:"column:task_id;not null;index:idx_task_histories_task_id"`
 	CompletedDate *time.Time `json:"completed_date" gorm:"column:completed_date"`
 	DueDate       *time.Time `json:"due_date" gorm:"column:due_date"`
+	Progress      int        `json:"progress,omitempty" gorm:"column:progress;type:int;default:null"`
+	Target        int        `json:"target,omitempty" gorm:"column:target;type:int;default:null"`
+	RecordedAt    time.Time  `json:"recorded_at" gorm:"column:recorded_at;index:idx_task_histories_recorded_at"`
 }
 
+// ActivityType tells the kinds of entries in the activity feed apart.
+type ActivityType string
+
+const (
+	// ActivityHistory entries are TaskHistory entries: completions and skips.
+	ActivityHistory ActivityType = "history"
+	// ActivityAudit entries are TaskAudit entries: edits of a task.
+	ActivityAudit ActivityType = "audit"
+)
+
+// ActivityEntry is an entry in the activity feed, identified by its Type and
+// ID. Any history entry can be reverted, which also reverts the entries of
+// its task recorded after it: UndoSteps counts them all, so it is 1 for the
+// latest history entry of the task, marked by IsLatest. Audit entries list
+// their Changes instead.
 type ActivityEntry struct {
-	ID            int        `json:"id"`
-	TaskID        int        `json:"task_id"`
-	TaskTitle     string     `json:"task_title"`
-	CompletedDate *time.Time `json:"completed_date"`
-	DueDate       *time.Time `json:"due_date"`
-	IsLatest      bool       `json:"is_latest"`
+	ID            int           `json:"id"`
+	Type          ActivityType  `json:"type"`
+	TaskID        int           `json:"task_id"`
+	TaskTitle     string        `json:"task_title"`
+	RecordedAt    time.Time     `json:"recorded_at"`
+	CompletedDate *time.Time    `json:"completed_date"`
+	DueDate       *time.Time    `json:"due_date"`
+	Progress      int           `json:"progress,omitempty"`
+	Target        int           `json:"target,omitempty"`
+	IsLatest      bool          `json:"is_latest"`
+	UndoSteps     int           `json:"undo_steps"`
+	Changes       []FieldChange `json:"changes,omitempty" gorm:"serializer:json"`
+}
+
+// ActivityRef identifies an entry of the activity feed.
+type ActivityRef struct {
+	Type ActivityType
+	ID   int
 }
 
 type TaskLabel struct {
@@ -44,26 +204,130 @@ type TaskLabel struct {
 }
 
 type CreateTaskReq struct {
-	Title        string                     `json:"title" binding:"required"`
-	NextDueDate  string                     `json:"next_due_date"`
-	EndDate      string                     `json:"end_date"`
-	IsRolling    bool                       `json:"is_rolling"`
-	Frequency    Frequency                  `json:"frequency"`
-	Notification NotificationTriggerOptions `json:"notification"`
-	Labels       []int                      `json:"labels"`
+	Title             string                     `json:"title" binding:"required"`
+	Notes             string                     `json:"notes"`
+	Priority          Priority                   `json:"priority"`
+	NextDueDate       string                     `json:"next_due_date"`
+	EndDate           string                     `json:"end_date"`
+	MaxOccurrences    int                        `json:"max_occurrences"`
+	IsRolling         bool                       `json:"is_rolling"`
+	CatchUp           CatchUpPolicy              `json:"catch_up"`
+	BusinessDays      BusinessDayRoll            `json:"business_days"`
+	HolidayCalendarID *int                       `json:"holiday_calendar_id"`
+	HabitTarget       int                        `json:"habit_target"`
+	EnforceDeps       bool                       `json:"enforce_dependencies"`
+	Frequency         Frequency                  `json:"frequency"`
+	ActiveWindow      ActiveWindow               `json:"active_window"`
+	Notification      NotificationTriggerOptions `json:"notification"`
+	Labels            []int                      `json:"labels"`
 }
 
+// UpdateTaskReq replaces the fields of the task. Version is the version of
+// the task the edit was made to; it may instead be given as an If-Match
+// header over REST.
 type UpdateTaskReq struct {
-	ID           int                        `json:"id" binding:"required"`
-	Title        string                     `json:"title" binding:"required"`
-	NextDueDate  string                     `json:"next_due_date"`
-	EndDate      string                     `json:"end_date"`
-	IsRolling    bool                       `json:"is_rolling"`
-	Frequency    Frequency                  `json:"frequency"`
-	Notification NotificationTriggerOptions `json:"notification"`
-	Labels       []int                      `json:"labels"`
+	ID                int                        `json:"id" binding:"required"`
+	Version           int                        `json:"version"`
+	Title             string                     `json:"title" binding:"required"`
+	Notes             string                     `json:"notes"`
+	Priority          Priority                   `json:"priority"`
+	NextDueDate       string                     `json:"next_due_date"`
+	EndDate           string                     `json:"end_date"`
+	MaxOccurrences    int                        `json:"max_occurrences"`
+	IsRolling         bool                       `json:"is_rolling"`
+	CatchUp           CatchUpPolicy              `json:"catch_up"`
+	BusinessDays      BusinessDayRoll            `json:"business_days"`
+	HolidayCalendarID *int                       `json:"holiday_calendar_id"`
+	HabitTarget       int                        `json:"habit_target"`
+	EnforceDeps       bool                       `json:"enforce_dependencies"`
+	Frequency         Frequency                  `json:"frequency"`
+	ActiveWindow      ActiveWindow               `json:"active_window"`
+	Notification      NotificationTriggerOptions `json:"notification"`
+	Labels            []int                      `json:"labels"`
+}
+
+// TaskFilter holds the filters and sort order of a task list. Query is
+// written in the search language of package search.
+type TaskFilter struct {
+	Labels           []int      `json:"labels,omitempty" form:"labels"`
+	LabelMatch       LabelMatch `json:"label_match,omitempty" form:"label_match"`
+	DueAfter         string     `json:"due_after,omitempty" form:"due_after"`
+	DueBefore        string     `json:"due_before,omitempty" form:"due_before"`
+	Overdue          bool       `json:"overdue,omitempty" form:"overdue"`
+	Recurring        *bool      `json:"recurring,omitempty" form:"recurring"`
+	HasNotifications *bool      `json:"has_notifications,omitempty" form:"has_notifications"`
+	Query            string     `json:"q,omitempty" form:"q"`
+	SearchNotes      bool       `json:"notes,omitempty" form:"notes"`
+	HideDormant      bool       `json:"hide_dormant,omitempty" form:"hide_dormant"`
+	Sort             string     `json:"sort,omitempty" form:"sort"`
+}
+
+// ListTasksReq holds the filters, sort order and page of a task list request,
+// whether it arrives as URL query parameters or as a WebSocket message.
+type ListTasksReq struct {
+	TaskFilter
+	Cursor string `json:"cursor" form:"cursor"`
+	Limit  int    `json:"limit" form:"limit"`
+}
+
+// PreviewOccurrencesReq accepts the scheduling fields of a CreateTaskReq, so a
+// task can be previewed before it is saved.
+type PreviewOccurrencesReq struct {
+	NextDueDate       string          `json:"next_due_date"`
+	EndDate           string          `json:"end_date"`
+	MaxOccurrences    int             `json:"max_occurrences"`
+	IsRolling         bool            `json:"is_rolling"`
+	BusinessDays      BusinessDayRoll `json:"business_days"`
+	HolidayCalendarID *int            `json:"holiday_calendar_id"`
+	Frequency         Frequency       `json:"frequency"`
+	ActiveWindow      ActiveWindow    `json:"active_window"`
+	Count             int             `json:"count"`
 }
 
+// OccurrenceOverrideReq either moves the occurrence due at OriginalDate to
+// NewDate or cancels it.
+type OccurrenceOverrideReq struct {
+	OriginalDate string `json:"original_date" binding:"required"`
+	NewDate      string `json:"new_date"`
+	Cancelled    bool   `json:"cancelled"`
+}
+
+// UpdateDueDateReq moves the task's next due date. Version is as for
+// UpdateTaskReq.
 type UpdateDueDateReq struct {
 	DueDate string `json:"due_date" binding:"required"`
+	Version int    `json:"version"`
+}
+
+type RestoreTaskReq struct {
+	DueDate string `json:"due_date" binding:"required"`
+}
+
+// BackfillTaskReq records past completions of a task, given in RFC 3339.
+type BackfillTaskReq struct {
+	CompletedDates []string `json:"completed_dates" binding:"required"`
+}
+
+// UpdateTaskHistoryReq corrects when the occurrence recorded by a history
+// entry was completed.
+type UpdateTaskHistoryReq struct {
+	CompletedDate string `json:"completed_date" binding:"required"`
+}
+
+// CreateChecklistItemReq adds an item at Position, or at the end of the
+// checklist if no position is given.
+type CreateChecklistItemReq struct {
+	Title    string `json:"title" binding:"required"`
+	Position *int   `json:"position"`
+}
+
+// UpdateChecklistItemReq changes only the fields that are set.
+type UpdateChecklistItemReq struct {
+	Title    *string `json:"title"`
+	IsDone   *bool   `json:"is_done"`
+	Position *int    `json:"position"`
+}
+
+type AddDependencyReq struct {
+	BlockerID int `json:"blocker_id" binding:"required"`
 }
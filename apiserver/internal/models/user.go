@@ -12,12 +12,17 @@ type User struct {
 	UpdatedAt           time.Time  `json:"-" gorm:"column:updated_at;default:NULL;autoUpdateTime"`
 	Disabled            bool       `json:"-" gorm:"column:disabled;default:false"`
 	DeletionRequestedAt *time.Time `json:"deletion_requested_at" gorm:"column:deletion_requested_at;default:NULL"`
+	Timezone            string     `json:"timezone" gorm:"column:timezone;type:varchar(64);not null;default:''"`
 
 	NotificationSettings NotificationSettings `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
 	Labels               []Label              `json:"-" gorm:"foreignKey:CreatedBy;constraint:OnDelete:CASCADE;"`
 	Tasks                []Task               `json:"-" gorm:"foreignKey:CreatedBy;constraint:OnDelete:CASCADE;"`
 }
 
+type UpdateTimezoneReq struct {
+	Timezone string `json:"timezone"`
+}
+
 type IdentityType string
 
 const (
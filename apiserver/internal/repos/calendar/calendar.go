@@ -0,0 +1,115 @@
+package repos
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	config "taskwiz.app/core/config"
+	"taskwiz.app/core/internal/models"
+)
+
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB, cfg *config.Config) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+func holidaysByDate(db *gorm.DB) *gorm.DB {
+	return db.Order("date ASC")
+}
+
+func (r *CalendarRepository) GetUserCalendars(ctx context.Context, userID int) ([]*models.HolidayCalendar, error) {
+	var calendars []*models.HolidayCalendar
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Preload("Holidays", holidaysByDate).
+		Find(&calendars).Error; err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+// GetUserCalendar returns one of the user's calendars, or
+// gorm.ErrRecordNotFound if it belongs to someone else.
+func (r *CalendarRepository) GetUserCalendar(ctx context.Context, userID, calendarID int) (*models.HolidayCalendar, error) {
+	var calendar models.HolidayCalendar
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", calendarID, userID).
+		Preload("Holidays", holidaysByDate).
+		First(&calendar).Error; err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+func (r *CalendarRepository) CreateCalendar(ctx context.Context, calendar *models.HolidayCalendar) error {
+	return r.db.WithContext(ctx).Create(calendar).Error
+}
+
+// UpdateCalendar renames one of the user's calendars and replaces its
+// holidays. It returns gorm.ErrRecordNotFound if the calendar belongs to
+// someone else.
+func (r *CalendarRepository) UpdateCalendar(ctx context.Context, userID int, calendar *models.HolidayCalendar) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.HolidayCalendar{}).
+			Where("id = ? AND user_id = ?", calendar.ID, userID).
+			Update("name", calendar.Name)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("calendar_id = ?", calendar.ID).Delete(&models.Holiday{}).Error; err != nil {
+			return err
+		}
+
+		for i := range calendar.Holidays {
+			calendar.Holidays[i].ID = 0
+			calendar.Holidays[i].CalendarID = calendar.ID
+		}
+		if len(calendar.Holidays) > 0 {
+			if err := tx.Create(&calendar.Holidays).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteCalendar removes one of the user's calendars and detaches it from the
+// tasks using it. It returns gorm.ErrRecordNotFound if the calendar belongs to
+// someone else.
+func (r *CalendarRepository) DeleteCalendar(ctx context.Context, userID, calendarID int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Include tasks in the trash, so none is restored pointing at a
+		// calendar that no longer exists.
+		if err := tx.Unscoped().Model(&models.Task{}).
+			Where("holiday_calendar_id = ? AND created_by = ?", calendarID, userID).
+			Update("holiday_calendar_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("calendar_id IN (?)", tx.Model(&models.HolidayCalendar{}).
+			Select("id").
+			Where("id = ? AND user_id = ?", calendarID, userID)).
+			Delete(&models.Holiday{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", calendarID, userID).Delete(&models.HolidayCalendar{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/utils/test"
+)
+
+type CalendarTestSuite struct {
+	test.DatabaseTestSuite
+	repo     *CalendarRepository
+	testUser *models.User
+}
+
+func TestCalendarTestSuite(t *testing.T) {
+	suite.Run(t, new(CalendarTestSuite))
+}
+
+func (s *CalendarTestSuite) SetupTest() {
+	s.DatabaseTestSuite.SetupTest()
+	s.repo = &CalendarRepository{db: s.DB}
+
+	s.testUser = &models.User{
+		ID:        1,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.DB.Create(s.testUser).Error
+	s.Require().NoError(err)
+}
+
+func (s *CalendarTestSuite) createCalendar(name string, dates ...string) *models.HolidayCalendar {
+	calendar := &models.HolidayCalendar{Name: name, UserID: s.testUser.ID}
+	for _, date := range dates {
+		calendar.Holidays = append(calendar.Holidays, models.Holiday{Date: date})
+	}
+
+	err := s.repo.CreateCalendar(context.Background(), calendar)
+	s.Require().NoError(err)
+	return calendar
+}
+
+func (s *CalendarTestSuite) TestGetUserCalendars() {
+	ctx := context.Background()
+
+	s.createCalendar("Work", "2025-12-26", "2025-12-25")
+	s.createCalendar("School", "2025-07-01")
+
+	otherUser := &models.User{ID: 2, CreatedAt: time.Now()}
+	s.Require().NoError(s.DB.Create(otherUser).Error)
+	s.Require().NoError(s.DB.Create(&models.HolidayCalendar{Name: "Other", UserID: otherUser.ID}).Error)
+
+	calendars, err := s.repo.GetUserCalendars(ctx, s.testUser.ID)
+	s.Require().NoError(err)
+	s.Require().Len(calendars, 2)
+	s.Equal("School", calendars[0].Name)
+	s.Equal("Work", calendars[1].Name)
+
+	s.Require().Len(calendars[1].Holidays, 2)
+	s.Equal("2025-12-25", calendars[1].Holidays[0].Date)
+	s.Equal("2025-12-26", calendars[1].Holidays[1].Date)
+}
+
+func (s *CalendarTestSuite) TestGetUserCalendar() {
+	ctx := context.Background()
+	calendar := s.createCalendar("Work", "2025-12-25")
+
+	retrieved, err := s.repo.GetUserCalendar(ctx, s.testUser.ID, calendar.ID)
+	s.Require().NoError(err)
+	s.Equal("Work", retrieved.Name)
+	s.Require().Len(retrieved.Holidays, 1)
+
+	_, err = s.repo.GetUserCalendar(ctx, s.testUser.ID+1, calendar.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *CalendarTestSuite) TestUpdateCalendar() {
+	ctx := context.Background()
+	calendar := s.createCalendar("Work", "2025-12-25", "2025-12-26")
+
+	updated := &models.HolidayCalendar{
+		ID:       calendar.ID,
+		Name:     "Office",
+		Holidays: []models.Holiday{{Date: "2025-12-26"}, {Date: "2026-01-01", Name: "New Year's Day"}},
+	}
+	err := s.repo.UpdateCalendar(ctx, s.testUser.ID, updated)
+	s.Require().NoError(err)
+
+	retrieved, err := s.repo.GetUserCalendar(ctx, s.testUser.ID, calendar.ID)
+	s.Require().NoError(err)
+	s.Equal("Office", retrieved.Name)
+	s.Require().Len(retrieved.Holidays, 2)
+	s.Equal("2025-12-26", retrieved.Holidays[0].Date)
+	s.Equal("2026-01-01", retrieved.Holidays[1].Date)
+	s.Equal("New Year's Day", retrieved.Holidays[1].Name)
+
+	// Calendars of other users are left alone.
+	err = s.repo.UpdateCalendar(ctx, s.testUser.ID+1, &models.HolidayCalendar{ID: calendar.ID, Name: "Stolen"})
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *CalendarTestSuite) TestDeleteCalendar() {
+	ctx := context.Background()
+	calendar := s.createCalendar("Work", "2025-12-25")
+
+	task := &models.Task{
+		Title:             "Payroll",
+		CreatedBy:         s.testUser.ID,
+		IsActive:          true,
+		Frequency:         models.Frequency{Type: models.RepeatMonthly},
+		BusinessDays:      models.BusinessDaysForward,
+		HolidayCalendarID: &calendar.ID,
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	err := s.repo.DeleteCalendar(ctx, s.testUser.ID+1, calendar.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	err = s.repo.DeleteCalendar(ctx, s.testUser.ID, calendar.ID)
+	s.Require().NoError(err)
+
+	var holidays int64
+	s.Require().NoError(s.DB.Model(&models.Holiday{}).Where("calendar_id = ?", calendar.ID).Count(&holidays).Error)
+	s.Zero(holidays)
+
+	// Tasks using the calendar keep skipping weekends only.
+	var retrieved models.Task
+	s.Require().NoError(s.DB.First(&retrieved, task.ID).Error)
+	s.Nil(retrieved.HolidayCalendarID)
+	s.Equal(models.BusinessDaysForward, retrieved.BusinessDays)
+}
@@ -0,0 +1,93 @@
+package repos
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	config "taskwiz.app/core/config"
+	"taskwiz.app/core/internal/models"
+)
+
+type FilterRepository struct {
+	db *gorm.DB
+}
+
+func NewFilterRepository(db *gorm.DB, cfg *config.Config) *FilterRepository {
+	return &FilterRepository{db: db}
+}
+
+func (r *FilterRepository) GetUserFilters(ctx context.Context, userID int) ([]*models.SavedFilter, error) {
+	var filters []*models.SavedFilter
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&filters).Error; err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// GetUserFilter returns one of the user's saved filters, or
+// gorm.ErrRecordNotFound if it belongs to someone else.
+func (r *FilterRepository) GetUserFilter(ctx context.Context, userID, filterID int) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", filterID, userID).
+		First(&filter).Error; err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func (r *FilterRepository) CountUserFilters(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SavedFilter{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *FilterRepository) FilterExistsByName(ctx context.Context, userID int, name string, excludeFilterID int) (bool, error) {
+	var count int64
+	q := r.db.WithContext(ctx).Model(&models.SavedFilter{}).Where("user_id = ? AND name = ?", userID, name)
+	if excludeFilterID > 0 {
+		q = q.Where("id != ?", excludeFilterID)
+	}
+	if err := q.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *FilterRepository) CreateFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return r.db.WithContext(ctx).Create(filter).Error
+}
+
+// UpdateFilter renames one of the user's saved filters and replaces its
+// definition. It returns gorm.ErrRecordNotFound if the filter belongs to
+// someone else.
+func (r *FilterRepository) UpdateFilter(ctx context.Context, userID int, filter *models.SavedFilter) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.SavedFilter{}).
+		Where("id = ? AND user_id = ?", filter.ID, userID).
+		Select("name", "definition").
+		Updates(filter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteFilter removes one of the user's saved filters. It returns
+// gorm.ErrRecordNotFound if the filter belongs to someone else.
+func (r *FilterRepository) DeleteFilter(ctx context.Context, userID, filterID int) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", filterID, userID).Delete(&models.SavedFilter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
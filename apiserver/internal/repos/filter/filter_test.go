@@ -0,0 +1,133 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/utils/test"
+)
+
+type FilterTestSuite struct {
+	test.DatabaseTestSuite
+	repo     *FilterRepository
+	testUser *models.User
+}
+
+func TestFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterTestSuite))
+}
+
+func (s *FilterTestSuite) SetupTest() {
+	s.DatabaseTestSuite.SetupTest()
+	s.repo = &FilterRepository{db: s.DB}
+
+	s.testUser = &models.User{
+		ID:        1,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.DB.Create(s.testUser).Error
+	s.Require().NoError(err)
+}
+
+func (s *FilterTestSuite) createFilter(name string, filter models.TaskFilter) *models.SavedFilter {
+	saved := &models.SavedFilter{Name: name, UserID: s.testUser.ID, Filter: filter}
+	err := s.repo.CreateFilter(context.Background(), saved)
+	s.Require().NoError(err)
+	return saved
+}
+
+func (s *FilterTestSuite) TestGetUserFilters() {
+	ctx := context.Background()
+
+	recurring := true
+	s.createFilter("Weekend chores", models.TaskFilter{Labels: []int{3}, Recurring: &recurring, Sort: "due"})
+	s.createFilter("Overdue work", models.TaskFilter{Query: "label:work overdue"})
+
+	otherUser := &models.User{ID: 2, CreatedAt: time.Now()}
+	s.Require().NoError(s.DB.Create(otherUser).Error)
+	s.Require().NoError(s.repo.CreateFilter(ctx, &models.SavedFilter{Name: "Other", UserID: otherUser.ID}))
+
+	filters, err := s.repo.GetUserFilters(ctx, s.testUser.ID)
+	s.Require().NoError(err)
+	s.Require().Len(filters, 2)
+	s.Equal("Overdue work", filters[0].Name)
+	s.Equal("label:work overdue", filters[0].Filter.Query)
+	s.Equal("Weekend chores", filters[1].Name)
+	s.Equal([]int{3}, filters[1].Filter.Labels)
+	s.Require().NotNil(filters[1].Filter.Recurring)
+	s.True(*filters[1].Filter.Recurring)
+	s.Equal("due", filters[1].Filter.Sort)
+
+	count, err := s.repo.CountUserFilters(ctx, s.testUser.ID)
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+}
+
+func (s *FilterTestSuite) TestGetUserFilter() {
+	ctx := context.Background()
+	saved := s.createFilter("Overdue", models.TaskFilter{Overdue: true})
+
+	retrieved, err := s.repo.GetUserFilter(ctx, s.testUser.ID, saved.ID)
+	s.Require().NoError(err)
+	s.Equal("Overdue", retrieved.Name)
+	s.True(retrieved.Filter.Overdue)
+
+	_, err = s.repo.GetUserFilter(ctx, s.testUser.ID+1, saved.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *FilterTestSuite) TestFilterExistsByName() {
+	ctx := context.Background()
+	saved := s.createFilter("Overdue", models.TaskFilter{Overdue: true})
+
+	exists, err := s.repo.FilterExistsByName(ctx, s.testUser.ID, "Overdue", 0)
+	s.Require().NoError(err)
+	s.True(exists)
+
+	exists, err = s.repo.FilterExistsByName(ctx, s.testUser.ID, "Overdue", saved.ID)
+	s.Require().NoError(err)
+	s.False(exists)
+
+	exists, err = s.repo.FilterExistsByName(ctx, s.testUser.ID+1, "Overdue", 0)
+	s.Require().NoError(err)
+	s.False(exists)
+}
+
+func (s *FilterTestSuite) TestUpdateFilter() {
+	ctx := context.Background()
+	saved := s.createFilter("Overdue", models.TaskFilter{Overdue: true, Sort: "due"})
+
+	err := s.repo.UpdateFilter(ctx, s.testUser.ID, &models.SavedFilter{
+		ID:     saved.ID,
+		Name:   "Urgent",
+		Filter: models.TaskFilter{Query: "priority:urgent"},
+	})
+	s.Require().NoError(err)
+
+	retrieved, err := s.repo.GetUserFilter(ctx, s.testUser.ID, saved.ID)
+	s.Require().NoError(err)
+	s.Equal("Urgent", retrieved.Name)
+	s.Equal(models.TaskFilter{Query: "priority:urgent"}, retrieved.Filter)
+
+	err = s.repo.UpdateFilter(ctx, s.testUser.ID+1, &models.SavedFilter{ID: saved.ID, Name: "Stolen"})
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *FilterTestSuite) TestDeleteFilter() {
+	ctx := context.Background()
+	saved := s.createFilter("Overdue", models.TaskFilter{Overdue: true})
+
+	err := s.repo.DeleteFilter(ctx, s.testUser.ID+1, saved.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	err = s.repo.DeleteFilter(ctx, s.testUser.ID, saved.ID)
+	s.Require().NoError(err)
+
+	_, err = s.repo.GetUserFilter(ctx, s.testUser.ID, saved.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
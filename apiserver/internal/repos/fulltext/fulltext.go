@@ -0,0 +1,165 @@
+// Package fulltext maintains and queries task_search, the full-text index over
+// task titles and label names. SQLite deployments use an FTS5 table and MySQL
+// deployments a FULLTEXT index, chosen by the configured database type.
+package fulltext
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	config "taskwiz.app/core/config"
+)
+
+// Index reads and writes task_search. The zero value uses SQLite FTS5.
+type Index struct {
+	mysql bool
+}
+
+func NewIndex(cfg *config.Config) Index {
+	return Index{mysql: strings.ToLower(cfg.Database.Type) == "mysql"}
+}
+
+// Match is a task found by a search, with its relevance; higher scores are
+// more relevant.
+type Match struct {
+	TaskID int     `gorm:"column:task_id"`
+	Score  float64 `gorm:"column:score"`
+}
+
+// Sync brings the index entries of the given tasks up to date with their
+// titles and labels, dropping those of tasks that no longer exist or are in
+// the trash.
+func (i Index) Sync(db *gorm.DB, taskIDs ...int) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	key, concat := "rowid", "group_concat(l.name, ' ')"
+	if i.mysql {
+		key, concat = "task_id", "GROUP_CONCAT(l.name SEPARATOR ' ')"
+	}
+
+	if err := db.Exec("DELETE FROM task_search WHERE "+key+" IN ?", taskIDs).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`INSERT INTO task_search (`+key+`, title, labels)
+		SELECT t.id, t.title, COALESCE((
+			SELECT `+concat+` FROM task_labels tl
+			JOIN labels l ON l.id = tl.label_id
+			WHERE tl.task_id = t.id
+		), '')
+		FROM tasks t WHERE t.id IN ? AND t.deleted_at IS NULL`, taskIDs).Error
+}
+
+// SyncLabel updates the index entries of every task carrying the label.
+func (i Index) SyncLabel(db *gorm.DB, labelID int) error {
+	var taskIDs []int
+	if err := db.Table("task_labels").Where("label_id = ?", labelID).Pluck("task_id", &taskIDs).Error; err != nil {
+		return err
+	}
+	return i.Sync(db, taskIDs...)
+}
+
+// Search returns up to limit of the user's active tasks whose title or labels
+// contain a word starting with each of terms, most relevant first.
+func (i Index) Search(db *gorm.DB, userID int, terms []string, limit int) ([]Match, error) {
+	var matches []Match
+	if len(terms) == 0 {
+		return matches, nil
+	}
+
+	if i.mysql {
+		// Boolean mode lets every term be required and matched as a prefix.
+		// Terms are letters and digits only, so they cannot inject operators.
+		against := "+" + strings.Join(terms, "* +") + "*"
+		err := db.Raw(`SELECT s.task_id AS task_id, MATCH(s.title, s.labels) AGAINST (? IN BOOLEAN MODE) AS score
+			FROM task_search s
+			JOIN tasks t ON t.id = s.task_id
+			WHERE MATCH(s.title, s.labels) AGAINST (? IN BOOLEAN MODE)
+				AND t.created_by = ? AND t.is_active = 1
+			ORDER BY score DESC, t.id ASC
+			LIMIT ?`, against, against, userID, limit).Scan(&matches).Error
+		return matches, err
+	}
+
+	// bm25 is lower for better matches, so negate it; titles weigh twice as
+	// much as labels. Each term is quoted so FTS5 reads it as a string.
+	match := `"` + strings.Join(terms, `"* AND "`) + `"*`
+	err := db.Raw(`SELECT task_search.rowid AS task_id, -bm25(task_search, 2.0, 1.0) AS score
+		FROM task_search
+		JOIN tasks t ON t.id = task_search.rowid
+		WHERE task_search MATCH ?
+			AND t.created_by = ? AND t.is_active = 1
+		ORDER BY score DESC, t.id ASC
+		LIMIT ?`, match, userID, limit).Scan(&matches).Error
+	return matches, err
+}
+
+// maxTerms bounds how many words of a query are searched for.
+const maxTerms = 10
+
+// Terms splits a query into the lower-cased, distinct words to search for.
+// Punctuation separates words and is otherwise ignored, as the index does.
+func Terms(query string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), isSeparator) {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// Highlight returns text, HTML-escaped, with each word starting with one of
+// terms wrapped in <mark> tags, and whether any word was marked.
+func Highlight(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	marked := false
+
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		end := start
+		if isSeparator(runes[start]) {
+			for end < len(runes) && isSeparator(runes[end]) {
+				end++
+			}
+			b.WriteString(html.EscapeString(string(runes[start:end])))
+			start = end
+			continue
+		}
+
+		for end < len(runes) && !isSeparator(runes[end]) {
+			end++
+		}
+		word := string(runes[start:end])
+		if hasTermPrefix(strings.ToLower(word), terms) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+			marked = true
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		start = end
+	}
+
+	return b.String(), marked
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func hasTermPrefix(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
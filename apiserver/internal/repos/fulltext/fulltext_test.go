@@ -0,0 +1,55 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"Kitchen", []string{"kitchen"}},
+		{"clean the KITCHEN, clean!", []string{"clean", "the", "kitchen"}},
+		{`"quoted"* AND -label:home`, []string{"quoted", "and", "label", "home"}},
+		{"Café déjà-vu 2024", []string{"café", "déjà", "vu", "2024"}},
+		{"a b c d e f g h i j k l", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, Terms(tt.query))
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		terms  []string
+		want   string
+		marked bool
+	}{
+		{"no match", "Buy milk", []string{"bread"}, "Buy milk", false},
+		{"prefix", "Water the plants", []string{"plan"}, "Water the <mark>plants</mark>", true},
+		{"case insensitive", "WATER plants", []string{"water"}, "<mark>WATER</mark> plants", true},
+		{"several terms", "Water the plants", []string{"wat", "pl"}, "<mark>Water</mark> the <mark>plants</mark>", true},
+		{"word prefix only", "Unplanned", []string{"plan"}, "Unplanned", false},
+		{"escapes html", `<script>alert("x")</script>`, []string{"alert"}, `&lt;script&gt;<mark>alert</mark>(&#34;x&#34;)&lt;/script&gt;`, true},
+		{"unicode", "Café crème", []string{"crè"}, "Café <mark>crème</mark>", true},
+		{"empty", "", []string{"x"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, marked := Highlight(tt.text, tt.terms)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.marked, marked)
+		})
+	}
+}
@@ -8,14 +8,16 @@ import (
 	"gorm.io/gorm"
 	config "taskwiz.app/core/config"
 	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/repos/fulltext"
 )
 
 type LabelRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	search fulltext.Index
 }
 
 func NewLabelRepository(db *gorm.DB, cfg *config.Config) *LabelRepository {
-	return &LabelRepository{db: db}
+	return &LabelRepository{db: db, search: fulltext.NewIndex(cfg)}
 }
 
 func (r *LabelRepository) GetUserLabels(ctx context.Context, userID int) ([]*models.Label, error) {
@@ -78,7 +80,7 @@ func (r *LabelRepository) AssignLabelsToTask(ctx context.Context, taskID int, us
 			return err
 		}
 
-		return nil
+		return r.search.Sync(tx, taskID)
 	})
 }
 
@@ -93,14 +95,24 @@ func (r *LabelRepository) DeleteLabel(ctx context.Context, userID int, labelID i
 			return errors.New("label is not owned by user")
 		}
 
+		var taskIDs []int
+		if err := tx.Model(&models.TaskLabel{}).Where("label_id = ?", labelID).Pluck("task_id", &taskIDs).Error; err != nil {
+			return err
+		}
+
 		if err := tx.Where("id = ?", labelID).Delete(&models.Label{}).Error; err != nil {
 			return fmt.Errorf("error deleting label: %s", err.Error())
 		}
 
-		return nil
+		return r.search.Sync(tx, taskIDs...)
 	})
 }
 
 func (r *LabelRepository) UpdateLabel(ctx context.Context, userID int, label *models.Label) error {
-	return r.db.WithContext(ctx).Model(&models.Label{}).Where("id = ? and created_by = ?", label.ID, userID).Updates(label).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Label{}).Where("id = ? and created_by = ?", label.ID, userID).Updates(label).Error; err != nil {
+			return err
+		}
+		return r.search.SyncLabel(tx, label.ID)
+	})
 }
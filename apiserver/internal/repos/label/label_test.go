@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/repos/fulltext"
 	"taskwiz.app/core/internal/utils/test"
 )
 
@@ -211,3 +212,40 @@ func (s *LabelTestSuite) TestUpdateLabel() {
 	s.Equal("Updated Work", updatedLabel.Name)
 	s.Equal("#FF5500", updatedLabel.Color)
 }
+
+func (s *LabelTestSuite) TestLabelChangesSyncSearchIndex() {
+	ctx := context.Background()
+
+	label := &models.Label{Name: "Garden", Color: "#00FF00", CreatedBy: s.testUser.ID}
+	err := s.DB.Create(label).Error
+	s.Require().NoError(err)
+
+	task := &models.Task{Title: "Water", CreatedBy: s.testUser.ID, IsActive: true}
+	err = s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	searchFor := func(query string) []int {
+		matches, err := s.repo.search.Search(s.DB, s.testUser.ID, fulltext.Terms(query), 10)
+		s.Require().NoError(err)
+		var ids []int
+		for _, match := range matches {
+			ids = append(ids, match.TaskID)
+		}
+		return ids
+	}
+
+	err = s.repo.AssignLabelsToTask(ctx, task.ID, s.testUser.ID, []int{label.ID})
+	s.Require().NoError(err)
+	s.Equal([]int{task.ID}, searchFor("garden"))
+
+	label.Name = "Balcony"
+	err = s.repo.UpdateLabel(ctx, s.testUser.ID, label)
+	s.Require().NoError(err)
+	s.Empty(searchFor("garden"))
+	s.Equal([]int{task.ID}, searchFor("balcony"))
+
+	err = s.repo.DeleteLabel(ctx, s.testUser.ID, label.ID)
+	s.Require().NoError(err)
+	s.Empty(searchFor("balcony"))
+	s.Equal([]int{task.ID}, searchFor("water"))
+}
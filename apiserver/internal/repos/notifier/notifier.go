@@ -50,7 +50,7 @@ func (r *NotificationRepository) GetNotificationsWithMissingUserOrTask(c context
 			FROM notifications n
 			LEFT JOIN users u ON n.user_id = u.id
 			LEFT JOIN tasks t ON n.task_id = t.id
-			WHERE u.id IS NULL OR t.id IS NULL
+			WHERE u.id IS NULL OR t.id IS NULL OR t.deleted_at IS NOT NULL
 		`).Scan(&notifications).Error
 	if err != nil {
 		return nil, err
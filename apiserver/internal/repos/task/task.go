@@ -1,224 +1,1806 @@
 package repos
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	config "taskwiz.app/core/config"
 	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/repos/fulltext"
+	"taskwiz.app/core/internal/utils/ics"
+	"taskwiz.app/core/internal/utils/rrule"
+	"taskwiz.app/core/internal/utils/search"
 )
 
 type TaskRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	search fulltext.Index
 }
 
 func NewTaskRepository(db *gorm.DB, cfg *config.Config) *TaskRepository {
-	return &TaskRepository{db: db}
+	return &TaskRepository{db: db, search: fulltext.NewIndex(cfg)}
 }
 
-func (r *TaskRepository) UpsertTask(c context.Context, task *models.Task) error {
-	return r.db.WithContext(c).Model(&task).Save(task).Error
+// ErrVersionConflict indicates that a task was changed since the version an
+// edit was made to.
+var ErrVersionConflict = errors.New("task version conflict")
+
+// UpsertTask saves task, replaces its labels with labels unless that is nil
+// and, if audit is not nil and lists changes, records the edit in the task's
+// audit trail. An existing task is only saved if it is still at
+// task.Version, which is then incremented; otherwise ErrVersionConflict is
+// returned and nothing is changed.
+func (r *TaskRepository) UpsertTask(c context.Context, task *models.Task, labels []int, audit *models.TaskAudit) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if task.ID > 0 {
+			result := tx.Model(&models.Task{}).
+				Where("id = ? AND version = ?", task.ID, task.Version).
+				Update("version", gorm.Expr("version + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrVersionConflict
+			}
+			task.Version++
+		}
+
+		// Edits are built from the request rather than the stored task, so
+		// they must not overwrite when it was created.
+		save := tx.Model(&task)
+		if task.ID > 0 {
+			save = save.Omit("created_at")
+		}
+		if err := save.Save(task).Error; err != nil {
+			return err
+		}
+		if labels != nil {
+			if err := replaceTaskLabels(tx, task.ID, labels); err != nil {
+				return err
+			}
+		}
+		if audit != nil && len(audit.Changes) > 0 {
+			audit.TaskID = task.ID
+			audit.CreatedAt = time.Now().UTC()
+			if err := tx.Create(audit).Error; err != nil {
+				return err
+			}
+		}
+		return r.search.Sync(tx, task.ID)
+	})
+}
+
+func replaceTaskLabels(tx *gorm.DB, taskID int, labels []int) error {
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+
+	taskLabels := make([]*models.TaskLabel, 0, len(labels))
+	for _, labelID := range labels {
+		taskLabels = append(taskLabels, &models.TaskLabel{TaskID: taskID, LabelID: labelID})
+	}
+	return tx.Create(&taskLabels).Error
+}
+
+// GetTaskAudit returns the audit trail of a task, most recent edit first.
+func (r *TaskRepository) GetTaskAudit(c context.Context, taskID int) ([]*models.TaskAudit, error) {
+	var audits []*models.TaskAudit
+	if err := r.db.WithContext(c).
+		Where("task_id = ?", taskID).
+		Order("created_at DESC, id DESC").
+		Find(&audits).Error; err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
+
+func (r *TaskRepository) CreateTask(c context.Context, task *models.Task) (int, error) {
+	if err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		return r.search.Sync(tx, task.ID)
+	}); err != nil {
+		return 0, err
+	}
+	return task.ID, nil
+}
+
+func (r *TaskRepository) GetTask(c context.Context, taskID int) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.WithContext(c).
+		Model(&models.Task{}).
+		Preload("Labels").
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Order("original_date ASC")
+		}).
+		Preload("HolidayCalendar.Holidays").
+		Preload("Checklist", checklistOrder).
+		First(&task, taskID).Error; err != nil {
+		return nil, err
+	}
+	if err := markBlocked(r.db.WithContext(c), &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTasks returns the user's active tasks in the given order, or by due
+// date when sorts is empty.
+func (r *TaskRepository) GetTasks(c context.Context, userID int, sorts ...models.TaskSort) ([]*models.Task, error) {
+	tasks, _, err := r.ListTasks(c, userID, models.TaskQuery{Sort: sorts})
+	return tasks, err
+}
+
+// ErrInvalidCursor indicates that the task a list was to resume after does not
+// exist or belongs to another user.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListTasks returns the page of the user's active tasks selected by query, or
+// of their archived or deleted tasks if query.Archived or query.Deleted is
+// set. If more tasks follow, nextAfterID is the ID to resume after; otherwise
+// it is zero.
+func (r *TaskRepository) ListTasks(c context.Context, userID int, query models.TaskQuery) (tasks []*models.Task, nextAfterID int, err error) {
+	db := r.db.WithContext(c)
+
+	var q *gorm.DB
+	if query.Deleted {
+		db = db.Unscoped().Session(&gorm.Session{})
+		q = db.Where("tasks.created_by = ? AND tasks.deleted_at IS NOT NULL", userID)
+	} else {
+		q = db.Where("tasks.created_by = ? AND tasks.is_active = ?", userID, !query.Archived)
+	}
+	q = q.Scopes(filterTasks(query)).
+		Scopes(sortTasks(query.Sort))
+
+	if query.AfterID > 0 {
+		var count int64
+		if err := db.Model(&models.Task{}).
+			Where("id = ? AND created_by = ?", query.AfterID, userID).
+			Count(&count).Error; err != nil {
+			return nil, 0, err
+		}
+		if count == 0 {
+			return nil, 0, ErrInvalidCursor
+		}
+		q = q.Scopes(afterTask(query.Sort, query.AfterID))
+	}
+
+	if query.Limit > 0 {
+		// Fetch one task more than asked for to learn whether a page follows.
+		q = q.Limit(query.Limit + 1)
+	}
+
+	if err := q.Preload("Labels").
+		Preload("Checklist", checklistOrder).
+		Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Limit > 0 && len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+		nextAfterID = tasks[len(tasks)-1].ID
+	}
+
+	if err := markBlocked(db, tasks...); err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, nextAfterID, nil
+}
+
+// filterTasks applies the filters of query to a task query.
+func filterTasks(query models.TaskQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(query.Labels) > 0 {
+			labels := slices.Compact(slices.Sorted(slices.Values(query.Labels)))
+			if query.LabelMatch == models.LabelMatchAll {
+				db = db.Where("tasks.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+					Table("task_labels").
+					Select("task_id").
+					Where("label_id IN ?", labels).
+					Group("task_id").
+					Having("COUNT(*) = ?", len(labels)))
+			} else {
+				db = db.Where("tasks.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+					Table("task_labels").
+					Select("task_id").
+					Where("label_id IN ?", labels))
+			}
+		}
+
+		if query.DueAfter != nil {
+			db = db.Where("tasks.next_due_date >= ?", *query.DueAfter)
+		}
+		if query.DueBefore != nil {
+			db = db.Where("tasks.next_due_date < ?", *query.DueBefore)
+		}
+		if query.Overdue {
+			db = db.Where("tasks.next_due_date < ?", time.Now().UTC())
+		}
+
+		if query.Recurring != nil {
+			if *query.Recurring {
+				db = db.Where("tasks.frequency_type <> ?", models.RepeatOnce)
+			} else {
+				db = db.Where("tasks.frequency_type = ?", models.RepeatOnce)
+			}
+		}
+
+		if query.HasNotifications != nil {
+			db = db.Where("tasks.notification_enabled = ?", *query.HasNotifications)
+		}
+
+		if query.Search != nil {
+			loc := query.Location
+			if loc == nil {
+				loc = time.UTC
+			}
+			now := time.Now()
+			for _, term := range query.Search.Terms {
+				cond, args, err := searchCondition(term.Filter, query.SearchNotes, now, loc)
+				if err != nil {
+					_ = db.AddError(err)
+					return db
+				}
+				if term.Negated {
+					cond = "NOT (" + cond + ")"
+				}
+				db = db.Where(cond, args...)
+			}
+		}
+
+		return db
+	}
+}
+
+// likePattern turns text into a LIKE pattern matching it anywhere in a
+// lower-cased column. LIKE wildcards in text are escaped so they match
+// literally. Use '!' as the escape character because backslash has
+// dialect-specific meaning inside MySQL string literals by default and would
+// produce a SQL syntax error.
+func likePattern(text string) string {
+	escaped := strings.ReplaceAll(text, "!", "!!")
+	escaped = strings.ReplaceAll(escaped, "%", "!%")
+	escaped = strings.ReplaceAll(escaped, "_", "!_")
+	return "%" + strings.ToLower(escaped) + "%"
+}
+
+// searchCondition compiles a search filter into a SQL condition on the tasks
+// table. Conditions never evaluate to NULL, so that negating them with NOT
+// keeps exactly the tasks they leave out.
+func searchCondition(filter search.Filter, searchNotes bool, now time.Time, loc *time.Location) (string, []interface{}, error) {
+	switch f := filter.(type) {
+	case search.Text:
+		pattern := likePattern(f.Value)
+		if searchNotes {
+			return "(LOWER(tasks.title) LIKE ? ESCAPE '!' OR LOWER(COALESCE(tasks.notes, '')) LIKE ? ESCAPE '!')", []interface{}{pattern, pattern}, nil
+		}
+		return "LOWER(tasks.title) LIKE ? ESCAPE '!'", []interface{}{pattern}, nil
+
+	case search.Label:
+		return "EXISTS (SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND LOWER(l.name) = ?)",
+			[]interface{}{strings.ToLower(f.Name)}, nil
+
+	case search.Due:
+		if f.None {
+			return "tasks.next_due_date IS NULL", nil, nil
+		}
+		start := f.Date.Start(now, loc)
+		end := start.AddDate(0, 0, 1)
+		switch f.Op {
+		case search.Less:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date < ?)", []interface{}{start.UTC()}, nil
+		case search.LessEqual:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date < ?)", []interface{}{end.UTC()}, nil
+		case search.Greater:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date >= ?)", []interface{}{end.UTC()}, nil
+		case search.GreaterEqual:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date >= ?)", []interface{}{start.UTC()}, nil
+		default:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date >= ? AND tasks.next_due_date < ?)", []interface{}{start.UTC(), end.UTC()}, nil
+		}
+
+	case search.Priority:
+		switch f.Op {
+		case search.Equal, search.Less, search.LessEqual, search.Greater, search.GreaterEqual:
+			return "tasks.priority " + string(f.Op) + " ?", []interface{}{f.Level}, nil
+		}
+
+	case search.Is:
+		switch f.State {
+		case search.Overdue:
+			return "(tasks.next_due_date IS NOT NULL AND tasks.next_due_date < ?)", []interface{}{now.UTC()}, nil
+		case search.Recurring:
+			return "tasks.frequency_type <> ?", []interface{}{models.RepeatOnce}, nil
+		case search.Once:
+			return "tasks.frequency_type = ?", []interface{}{models.RepeatOnce}, nil
+		case search.Blocked:
+			return "EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id = tasks.id AND b.is_active = 1 AND b.deleted_at IS NULL)", nil, nil
+		}
+
+	case search.Has:
+		switch f.Field {
+		case search.Notes:
+			return "COALESCE(tasks.notes, '') <> ''", nil, nil
+		case search.Labels:
+			return "EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = tasks.id)", nil, nil
+		case search.DueDate:
+			return "tasks.next_due_date IS NOT NULL", nil, nil
+		case search.Notifications:
+			return "tasks.notification_enabled = ?", []interface{}{true}, nil
+		case search.Checklist:
+			return "EXISTS (SELECT 1 FROM checklist_items ci WHERE ci.task_id = tasks.id)", nil, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("unsupported search filter %#v", filter)
+}
+
+// sortColumns maps each sort key to the expression it orders by, with %s
+// standing for the tasks table, and whether that expression can be NULL.
+var sortColumns = map[models.TaskSortKey]struct {
+	expr     string
+	nullable bool
+}{
+	models.TaskSortDue:      {"%s.next_due_date", true},
+	models.TaskSortPriority: {"%s.priority", false},
+	// Compare titles in lower case, since SQLite and MySQL disagree on the
+	// case sensitivity of their default collations.
+	models.TaskSortTitle:    {"LOWER(%s.title)", false},
+	models.TaskSortCreated:  {"%s.created_at", false},
+	models.TaskSortUpdated:  {"%s.updated_at", true},
+	models.TaskSortArchived: {"%s.archived_at", true},
+	models.TaskSortDeleted:  {"%s.deleted_at", true},
+}
+
+// sortTerm is one expression of a task list's ORDER BY clause.
+type sortTerm struct {
+	expr       string
+	descending bool
+	nullable   bool
+}
+
+// sortTerms expands sorts, defaulting to the due date, into the terms a task
+// list is ordered by. NULLs are placed last explicitly because SQLite and
+// MySQL both put them first in ascending order and last in descending order,
+// and the task ID breaks ties so that the order is stable.
+func sortTerms(sorts []models.TaskSort) []sortTerm {
+	if len(sorts) == 0 {
+		sorts = []models.TaskSort{{Key: models.TaskSortDue}}
+	}
+
+	var terms []sortTerm
+	for _, sort := range sorts {
+		column, ok := sortColumns[sort.Key]
+		if !ok {
+			continue
+		}
+		if column.nullable {
+			terms = append(terms, sortTerm{expr: "CASE WHEN " + column.expr + " IS NULL THEN 1 ELSE 0 END"})
+		}
+		terms = append(terms, sortTerm{expr: column.expr, descending: sort.Descending, nullable: column.nullable})
+	}
+	return append(terms, sortTerm{expr: "%s.id"})
+}
+
+// sortTasks orders a task query by sorts.
+func sortTasks(sorts []models.TaskSort) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, term := range sortTerms(sorts) {
+			direction := "ASC"
+			if term.descending {
+				direction = "DESC"
+			}
+			db = db.Order(expand(term.expr, "tasks") + " " + direction)
+		}
+		return db
+	}
+}
+
+// afterTask keeps the tasks that follow the task afterID in the order given by
+// sorts. The task's sort values are read in SQL rather than round-tripped
+// through the cursor, so that they compare exactly as stored.
+func afterTask(sorts []models.TaskSort, afterID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		terms := sortTerms(sorts)
+
+		var clauses []string
+		var args []interface{}
+		for i, term := range terms {
+			var parts []string
+			for _, prev := range terms[:i] {
+				// Earlier nullable terms are preceded by their NULL flag, so
+				// if the flags match either both values are NULL or neither.
+				cond := "%[1]s = (SELECT %[2]s FROM tasks AS cursor_task WHERE cursor_task.id = ?)"
+				if prev.nullable {
+					cond = "(" + cond + " OR %[1]s IS NULL)"
+				}
+				parts = append(parts, fmt.Sprintf(cond, expand(prev.expr, "tasks"), expand(prev.expr, "cursor_task")))
+				args = append(args, afterID)
+			}
+
+			op := ">"
+			if term.descending {
+				op = "<"
+			}
+			parts = append(parts, fmt.Sprintf("%s %s (SELECT %s FROM tasks AS cursor_task WHERE cursor_task.id = ?)",
+				expand(term.expr, "tasks"), op, expand(term.expr, "cursor_task")))
+			args = append(args, afterID)
+
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
+
+		return db.Where(strings.Join(clauses, " OR "), args...)
+	}
+}
+
+// expand fills the table name into a sort expression.
+func expand(expr, table string) string {
+	return strings.ReplaceAll(expr, "%s", table)
+}
+
+// auditedFields lists the task fields recorded in the audit trail, by their
+// JSON names, with how to read each of them.
+var auditedFields = []struct {
+	name  string
+	value func(*models.Task) interface{}
+}{
+	{"title", func(t *models.Task) interface{} { return t.Title }},
+	{"notes", func(t *models.Task) interface{} { return t.Notes }},
+	{"priority", func(t *models.Task) interface{} { return t.Priority }},
+	{"frequency", func(t *models.Task) interface{} { return t.Frequency }},
+	{"active_window", func(t *models.Task) interface{} { return t.ActiveWindow }},
+	{"next_due_date", func(t *models.Task) interface{} { return utcTime(t.NextDueDate) }},
+	{"end_date", func(t *models.Task) interface{} { return utcTime(t.EndDate) }},
+	{"max_occurrences", func(t *models.Task) interface{} { return t.MaxOccurrences }},
+	{"is_rolling", func(t *models.Task) interface{} { return t.IsRolling }},
+	{"catch_up", func(t *models.Task) interface{} { return t.CatchUp }},
+	{"business_days", func(t *models.Task) interface{} { return t.BusinessDays }},
+	{"holiday_calendar_id", func(t *models.Task) interface{} { return t.HolidayCalendarID }},
+	{"habit_target", func(t *models.Task) interface{} { return t.HabitTarget }},
+	{"enforce_dependencies", func(t *models.Task) interface{} { return t.EnforceDeps }},
+	{"notification", func(t *models.Task) interface{} { return t.Notification }},
+	{"labels", func(t *models.Task) interface{} {
+		ids := make([]int, 0, len(t.Labels))
+		for _, label := range t.Labels {
+			ids = append(ids, label.ID)
+		}
+		slices.Sort(ids)
+		return ids
+	}},
+}
+
+// DiffTask returns the audited fields whose values differ between before and
+// after, with their JSON values on each side. Labels are compared by ID.
+func DiffTask(before, after *models.Task) []models.FieldChange {
+	var changes []models.FieldChange
+	for _, field := range auditedFields {
+		b, errB := json.Marshal(field.value(before))
+		a, errA := json.Marshal(field.value(after))
+		if errB != nil || errA != nil || bytes.Equal(a, b) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: field.name, Before: b, After: a})
+	}
+	return changes
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// GetRecentActivity returns up to limit entries of the user's activity feed,
+// merging the history and audit entries of their tasks, most recently
+// recorded first. If before is not nil, only the entries following it are
+// returned; ErrInvalidCursor is returned if the user has no such entry.
+func (r *TaskRepository) GetRecentActivity(c context.Context, userID int, before *models.ActivityRef, limit int) ([]*models.ActivityEntry, error) {
+	db := r.db.WithContext(c)
+
+	historyQuery := db.Table("task_histories AS th").
+		Select(`th.id AS id, 'history' AS type, th.task_id AS task_id, t.title AS task_title,
+			th.recorded_at AS recorded_at, th.completed_date AS completed_date, th.due_date AS due_date,
+			th.progress AS progress, th.target AS target,
+			CASE WHEN th.id = (SELECT MAX(th2.id) FROM task_histories th2 WHERE th2.task_id = th.task_id) THEN 1 ELSE 0 END AS is_latest,
+			(SELECT COUNT(*) FROM task_histories th2 WHERE th2.task_id = th.task_id AND th2.id >= th.id) AS undo_steps`).
+		Joins("JOIN tasks t ON t.id = th.task_id").
+		Where("t.created_by = ? AND t.deleted_at IS NULL", userID)
+
+	auditQuery := db.Table("task_audits AS ta").
+		Select(`ta.id AS id, 'audit' AS type, ta.task_id AS task_id, t.title AS task_title,
+			ta.created_at AS recorded_at, ta.changes AS changes`).
+		Joins("JOIN tasks t ON t.id = ta.task_id").
+		Where("t.created_by = ? AND t.deleted_at IS NULL", userID)
+
+	if before != nil {
+		recordedAt, err := activityRecordedAt(db, userID, *before)
+		if err != nil {
+			return nil, err
+		}
+		cond, args := activityBefore(models.ActivityHistory, "th.recorded_at", "th.id", *before, recordedAt)
+		historyQuery = historyQuery.Where(cond, args...)
+		cond, args = activityBefore(models.ActivityAudit, "ta.created_at", "ta.id", *before, recordedAt)
+		auditQuery = auditQuery.Where(cond, args...)
+	}
+
+	var entries, audits []*models.ActivityEntry
+	if err := historyQuery.Order("th.recorded_at DESC, th.id DESC").Limit(limit).Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	if err := auditQuery.Order("ta.created_at DESC, ta.id DESC").Limit(limit).Scan(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	entries = append(entries, audits...)
+	slices.SortFunc(entries, func(a, b *models.ActivityEntry) int {
+		return compareActivity(b, a)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// activityRank orders the entries of the activity feed recorded at the same
+// time: history entries count as later than audit entries.
+var activityRank = map[models.ActivityType]int{
+	models.ActivityAudit:   0,
+	models.ActivityHistory: 1,
+}
+
+// compareActivity orders activity entries from the earliest recorded to the
+// latest.
+func compareActivity(a, b *models.ActivityEntry) int {
+	if c := a.RecordedAt.Compare(b.RecordedAt); c != 0 {
+		return c
+	}
+	if c := activityRank[a.Type] - activityRank[b.Type]; c != 0 {
+		return c
+	}
+	return a.ID - b.ID
+}
+
+// activityBefore returns the condition selecting the entries of the given
+// type that follow before, recorded at recordedAt, in the activity feed as
+// ordered by compareActivity.
+func activityBefore(entryType models.ActivityType, timeColumn, idColumn string, before models.ActivityRef, recordedAt time.Time) (string, []interface{}) {
+	switch {
+	case entryType == before.Type:
+		return fmt.Sprintf("(%s < ? OR (%s = ? AND %s < ?))", timeColumn, timeColumn, idColumn),
+			[]interface{}{recordedAt, recordedAt, before.ID}
+	case activityRank[entryType] < activityRank[before.Type]:
+		return timeColumn + " <= ?", []interface{}{recordedAt}
+	default:
+		return timeColumn + " < ?", []interface{}{recordedAt}
+	}
+}
+
+// activityRecordedAt returns when the user's activity entry ref was recorded,
+// or ErrInvalidCursor if there is no such entry.
+func activityRecordedAt(db *gorm.DB, userID int, ref models.ActivityRef) (time.Time, error) {
+	table, column := "task_histories", "recorded_at"
+	if ref.Type == models.ActivityAudit {
+		table, column = "task_audits", "created_at"
+	}
+
+	var recordedAt []time.Time
+	if err := db.Table(table+" AS e").
+		Joins("JOIN tasks t ON t.id = e.task_id").
+		Where("e.id = ? AND t.created_by = ?", ref.ID, userID).
+		Pluck("e."+column, &recordedAt).Error; err != nil {
+		return time.Time{}, err
+	}
+	if len(recordedAt) == 0 {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return recordedAt[0], nil
+}
+
+// DeleteTask moves a task to the trash, dropping its pending notifications.
+// Its history stays until the trash is purged.
+func (r *TaskRepository) DeleteTask(c context.Context, id int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", id).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		return r.search.Sync(tx, id)
+	})
+}
+
+// GetDeletedTask returns a task in the trash, or gorm.ErrRecordNotFound if
+// there is no such task in the trash.
+func (r *TaskRepository) GetDeletedTask(c context.Context, taskID int) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.WithContext(c).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", taskID).
+		First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UndeleteTask takes a task out of the trash. It returns
+// gorm.ErrRecordNotFound if the task is not in the trash.
+func (r *TaskRepository) UndeleteTask(c context.Context, taskID int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Model(&models.Task{}).
+			Where("id = ? AND deleted_at IS NOT NULL", taskID).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return r.search.Sync(tx, taskID)
+	})
+}
+
+// PurgeDeletedTasks permanently deletes the tasks moved to the trash before
+// the given time, with their history, and returns how many it deleted.
+func (r *TaskRepository) PurgeDeletedTasks(c context.Context, before time.Time) (int, error) {
+	return r.purgeTasks(c, "deleted_at IS NOT NULL AND deleted_at < ?", before)
+}
+
+// SearchTasks finds up to limit of the user's active tasks whose title or
+// labels contain words starting with every word of query, most relevant
+// first, with the matching words highlighted.
+func (r *TaskRepository) SearchTasks(c context.Context, userID int, query string, limit int) ([]*models.TaskSearchResult, error) {
+	db := r.db.WithContext(c)
+	terms := fulltext.Terms(query)
+	matches, err := r.search.Search(db, userID, terms, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.TaskSearchResult, 0, len(matches))
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	ids := make([]int, len(matches))
+	for i, match := range matches {
+		ids[i] = match.TaskID
+	}
+	var tasks []*models.Task
+	if err := db.Where("id IN ?", ids).
+		Preload("Labels").
+		Preload("Checklist", checklistOrder).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := markBlocked(db, tasks...); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	for _, match := range matches {
+		task, ok := byID[match.TaskID]
+		if !ok {
+			continue
+		}
+		result := &models.TaskSearchResult{Task: task, Score: match.Score}
+		result.Snippet, _ = fulltext.Highlight(task.Title, terms)
+		for _, label := range task.Labels {
+			if name, ok := fulltext.Highlight(label.Name, terms); ok {
+				result.MatchedLabels = append(result.MatchedLabels, name)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (r *TaskRepository) IsTaskOwner(c context.Context, taskID int, userID int) error {
+	var task models.Task
+	return r.db.WithContext(c).Model(&models.Task{}).Where("id = ? AND created_by = ?", taskID, userID).First(&task).Error
+}
+
+// CompleteTask records the completion (or, with a nil completedDate, the skip)
+// of the task's current occurrence and moves it to dueDate. Any missed
+// occurrences are recorded as skipped after it, oldest first. The checklist is
+// unchecked for the next occurrence, if there is one.
+//
+// Every history entry, completed or skipped, uses up one of the task's
+// MaxOccurrences; once they are used up the task is deactivated regardless of
+// dueDate. Since the count is derived from the history, reverting an entry
+// gives its occurrence back.
+func (r *TaskRepository) CompleteTask(c context.Context, task *models.Task, userID int, dueDate *time.Time, completedDate *time.Time, missed ...time.Time) error {
+	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if task.MaxOccurrences > 0 {
+			var used int64
+			if err := tx.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&used).Error; err != nil {
+				return err
+			}
+
+			remaining := task.MaxOccurrences - int(used) - 1
+			if remaining <= len(missed) {
+				missed = missed[:max(remaining, 0)]
+				dueDate = nil
+			}
+		}
+
+		recordedAt := time.Now().UTC()
+		ch := &models.TaskHistory{
+			TaskID:        task.ID,
+			CompletedDate: completedDate,
+			DueDate:       task.NextDueDate,
+			RecordedAt:    recordedAt,
+		}
+		if task.HabitTarget > 0 {
+			ch.Progress = task.HabitProgress
+			ch.Target = task.HabitTarget
+		}
+		if err := tx.Create(ch).Error; err != nil {
+			return err
+		}
+
+		for _, missedDate := range missed {
+			skipped := &models.TaskHistory{
+				TaskID:     task.ID,
+				DueDate:    &missedDate,
+				Target:     task.HabitTarget,
+				RecordedAt: recordedAt,
+			}
+			if err := tx.Create(skipped).Error; err != nil {
+				return err
+			}
+		}
+
+		return moveOn(tx, task.ID, dueDate)
+	})
+
+	return err
+}
+
+// BackfillTask records several past completions of a task at once, as
+// scheduled by ScheduleBackfill, and moves the task on as in CompleteTask.
+func (r *TaskRepository) BackfillTask(c context.Context, taskID int, backfill Backfill) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		for _, roll := range backfill.Rolls {
+			if err := saveOverride(tx, roll); err != nil {
+				return err
+			}
+		}
+
+		recordedAt := time.Now().UTC()
+		for _, entry := range backfill.History {
+			entry.RecordedAt = recordedAt
+		}
+		if err := tx.Create(backfill.History).Error; err != nil {
+			return err
+		}
+
+		return moveOn(tx, taskID, backfill.NextDueDate)
+	})
+}
+
+// moveOn moves a task whose occurrence was just recorded on to dueDate, with
+// its checklist unticked and habit progress reset. A nil dueDate archives it.
+func moveOn(tx *gorm.DB, taskID int, dueDate *time.Time) error {
+	updates := map[string]interface{}{}
+	updates["next_due_date"] = dueDate
+	updates["habit_progress"] = 0
+
+	if dueDate == nil {
+		updates["is_active"] = false
+		updates["archived_at"] = time.Now().UTC()
+	} else if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", taskID).Update("is_done", false).Error; err != nil {
+		return err
+	}
+
+	updates["version"] = gorm.Expr("version + 1")
+	return tx.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error
+}
+
+// AddHabitProgress counts one completion toward the current period of a habit
+// task and stores the new count in task.HabitProgress. The period itself is
+// only recorded by CompleteTask, once its target is met or it has ended.
+func (r *TaskRepository) AddHabitProgress(c context.Context, task *models.Task) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).
+			Where("id = ?", task.ID).
+			Update("habit_progress", gorm.Expr("habit_progress + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Task{}).
+			Where("id = ?", task.ID).
+			Select("habit_progress").
+			Scan(&task.HabitProgress).Error
+	})
+}
+
+// GetLapsedHabitTasks returns the active habit tasks whose current period
+// ended at or before the given time, loaded like GetTask.
+func (r *TaskRepository) GetLapsedHabitTasks(c context.Context, before time.Time) ([]*models.Task, error) {
+	var tasks []*models.Task
+	if err := r.db.WithContext(c).
+		Where("is_active = 1 AND habit_target > 0 AND next_due_date <= ?", before).
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Order("original_date ASC")
+		}).
+		Preload("HolidayCalendar.Holidays").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ErrActivityNotLatest indicates a revert was attempted on a history entry
+// that is not followed by exactly the expected number of later entries, such
+// as one that is no longer the most recent action for the task.
+var ErrActivityNotLatest = errors.New("history entry is not the latest action for the task")
+
+// RevertActivity undoes a history entry of the task together with every entry
+// recorded after it, putting the task back as it was before the entry. steps
+// is the number of entries the caller expects to undo, the entry included;
+// if more were recorded since, nothing is undone and ErrActivityNotLatest is
+// returned.
+func (r *TaskRepository) RevertActivity(c context.Context, taskID, historyID, steps int) error {
+	if steps < 1 {
+		steps = 1
+	}
+
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// A non-positive historyID means "revert the latest steps actions",
+		// letting callers undo without first resolving the history entry
+		// themselves.
+		q := tx.Where("task_id = ?", taskID)
+		if historyID > 0 {
+			q = q.Where("id = ?", historyID)
+		} else {
+			q = q.Offset(steps - 1)
+		}
+
+		var entry models.TaskHistory
+		if err := q.Order("id DESC").First(&entry).Error; err != nil {
+			return err
+		}
+
+		var undone int64
+		if err := tx.Model(&models.TaskHistory{}).
+			Where("task_id = ? AND id >= ?", taskID, entry.ID).
+			Count(&undone).Error; err != nil {
+			return err
+		}
+		if undone != int64(steps) {
+			return ErrActivityNotLatest
+		}
+
+		if err := tx.Where("task_id = ? AND id >= ?", taskID, entry.ID).Delete(&models.TaskHistory{}).Error; err != nil {
+			return err
+		}
+
+		// Reopen a habit period with the progress it had, less the
+		// completion that met its target.
+		progress := entry.Progress
+		if entry.CompletedDate != nil && progress > 0 {
+			progress--
+		}
+
+		updates := map[string]interface{}{
+			"next_due_date":  entry.DueDate,
+			"is_active":      true,
+			"archived_at":    nil,
+			"habit_progress": progress,
+		}
+
+		updates["version"] = gorm.Expr("version + 1")
+		return tx.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error
+	})
+}
+
+// UpdateActivity corrects the completion date of a history entry of the task.
+// If the entry is the task's latest, the task moves on to nextDueDate, as
+// worked out by ScheduleFromHistory from the corrected history; older entries
+// have no bearing on the schedule and leave the task alone. It returns
+// gorm.ErrRecordNotFound if the task has no such entry.
+func (r *TaskRepository) UpdateActivity(c context.Context, taskID, historyID int, completedDate time.Time, nextDueDate *time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TaskHistory{}).
+			Where("id = ? AND task_id = ?", historyID, taskID).
+			Update("completed_date", completedDate)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var latestID int
+		if err := tx.Model(&models.TaskHistory{}).
+			Where("task_id = ?", taskID).
+			Select("MAX(id)").
+			Scan(&latestID).Error; err != nil {
+			return err
+		}
+		if latestID != historyID {
+			return nil
+		}
+
+		return rescheduleFromHistory(tx, taskID, nextDueDate)
+	})
+}
+
+// DeleteActivity deletes a history entry of the task other than its latest,
+// which is undone with RevertActivity instead. The schedule follows from the
+// latest entry, so the task is left alone. It returns gorm.ErrRecordNotFound
+// if the task has no such entry.
+func (r *TaskRepository) DeleteActivity(c context.Context, taskID, historyID int) error {
+	result := r.db.WithContext(c).Where("id = ? AND task_id = ?", historyID, taskID).Delete(&models.TaskHistory{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// rescheduleFromHistory moves an active task on to nextDueDate after its
+// latest history entry was corrected, archiving it if it is left without one.
+// Archived tasks stay archived; bringing one back is up to RestoreTask.
+func rescheduleFromHistory(tx *gorm.DB, taskID int, nextDueDate *time.Time) error {
+	updates := map[string]interface{}{
+		"next_due_date": nextDueDate,
+	}
+	if nextDueDate == nil {
+		updates["is_active"] = false
+		updates["archived_at"] = time.Now().UTC()
+	}
+	updates["version"] = gorm.Expr("version + 1")
+	return tx.Model(&models.Task{}).Where("id = ? AND is_active = 1", taskID).Updates(updates).Error
+}
+
+// RestoreTask makes an archived task active again, due at dueDate, with its
+// checklist unticked.
+func (r *TaskRepository) RestoreTask(c context.Context, taskID int, dueDate time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Task{}).
+			Where("id = ? AND is_active = 0", taskID).
+			Updates(map[string]interface{}{
+				"next_due_date":  dueDate,
+				"is_active":      true,
+				"archived_at":    nil,
+				"habit_progress": 0,
+				"version":        gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.ChecklistItem{}).Where("task_id = ?", taskID).Update("is_done", false).Error
+	})
+}
+
+// purgeBatchSize bounds how many tasks a purge deletes in one transaction.
+const purgeBatchSize = 500
+
+// PurgeArchivedTasks deletes the tasks archived before the given time and
+// returns how many it deleted.
+func (r *TaskRepository) PurgeArchivedTasks(c context.Context, before time.Time) (int, error) {
+	return r.purgeTasks(c, "is_active = 0 AND archived_at < ?", before)
+}
+
+// purgeTasks permanently deletes the tasks matching the condition, in
+// batches, and returns how many it deleted.
+func (r *TaskRepository) purgeTasks(c context.Context, condition string, args ...interface{}) (int, error) {
+	purged := 0
+	for {
+		var ids []int
+		err := r.db.WithContext(c).Unscoped().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Task{}).
+				Where(condition, args...).
+				Order("id ASC").
+				Limit(purgeBatchSize).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+
+			if err := tx.Where("id IN ?", ids).Delete(&models.Task{}).Error; err != nil {
+				return err
+			}
+			return r.search.Sync(tx, ids...)
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		purged += len(ids)
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+func (r *TaskRepository) GetTaskHistory(c context.Context, taskID int) ([]*models.TaskHistory, error) {
+	var histories []*models.TaskHistory
+	if err := r.db.WithContext(c).Where("task_id = ?", taskID).Order("due_date desc").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+func (r *TaskRepository) GetOccurrenceOverrides(c context.Context, taskID int) ([]*models.OccurrenceOverride, error) {
+	var overrides []*models.OccurrenceOverride
+	if err := r.db.WithContext(c).Where("task_id = ?", taskID).Order("original_date ASC").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// SaveOccurrenceOverride stores override, replacing any existing override of
+// the same occurrence, and moves the task to dueDate if that changed. A nil
+// dueDate ends the series, as in CompleteTask.
+func (r *TaskRepository) SaveOccurrenceOverride(c context.Context, task *models.Task, override *models.OccurrenceOverride, dueDate *time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := saveOverride(tx, override); err != nil {
+			return err
+		}
+		return updateDueDate(tx, task, dueDate)
+	})
+}
+
+// saveOverride stores override, replacing any existing override of the same
+// occurrence.
+func saveOverride(tx *gorm.DB, override *models.OccurrenceOverride) error {
+	var existing models.OccurrenceOverride
+	err := tx.Where("task_id = ? AND original_date = ?", override.TaskID, override.OriginalDate).First(&existing).Error
+	switch {
+	case err == nil:
+		override.ID = existing.ID
+		return tx.Model(&existing).Update("new_date", override.NewDate).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return tx.Create(override).Error
+	default:
+		return err
+	}
+}
+
+// DeleteOccurrenceOverride removes an override and moves the task to dueDate
+// if that changed.
+func (r *TaskRepository) DeleteOccurrenceOverride(c context.Context, task *models.Task, overrideID int, dueDate *time.Time) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND task_id = ?", overrideID, task.ID).Delete(&models.OccurrenceOverride{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return updateDueDate(tx, task, dueDate)
+	})
+}
+
+func updateDueDate(tx *gorm.DB, task *models.Task, dueDate *time.Time) error {
+	if task.NextDueDate == nil && dueDate == nil {
+		return nil
+	}
+	if task.NextDueDate != nil && dueDate != nil && task.NextDueDate.Equal(*dueDate) {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"next_due_date": dueDate,
+	}
+	if dueDate == nil {
+		updates["is_active"] = false
+		updates["archived_at"] = time.Now().UTC()
+	}
+
+	updates["version"] = gorm.Expr("version + 1")
+	return tx.Model(&models.Task{}).Where("id = ?", task.ID).Updates(updates).Error
+}
+
+func checklistOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+func (r *TaskRepository) GetChecklist(c context.Context, taskID int) ([]*models.ChecklistItem, error) {
+	var items []*models.ChecklistItem
+	if err := checklistOrder(r.db.WithContext(c)).Where("task_id = ?", taskID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddChecklistItem inserts item into the task's checklist at position, or at
+// the end if position is nil, and renumbers the items after it.
+func (r *TaskRepository) AddChecklistItem(c context.Context, taskID int, item *models.ChecklistItem, position *int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		ids, err := checklistIDs(tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		item.TaskID = taskID
+		item.Position = len(ids)
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+
+		return reorderChecklist(tx, insertAt(ids, item.ID, position))
+	})
+}
+
+// UpdateChecklistItem applies the changes set in req to one of the task's
+// checklist items. It returns gorm.ErrRecordNotFound if the item belongs to
+// another task.
+func (r *TaskRepository) UpdateChecklistItem(c context.Context, taskID, itemID int, req models.UpdateChecklistItemReq) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var item models.ChecklistItem
+		if err := tx.Where("id = ? AND task_id = ?", itemID, taskID).First(&item).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Title != nil {
+			updates["title"] = *req.Title
+		}
+		if req.IsDone != nil {
+			updates["is_done"] = *req.IsDone
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&item).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if req.Position == nil {
+			return nil
+		}
+
+		ids, err := checklistIDs(tx, taskID)
+		if err != nil {
+			return err
+		}
+		ids = slices.DeleteFunc(ids, func(id int) bool { return id == itemID })
+		return reorderChecklist(tx, insertAt(ids, itemID, req.Position))
+	})
+}
+
+// DeleteChecklistItem removes one of the task's checklist items and closes
+// the gap it leaves. It returns gorm.ErrRecordNotFound if the item belongs to
+// another task.
+func (r *TaskRepository) DeleteChecklistItem(c context.Context, taskID, itemID int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND task_id = ?", itemID, taskID).Delete(&models.ChecklistItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		ids, err := checklistIDs(tx, taskID)
+		if err != nil {
+			return err
+		}
+		return reorderChecklist(tx, ids)
+	})
+}
+
+func checklistIDs(tx *gorm.DB, taskID int) ([]int, error) {
+	var ids []int
+	if err := checklistOrder(tx.Model(&models.ChecklistItem{})).
+		Where("task_id = ?", taskID).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// insertAt inserts id into ids at position, clamped to the bounds of ids. A
+// nil position appends it.
+func insertAt(ids []int, id int, position *int) []int {
+	at := len(ids)
+	if position != nil {
+		at = min(max(*position, 0), len(ids))
+	}
+	return slices.Insert(ids, at, id)
+}
+
+// reorderChecklist numbers the given checklist items by their order in ids.
+func reorderChecklist(tx *gorm.DB, ids []int) error {
+	for position, id := range ids {
+		if err := tx.Model(&models.ChecklistItem{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrDependencyCycle indicates a dependency that would make a task wait for
+// itself, directly or through other tasks.
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// markBlocked sets Blocked on the tasks that have at least one open blocker,
+// that is a blocker that is still active and not in the trash.
+func markBlocked(db *gorm.DB, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var blocked []int
+	if err := db.Table("task_dependencies AS d").
+		Joins("JOIN tasks b ON b.id = d.blocker_id").
+		Where("d.task_id IN ? AND b.is_active = 1 AND b.deleted_at IS NULL", ids).
+		Distinct().
+		Pluck("d.task_id", &blocked).Error; err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		task.Blocked = slices.Contains(blocked, task.ID)
+	}
+	return nil
+}
+
+// GetDependencies returns the tasks blocking the given task and the tasks it
+// blocks in turn.
+func (r *TaskRepository) GetDependencies(c context.Context, taskID int) (blockers []*models.Task, dependents []*models.Task, err error) {
+	db := r.db.WithContext(c)
+
+	if err := db.Where("id IN (?)", db.Model(&models.TaskDependency{}).Select("blocker_id").Where("task_id = ?", taskID)).
+		Order("id ASC").
+		Find(&blockers).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err := db.Where("id IN (?)", db.Model(&models.TaskDependency{}).Select("task_id").Where("blocker_id = ?", taskID)).
+		Order("id ASC").
+		Find(&dependents).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err := markBlocked(db, append(slices.Clone(blockers), dependents...)...); err != nil {
+		return nil, nil, err
+	}
+
+	return blockers, dependents, nil
+}
+
+// AddDependency records that taskID is blocked by blockerID, both owned by
+// userID. It returns ErrDependencyCycle if blockerID already waits for taskID.
+// Adding an existing dependency is a no-op.
+func (r *TaskRepository) AddDependency(c context.Context, userID, taskID, blockerID int) error {
+	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if taskID == blockerID {
+			return ErrDependencyCycle
+		}
+
+		var edges []models.TaskDependency
+		if err := tx.Table("task_dependencies AS d").
+			Select("d.task_id, d.blocker_id").
+			Joins("JOIN tasks t ON t.id = d.task_id").
+			Where("t.created_by = ?", userID).
+			Scan(&edges).Error; err != nil {
+			return err
+		}
+
+		blockersOf := make(map[int][]int)
+		for _, edge := range edges {
+			if edge.TaskID == taskID && edge.BlockerID == blockerID {
+				return nil
+			}
+			blockersOf[edge.TaskID] = append(blockersOf[edge.TaskID], edge.BlockerID)
+		}
+
+		// Walk everything the new blocker waits for; finding the task
+		// there means the new edge would close a loop.
+		visited := map[int]bool{blockerID: true}
+		queue := []int{blockerID}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range blockersOf[current] {
+				if next == taskID {
+					return ErrDependencyCycle
+				}
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		return tx.Create(&models.TaskDependency{TaskID: taskID, BlockerID: blockerID}).Error
+	})
 }
 
-func (r *TaskRepository) CreateTask(c context.Context, task *models.Task) (int, error) {
-	if err := r.db.WithContext(c).Create(task).Error; err != nil {
-		return 0, err
+// RemoveDependency deletes the dependency of taskID on blockerID, returning
+// gorm.ErrRecordNotFound if there is none.
+func (r *TaskRepository) RemoveDependency(c context.Context, taskID, blockerID int) error {
+	result := r.db.WithContext(c).
+		Where("task_id = ? AND blocker_id = ?", taskID, blockerID).
+		Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		return result.Error
 	}
-	return task.ID, nil
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
-func (r *TaskRepository) GetTask(c context.Context, taskID int) (*models.Task, error) {
-	var task models.Task
-	if err := r.db.WithContext(c).
-		Model(&models.Task{}).
-		Preload("Labels").
-		First(&task, taskID).Error; err != nil {
-		return nil, err
+// ValidateActiveWindow reports whether an active window received from a client
+// is either a month range or a date range.
+func ValidateActiveWindow(window models.ActiveWindow) error {
+	hasMonths := window.StartMonth != 0 || window.EndMonth != 0
+	hasDates := window.StartDate != nil || window.EndDate != nil
+
+	if hasMonths && hasDates {
+		return errors.New("active window must be either a month range or a date range")
 	}
-	return &task, nil
-}
 
-func (r *TaskRepository) GetTasks(c context.Context, userID int) ([]*models.Task, error) {
-	var tasks []*models.Task
+	if hasMonths {
+		if window.StartMonth < 1 || window.StartMonth > 12 || window.EndMonth < 1 || window.EndMonth > 12 {
+			return errors.New("active window months must be between 1 and 12")
+		}
+	}
 
-	if err := r.db.WithContext(c).
-		Where("created_by = ? AND is_active = 1", userID).
-		Order("next_due_date ASC").
-		Preload("Labels").
-		Find(&tasks).Error; err != nil {
-		return nil, err
+	if window.StartDate != nil && window.EndDate != nil && window.EndDate.Before(*window.StartDate) {
+		return errors.New("active window cannot end before it starts")
 	}
 
-	return tasks, nil
+	return nil
 }
 
-func (r *TaskRepository) GetTasksDueBefore(c context.Context, userID int, before time.Time) ([]*models.Task, error) {
-	var tasks []*models.Task
-
-	if err := r.db.WithContext(c).
-		Where("created_by = ? AND is_active = 1 AND next_due_date < ?", userID, before).
-		Order("next_due_date ASC").
-		Preload("Labels").
-		Find(&tasks).Error; err != nil {
-		return nil, err
+// ValidateCatchUpPolicy reports whether a catch-up policy received from a
+// client is known. An empty policy selects CatchUpNextOccurrence.
+func ValidateCatchUpPolicy(policy models.CatchUpPolicy) error {
+	switch policy {
+	case "", models.CatchUpNextOccurrence, models.CatchUpAfterNow, models.CatchUpSkipMissed:
+		return nil
+	default:
+		return fmt.Errorf("unknown catch-up policy %q", policy)
 	}
+}
 
-	return tasks, nil
+// ValidatePriority reports whether a priority received from a client is one
+// of the known levels.
+func ValidatePriority(priority models.Priority) error {
+	if priority < models.PriorityNone || priority > models.PriorityUrgent {
+		return fmt.Errorf("priority must be between %d and %d", models.PriorityNone, models.PriorityUrgent)
+	}
+	return nil
 }
 
-func (r *TaskRepository) GetTasksByLabel(c context.Context, userID int, labelID int) ([]*models.Task, error) {
-	var tasks []*models.Task
+// ParseTaskSort parses a comma separated list of sort keys, each optionally
+// followed by ":asc" or ":desc", such as "priority:desc,due". Keys may not
+// repeat. An empty string yields no sorts.
+func ParseTaskSort(raw string) ([]models.TaskSort, error) {
+	var sorts []models.TaskSort
+	if strings.TrimSpace(raw) == "" {
+		return sorts, nil
+	}
 
-	if err := r.db.WithContext(c).
-		Where("created_by = ? AND is_active = 1", userID).
-		Joins("JOIN task_labels ON task_labels.task_id = tasks.id AND task_labels.label_id = ?", labelID).
-		Order("next_due_date ASC").
-		Preload("Labels").
-		Find(&tasks).Error; err != nil {
-		return nil, err
+	seen := make(map[models.TaskSortKey]bool)
+	for _, part := range strings.Split(raw, ",") {
+		key, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		sort := models.TaskSort{Key: models.TaskSortKey(strings.ToLower(key))}
+		if _, ok := sortColumns[sort.Key]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q", key)
+		}
+		if seen[sort.Key] {
+			return nil, fmt.Errorf("sort key %q given more than once", key)
+		}
+		seen[sort.Key] = true
+
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			sort.Descending = true
+		default:
+			return nil, fmt.Errorf("unknown sort direction %q", direction)
+		}
+		sorts = append(sorts, sort)
 	}
+	return sorts, nil
+}
 
-	return tasks, nil
+// ValidateBusinessDays reports whether a business-day setting received from a
+// client is known. An empty setting lets due dates fall on any day.
+func ValidateBusinessDays(roll models.BusinessDayRoll) error {
+	switch roll {
+	case "", models.BusinessDaysForward, models.BusinessDaysBackward:
+		return nil
+	default:
+		return fmt.Errorf("unknown business day roll %q", roll)
+	}
 }
 
-func (r *TaskRepository) SearchTasksByTitle(c context.Context, userID int, query string) ([]*models.Task, error) {
-	var tasks []*models.Task
+// ValidateFrequency reports whether a frequency received from a client can be
+// scheduled by ScheduleNextDueDate.
+func ValidateFrequency(freq models.Frequency) error {
+	switch freq.Type {
+	case models.RepeatOnce, models.RepeatDaily, models.RepeatWeekly, models.RepeatMonthly, models.RepeatYearly:
+		return nil
+	case models.RepeatCustom:
+		return validateCustomFrequency(freq)
+	case models.RepeatRRule:
+		if _, err := rrule.Parse(freq.RRule); err != nil {
+			return fmt.Errorf("invalid recurrence rule: %s", err.Error())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown frequency type %q", freq.Type)
+	}
+}
 
-	// Escape LIKE wildcards so they match literally. Use '!' as the escape
-	// character because backslash has dialect-specific meaning inside MySQL
-	// string literals by default and would produce a SQL syntax error.
-	escaped := strings.ReplaceAll(query, "!", "!!")
-	escaped = strings.ReplaceAll(escaped, "%", "!%")
-	escaped = strings.ReplaceAll(escaped, "_", "!_")
-	pattern := "%" + strings.ToLower(escaped) + "%"
+func validateCustomFrequency(freq models.Frequency) error {
+	if freq.Every < 0 {
+		return errors.New("every cannot be negative")
+	}
 
-	if err := r.db.WithContext(c).
-		Where("created_by = ? AND is_active = 1 AND LOWER(title) LIKE ? ESCAPE '!'", userID, pattern).
-		Order("next_due_date ASC").
-		Preload("Labels").
-		Find(&tasks).Error; err != nil {
-		return nil, err
+	switch freq.On {
+	case models.Interval, models.DaysOfTheWeek, models.DayOfTheMonths:
+		return nil
+	case models.DayOfTheMonth:
+		if freq.DayOfMonth < 1 || freq.DayOfMonth > 31 {
+			return errors.New("day of month must be between 1 and 31")
+		}
+		return nil
+	case models.WeekdayOfTheMonth:
+		if freq.WeekOfMonth != models.LastWeekOfMonth && (freq.WeekOfMonth < 1 || freq.WeekOfMonth > 5) {
+			return errors.New("week of month must be between 1 and 5, or -1 for the last week")
+		}
+		if len(freq.Days) == 0 {
+			return errors.New("days of the week cannot be empty")
+		}
+		for _, day := range freq.Days {
+			if day < 0 || day > 6 {
+				return errors.New("days of the week must be between 0 and 6")
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown repeat mode %q", freq.On)
+	}
+}
+
+// monthlyRule expresses the day_of_the_month and weekday_of_month modes as a
+// monthly recurrence rule repeating every freq.Every months.
+func monthlyRule(freq models.Frequency) *rrule.Rule {
+	rule := &rrule.Rule{
+		Freq:     rrule.Monthly,
+		Interval: max(freq.Every, 1),
 	}
 
-	return tasks, nil
+	if freq.On == models.DayOfTheMonth {
+		rule.ByMonthDay = []int{freq.DayOfMonth}
+		if freq.ClampToLastDay {
+			// Short months have no match for the requested day, leaving the
+			// last day of the month as the first candidate.
+			rule.ByMonthDay = append(rule.ByMonthDay, -1)
+			rule.BySetPos = []int{1}
+		}
+		return rule
+	}
+
+	for _, day := range freq.Days {
+		rule.ByDay = append(rule.ByDay, rrule.WeekdayNum{
+			Ordinal: freq.WeekOfMonth,
+			Weekday: time.Weekday(day),
+		})
+	}
+	return rule
 }
 
-func (r *TaskRepository) GetRecentActivity(c context.Context, userID, beforeID, limit int) ([]*models.ActivityEntry, error) {
-	var entries []*models.ActivityEntry
+// addMonths moves t by the given number of calendar months, clamping the day
+// to the end of the target month instead of overflowing into the next one
+// (so January 31st plus one month is February 28th or 29th).
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
 
-	q := r.db.WithContext(c).
-		Table("task_histories AS th").
-		Select(`th.id AS id, th.task_id AS task_id, t.title AS task_title,
-			th.completed_date AS completed_date, th.due_date AS due_date,
-			CASE WHEN th.id = (SELECT MAX(th2.id) FROM task_histories th2 WHERE th2.task_id = th.task_id) THEN 1 ELSE 0 END AS is_latest`).
-		Joins("JOIN tasks t ON t.id = th.task_id").
-		Where("t.created_by = ?", userID)
+	lastDay := time.Date(y, m+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+
+	return time.Date(y, m+time.Month(months), d, hh, mm, ss, t.Nanosecond(), t.Location())
+}
 
-	if beforeID > 0 {
-		q = q.Where("th.id < ?", beforeID)
+// skipClockGap handles due dates whose wall-clock time does not exist because
+// clocks sprang forward on that day. time.Date resolves such times backwards
+// (02:30 becomes 01:30); like calendar apps, move them past the gap instead
+// (02:30 becomes 03:30).
+func skipClockGap(t time.Time, wallClock time.Time) time.Time {
+	if t.Hour() == wallClock.Hour() && t.Minute() == wallClock.Minute() {
+		return t
 	}
 
-	if err := q.Order("th.id DESC").Limit(limit).Scan(&entries).Error; err != nil {
-		return nil, err
+	_, before := t.Zone()
+	_, after := t.Add(12 * time.Hour).Zone()
+	if after <= before {
+		return t
 	}
 
-	return entries, nil
+	return t.Add(time.Duration(after-before) * time.Second)
 }
 
-func (r *TaskRepository) DeleteTask(c context.Context, id int) error {
-	return r.db.WithContext(c).Where("id = ?", id).Delete(&models.Task{}).Error
+// maxSkippedOccurrences bounds how many consecutive occurrences
+// ScheduleNextDueDate steps over, because they were cancelled or fall outside
+// the active window, before giving up on finding the next one.
+const maxSkippedOccurrences = 10000
+
+// Schedule is the outcome of scheduling a task's next occurrence.
+type Schedule struct {
+	NextDueDate *time.Time
+	// RolledFrom is the series date NextDueDate was rolled off because it
+	// fell on a weekend or holiday. The roll has to be kept as an override
+	// of that occurrence, see RollOverride.
+	RolledFrom *time.Time
+	// Missed lists the passed occurrences that the catch-up policy records
+	// as skipped.
+	Missed []time.Time
 }
 
-func (r *TaskRepository) IsTaskOwner(c context.Context, taskID int, userID int) error {
-	var task models.Task
-	return r.db.WithContext(c).Model(&models.Task{}).Where("id = ? AND created_by = ?", taskID, userID).First(&task).Error
+// RollOverride returns the override that keeps a business-day roll in place,
+// or nil if the next due date was not rolled. Without it the series would
+// continue from the rolled date and drift by a day or two every time.
+func (s Schedule) RollOverride(taskID int) *models.OccurrenceOverride {
+	if s.RolledFrom == nil {
+		return nil
+	}
+	return &models.OccurrenceOverride{
+		TaskID:       taskID,
+		OriginalDate: *s.RolledFrom,
+		NewDate:      s.NextDueDate,
+	}
 }
 
-func (r *TaskRepository) CompleteTask(c context.Context, task *models.Task, userID int, dueDate *time.Time, completedDate *time.Time) error {
-	err := r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
-		ch := &models.TaskHistory{
-			TaskID:        task.ID,
-			CompletedDate: completedDate,
-			DueDate:       task.NextDueDate,
-		}
-		if err := tx.Create(ch).Error; err != nil {
-			return err
+// ScheduleNextDueDate computes the due date following the current one. The
+// arithmetic is done in loc so that the wall-clock time of day survives
+// daylight saving transitions; the result is returned in UTC. A nil loc means
+// UTC.
+//
+// The task's occurrence overrides are applied on top of the series: cancelled
+// occurrences are stepped over and moved ones are returned at their new date.
+// A due date that was moved is scheduled from its original date, so moving
+// one occurrence never shifts the rest of the series. Occurrences outside the
+// task's active window are stepped over too, and the series ends once its
+// date range has passed. Tasks restricted to business days are rolled to the
+// nearest working day, see RollToBusinessDay.
+func ScheduleNextDueDate(task *models.Task, completedDate time.Time, loc *time.Location) (*time.Time, error) {
+	schedule, err := scheduleOccurrence(task, completedDate, loc)
+	return schedule.NextDueDate, err
+}
+
+func scheduleOccurrence(task *models.Task, completedDate time.Time, loc *time.Location) (Schedule, error) {
+	if len(task.Overrides) == 0 && task.ActiveWindow == (models.ActiveWindow{}) && task.BusinessDays == "" {
+		next, err := scheduleSeriesDate(task, completedDate, loc)
+		return Schedule{NextDueDate: next}, err
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	series := *task
+	if !task.IsRolling {
+		series.NextDueDate = SeriesDate(task)
+	}
+
+	// A roll must not land on or before the occurrence being completed;
+	// backward rolls of daily tasks would otherwise pile weekends onto
+	// Friday.
+	previous := task.NextDueDate
+	if task.IsRolling {
+		previous = &completedDate
+	}
+
+	window := task.ActiveWindow
+	for i := 0; i < maxSkippedOccurrences; i++ {
+		next, err := scheduleSeriesDate(&series, completedDate, loc)
+		if err != nil || next == nil {
+			return Schedule{NextDueDate: next}, err
 		}
-		updates := map[string]interface{}{}
-		updates["next_due_date"] = dueDate
 
-		if dueDate == nil {
-			updates["is_active"] = false
+		if window.EndDate != nil && next.After(*window.EndDate) {
+			return Schedule{}, nil
 		}
 
-		if err := tx.Model(&models.Task{}).Where("id = ?", task.ID).Updates(updates).Error; err != nil {
-			return err
+		if inActiveWindow(window, *next, loc) {
+			override := findOverride(task.Overrides, *next)
+			if override != nil && override.NewDate != nil {
+				moved := override.NewDate.UTC()
+				return Schedule{NextDueDate: &moved}, nil
+			}
+			if override == nil {
+				rolled := RollToBusinessDay(task, *next, loc)
+				if previous == nil || rolled.After(*previous) {
+					if rolled.Equal(*next) || task.IsRolling {
+						return Schedule{NextDueDate: &rolled}, nil
+					}
+					return Schedule{NextDueDate: &rolled, RolledFrom: next}, nil
+				}
+			}
 		}
 
-		return nil
-	})
+		// Continue the series from the skipped occurrence.
+		series.NextDueDate = next
+		series.IsRolling = false
+	}
 
-	return err
+	return Schedule{}, errors.New("no upcoming occurrence found")
 }
 
-// ErrActivityNotLatest indicates a revert was attempted on a history entry that is
-// no longer the most recent action for the task.
-var ErrActivityNotLatest = errors.New("history entry is not the latest action for the task")
+// maxBusinessDayRoll bounds how far RollToBusinessDay looks for a working day,
+// in case a calendar marks a whole year as holidays.
+const maxBusinessDayRoll = 366
 
-func (r *TaskRepository) RevertActivity(c context.Context, taskID int, historyID int) error {
-	return r.db.WithContext(c).Transaction(func(tx *gorm.DB) error {
-		var latestID *int
-		if err := tx.Model(&models.TaskHistory{}).
-			Where("task_id = ?", taskID).
-			Select("MAX(id)").
-			Scan(&latestID).Error; err != nil {
-			return err
-		}
+// RollToBusinessDay moves date off weekends and the holidays in the task's
+// calendar, in the direction given by the task's BusinessDays setting. Days
+// are counted in loc and the wall-clock time of day is kept. Dates of tasks
+// without the setting, or with no working day in reach, are returned as is.
+func RollToBusinessDay(task *models.Task, date time.Time, loc *time.Location) time.Time {
+	var step int
+	switch task.BusinessDays {
+	case models.BusinessDaysForward:
+		step = 1
+	case models.BusinessDaysBackward:
+		step = -1
+	default:
+		return date
+	}
 
-		if latestID == nil {
-			return gorm.ErrRecordNotFound
-		}
+	if loc == nil {
+		loc = time.UTC
+	}
 
-		// A non-positive historyID means "revert the latest action", letting
-		// callers undo without first resolving the history entry themselves.
-		if historyID <= 0 {
-			historyID = *latestID
+	holidays := make(map[string]bool)
+	if task.HolidayCalendar != nil {
+		for _, holiday := range task.HolidayCalendar.Holidays {
+			holidays[holiday.Date] = true
 		}
+	}
 
-		if historyID != *latestID {
-			return ErrActivityNotLatest
+	wallClock := date.In(loc)
+	for i := 0; i <= maxBusinessDayRoll; i++ {
+		day := wallClock.AddDate(0, 0, i*step)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday || holidays[day.Format(ics.DateLayout)] {
+			continue
 		}
-
-		var entry models.TaskHistory
-		if err := tx.Where("id = ? AND task_id = ?", historyID, taskID).First(&entry).Error; err != nil {
-			return err
+		if i == 0 {
+			return date
 		}
+		return skipClockGap(day, wallClock).UTC()
+	}
 
-		if err := tx.Delete(&entry).Error; err != nil {
-			return err
+	return date
+}
+
+// IsDormant reports whether the task's active window excludes at, so that the
+// task is waiting for its season to start.
+func IsDormant(task *models.Task, at time.Time, loc *time.Location) bool {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return !inActiveWindow(task.ActiveWindow, at, loc)
+}
+
+func inActiveWindow(window models.ActiveWindow, t time.Time, loc *time.Location) bool {
+	if window.StartDate != nil && t.Before(*window.StartDate) {
+		return false
+	}
+	if window.EndDate != nil && t.After(*window.EndDate) {
+		return false
+	}
+
+	if window.StartMonth == 0 || window.EndMonth == 0 {
+		return true
+	}
+
+	month := int(t.In(loc).Month())
+	if window.StartMonth <= window.EndMonth {
+		return month >= window.StartMonth && month <= window.EndMonth
+	}
+	// The window wraps around the new year, e.g. November to February.
+	return month >= window.StartMonth || month <= window.EndMonth
+}
+
+// SeriesDate returns the date the task's current occurrence has in its series,
+// which differs from NextDueDate when the occurrence was moved by an override.
+func SeriesDate(task *models.Task) *time.Time {
+	if task.NextDueDate == nil {
+		return nil
+	}
+
+	for _, override := range task.Overrides {
+		if override.NewDate != nil && override.NewDate.Equal(*task.NextDueDate) {
+			original := override.OriginalDate.UTC()
+			return &original
 		}
+	}
 
-		updates := map[string]interface{}{
-			"next_due_date": entry.DueDate,
-			"is_active":     true,
+	return task.NextDueDate
+}
+
+// DueDateWithOverride returns the task's schedule once override is in place.
+// Only an override of the current occurrence changes it: moving it returns the
+// new date, and cancelling it advances the task to the following occurrence.
+func DueDateWithOverride(task *models.Task, override models.OccurrenceOverride, loc *time.Location) (Schedule, error) {
+	series := SeriesDate(task)
+	if series == nil || !series.Equal(override.OriginalDate) {
+		return Schedule{NextDueDate: task.NextDueDate}, nil
+	}
+
+	if override.NewDate != nil {
+		moved := override.NewDate.UTC()
+		return Schedule{NextDueDate: &moved}, nil
+	}
+
+	pending := *task
+	pending.NextDueDate = series
+	pending.Overrides = []models.OccurrenceOverride{override}
+	for _, existing := range task.Overrides {
+		if !existing.OriginalDate.Equal(override.OriginalDate) {
+			pending.Overrides = append(pending.Overrides, existing)
 		}
+	}
 
-		return tx.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error
-	})
+	return scheduleOccurrence(&pending, *series, loc)
 }
 
-func (r *TaskRepository) GetTaskHistory(c context.Context, taskID int) ([]*models.TaskHistory, error) {
-	var histories []*models.TaskHistory
-	if err := r.db.WithContext(c).Where("task_id = ?", taskID).Order("due_date desc").Find(&histories).Error; err != nil {
-		return nil, err
+// DueDateWithoutOverride returns the task's due date once override is removed.
+// Removing the override that moved the current occurrence puts it back on its
+// original date; other overrides leave the due date alone.
+func DueDateWithoutOverride(task *models.Task, override models.OccurrenceOverride) *time.Time {
+	if override.NewDate != nil && task.NextDueDate != nil && override.NewDate.Equal(*task.NextDueDate) {
+		original := override.OriginalDate.UTC()
+		return &original
 	}
-	return histories, nil
+	return task.NextDueDate
+}
+
+func findOverride(overrides []models.OccurrenceOverride, date time.Time) *models.OccurrenceOverride {
+	for i := range overrides {
+		if overrides[i].OriginalDate.Equal(date) {
+			return &overrides[i]
+		}
+	}
+	return nil
 }
 
-func ScheduleNextDueDate(task *models.Task, completedDate time.Time) (*time.Time, error) {
+func scheduleSeriesDate(task *models.Task, completedDate time.Time, loc *time.Location) (*time.Time, error) {
 	var freq = task.Frequency
 	if freq.Type == "once" {
 		return nil, nil
@@ -238,15 +1820,21 @@ func ScheduleNextDueDate(task *models.Task, completedDate time.Time) (*time.Time
 		return nil, errors.New("unable to calculate next due date")
 	}
 
+	if loc == nil {
+		loc = time.UTC
+	}
+	baseDate = baseDate.In(loc)
+	wallClock := baseDate
+
 	var nextDueDate time.Time
 	if freq.Type == "daily" {
 		nextDueDate = baseDate.AddDate(0, 0, 1)
 	} else if freq.Type == "weekly" {
 		nextDueDate = baseDate.AddDate(0, 0, 7)
 	} else if freq.Type == "monthly" {
-		nextDueDate = baseDate.AddDate(0, 1, 0)
+		nextDueDate = addMonths(baseDate, 1)
 	} else if freq.Type == "yearly" {
-		nextDueDate = baseDate.AddDate(1, 0, 0)
+		nextDueDate = addMonths(baseDate, 12)
 	} else if freq.Type == "custom" {
 		if freq.On == "interval" {
 			switch freq.Unit {
@@ -257,9 +1845,9 @@ func ScheduleNextDueDate(task *models.Task, completedDate time.Time) (*time.Time
 			case "weeks":
 				nextDueDate = baseDate.AddDate(0, 0, 7*freq.Every)
 			case "months":
-				nextDueDate = baseDate.AddDate(0, freq.Every, 0)
+				nextDueDate = addMonths(baseDate, freq.Every)
 			case "years":
-				nextDueDate = baseDate.AddDate(freq.Every, 0, 0)
+				nextDueDate = addMonths(baseDate, 12*freq.Every)
 			}
 		} else if freq.On == "days_of_the_week" {
 			currentWeekDay := int32(baseDate.Weekday())
@@ -294,21 +1882,269 @@ func ScheduleNextDueDate(task *models.Task, completedDate time.Time) (*time.Time
 			for _, month := range months {
 				if month > currentMonth {
 					duringThisYear = true
-					nextDueDate = baseDate.AddDate(0, int(month-currentMonth), 0)
+					nextDueDate = addMonths(baseDate, int(month-currentMonth))
 					break
 				}
 			}
 
 			if !duringThisYear {
 				monthsUntilNextYear := 12 - int(currentMonth)
-				nextDueDate = baseDate.AddDate(0, monthsUntilNextYear+int(months[0]), 0)
+				nextDueDate = addMonths(baseDate, monthsUntilNextYear+int(months[0]))
 			}
+		} else if freq.On == "day_of_the_month" || freq.On == "weekday_of_month" {
+			next, ok := monthlyRule(freq).Next(baseDate, baseDate)
+			if !ok {
+				return nil, errors.New("unable to calculate next due date")
+			}
+			nextDueDate = next
+		}
+	} else if freq.Type == "rrule" {
+		rule, err := rrule.ParseInLocation(freq.RRule, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rules without a DTSTART are anchored at the base date, so COUNT only
+		// has meaning for rules that carry their own series start.
+		seriesStart := baseDate
+		if rule.DTStart != nil {
+			seriesStart = rule.DTStart.In(loc)
+			wallClock = seriesStart
+		}
+
+		next, ok := rule.Next(seriesStart, baseDate)
+		if !ok {
+			return nil, nil
 		}
+		nextDueDate = next
 	}
 
+	if freq.Unit != "hours" {
+		nextDueDate = skipClockGap(nextDueDate, wallClock)
+	}
+
+	nextDueDate = nextDueDate.UTC()
 	if task.EndDate != nil && nextDueDate.After(*task.EndDate) {
 		return nil, nil
 	}
 
 	return &nextDueDate, nil
 }
+
+// maxMissedOccurrences bounds how many passed occurrences ScheduleCatchUp
+// walks over, so an hourly task left alone for years cannot stall a request.
+const maxMissedOccurrences = 1000
+
+// ScheduleCatchUp computes the next due date like ScheduleNextDueDate and then
+// applies the task's catch-up policy to any occurrences that are no later
+// than now, listing those that should be recorded as skipped in Missed.
+// Rolling tasks are scheduled from their completion and never need to catch
+// up.
+func ScheduleCatchUp(task *models.Task, completedDate, now time.Time, loc *time.Location) (Schedule, error) {
+	next, err := scheduleOccurrence(task, completedDate, loc)
+	if err != nil || next.NextDueDate == nil || task.IsRolling {
+		return next, err
+	}
+
+	if task.CatchUp != models.CatchUpAfterNow && task.CatchUp != models.CatchUpSkipMissed {
+		return next, nil
+	}
+
+	var missed []time.Time
+	pending := *task
+	pending.Overrides = slices.Clone(task.Overrides)
+	for i := 0; i < maxMissedOccurrences && !next.NextDueDate.After(now); i++ {
+		if task.CatchUp == models.CatchUpSkipMissed {
+			missed = append(missed, *next.NextDueDate)
+		}
+
+		// Passed occurrences are never stored, so keep their rolls in
+		// memory only to schedule the rest of the series from them.
+		if roll := next.RollOverride(task.ID); roll != nil {
+			pending.Overrides = append(pending.Overrides, *roll)
+		}
+
+		pending.NextDueDate = next.NextDueDate
+		following, err := scheduleOccurrence(&pending, *next.NextDueDate, loc)
+		if err != nil {
+			return Schedule{}, err
+		}
+		if following.NextDueDate == nil {
+			// The series ended while catching up.
+			return Schedule{Missed: missed}, nil
+		}
+		if !following.NextDueDate.After(*SeriesDate(&pending)) {
+			break
+		}
+		next = following
+	}
+
+	// Moved occurrences may be out of order with the rest of the series.
+	slices.SortFunc(missed, time.Time.Compare)
+	next.Missed = missed
+
+	return next, nil
+}
+
+// ScheduleFromHistory works out the next due date of a task from its history,
+// as if the latest entry had just been recorded: the series continues after
+// the occurrence that entry closed, and rolling tasks from its completion, or
+// its due date if it was skipped. The result is nil once the task has no
+// further occurrence or its MaxOccurrences are used up.
+func ScheduleFromHistory(task *models.Task, history []*models.TaskHistory, loc *time.Location) (*time.Time, error) {
+	if len(history) == 0 {
+		return nil, errors.New("task has no history")
+	}
+
+	latest := slices.MaxFunc(history, func(a, b *models.TaskHistory) int {
+		return a.ID - b.ID
+	})
+	if latest.DueDate == nil {
+		return nil, nil
+	}
+	if task.MaxOccurrences > 0 && len(history) >= task.MaxOccurrences {
+		return nil, nil
+	}
+
+	completedDate := *latest.DueDate
+	if latest.CompletedDate != nil {
+		completedDate = *latest.CompletedDate
+	}
+
+	// Catch up as of the completion, as CompleteTask did when the entry
+	// was recorded; the occurrences it skipped are in the history already.
+	occurrence := *task
+	occurrence.NextDueDate = latest.DueDate
+	schedule, err := ScheduleCatchUp(&occurrence, completedDate, completedDate, loc)
+	return schedule.NextDueDate, err
+}
+
+// ErrOccurrencesUsedUp indicates that a task has fewer occurrences left than
+// completions to record.
+var ErrOccurrencesUsedUp = errors.New("task has fewer occurrences left than completions")
+
+// ErrSeriesEnded indicates that a task's series, by its end date or
+// recurrence, ends before all completions could be recorded.
+var ErrSeriesEnded = errors.New("task series ends before all completions")
+
+// Backfill is the outcome of scheduling past completions of a task.
+type Backfill struct {
+	// History lists the entries to record, in order: one per completion,
+	// each followed by the occurrences its catch-up policy skipped.
+	History []*models.TaskHistory
+	// Rolls lists the business-day rolls made on the way, see RollOverride.
+	Rolls       []*models.OccurrenceOverride
+	NextDueDate *time.Time
+}
+
+// ScheduleBackfill schedules completions of a task made at the given times,
+// in order, as if each had been recorded when it was made: every completion
+// closes the occurrence due then and the next is scheduled from it, so
+// rolling tasks continue from the completion and fixed ones along their
+// series. The latest completion catches up to now. used is the number of
+// history entries the task already has, counted against its MaxOccurrences;
+// skipped occurrences give way to completions once those run short.
+func ScheduleBackfill(task *models.Task, used int, completedDates []time.Time, now time.Time, loc *time.Location) (Backfill, error) {
+	limit := -1
+	if task.MaxOccurrences > 0 {
+		limit = task.MaxOccurrences - used
+		if len(completedDates) > limit {
+			return Backfill{}, ErrOccurrencesUsedUp
+		}
+	}
+
+	var backfill Backfill
+	current := *task
+	current.Overrides = slices.Clone(task.Overrides)
+	for i, completedDate := range completedDates {
+		if i > 0 && current.NextDueDate == nil {
+			return Backfill{}, ErrSeriesEnded
+		}
+
+		backfill.History = append(backfill.History, &models.TaskHistory{
+			TaskID:        task.ID,
+			CompletedDate: &completedDate,
+			DueDate:       current.NextDueDate,
+		})
+
+		catchUpTo := completedDate
+		if i == len(completedDates)-1 {
+			catchUpTo = now
+		}
+		schedule, err := ScheduleCatchUp(&current, completedDate, catchUpTo, loc)
+		if err != nil {
+			return Backfill{}, err
+		}
+
+		if roll := schedule.RollOverride(task.ID); roll != nil {
+			backfill.Rolls = append(backfill.Rolls, roll)
+			current.Overrides = append(current.Overrides, *roll)
+		}
+
+		pending := len(completedDates) - 1 - i
+		for _, missedDate := range schedule.Missed {
+			if limit >= 0 && len(backfill.History)+pending >= limit {
+				break
+			}
+			backfill.History = append(backfill.History, &models.TaskHistory{
+				TaskID:  task.ID,
+				DueDate: &missedDate,
+			})
+		}
+
+		current.NextDueDate = schedule.NextDueDate
+	}
+
+	backfill.NextDueDate = current.NextDueDate
+	if limit >= 0 && len(backfill.History) >= limit {
+		backfill.NextDueDate = nil
+	}
+
+	return backfill, nil
+}
+
+// PreviewOccurrences lists up to count upcoming due dates of a task, starting
+// with its current due date. The task is assumed to have no history yet, so
+// all of its MaxOccurrences are still available. Rolling tasks are assumed to
+// be completed exactly when they fall due, since the actual completion times
+// are not yet known.
+func PreviewOccurrences(task *models.Task, count int, loc *time.Location) ([]time.Time, error) {
+	if task.NextDueDate == nil {
+		return nil, errors.New("task has no next due date")
+	}
+
+	if task.MaxOccurrences > 0 && count > task.MaxOccurrences {
+		count = task.MaxOccurrences
+	}
+
+	occurrences := make([]time.Time, 0, count)
+
+	dueDate := task.NextDueDate.UTC()
+	if task.EndDate != nil && dueDate.After(*task.EndDate) {
+		return occurrences, nil
+	}
+
+	preview := *task
+	preview.Overrides = slices.Clone(task.Overrides)
+	for len(occurrences) < count {
+		occurrences = append(occurrences, dueDate)
+		if len(occurrences) == count {
+			break
+		}
+
+		preview.NextDueDate = &dueDate
+		next, err := scheduleOccurrence(&preview, dueDate, loc)
+		if err != nil {
+			return nil, err
+		}
+		if next.NextDueDate == nil || !next.NextDueDate.After(dueDate) {
+			break
+		}
+		if roll := next.RollOverride(task.ID); roll != nil {
+			preview.Overrides = append(preview.Overrides, *roll)
+		}
+		dueDate = *next.NextDueDate
+	}
+
+	return occurrences, nil
+}
@@ -2,12 +2,15 @@ package repos
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/utils/search"
 	"taskwiz.app/core/internal/utils/test"
 )
 
@@ -97,6 +100,59 @@ func (s *TaskTestSuite) TestCreateTaskWithEndDate() {
 	s.Equal(string(models.RepeatWeekly), string(savedTask.Frequency.Type))
 }
 
+func (s *TaskTestSuite) TestCreateTaskWithMonthlyFrequency() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	task := &models.Task{
+		Title:       "Pay rent",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency: models.Frequency{
+			Type:           models.RepeatCustom,
+			On:             models.DayOfTheMonth,
+			DayOfMonth:     31,
+			ClampToLastDay: true,
+		},
+	}
+
+	id, err := s.repo.CreateTask(ctx, task)
+	s.Require().NoError(err)
+
+	savedTask, err := s.repo.GetTask(ctx, id)
+	s.Require().NoError(err)
+	s.Equal(models.DayOfTheMonth, savedTask.Frequency.On)
+	s.Equal(31, savedTask.Frequency.DayOfMonth)
+	s.True(savedTask.Frequency.ClampToLastDay)
+}
+
+func (s *TaskTestSuite) TestCreateTaskWithActiveWindow() {
+	ctx := context.Background()
+	dueDate := time.Date(2025, time.April, 5, 9, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, time.October, 31, 0, 0, 0, 0, time.UTC)
+
+	task := &models.Task{
+		Title:        "Mow the lawn",
+		CreatedBy:    s.testUser.ID,
+		NextDueDate:  &dueDate,
+		IsActive:     true,
+		Frequency:    models.Frequency{Type: models.RepeatWeekly},
+		ActiveWindow: models.ActiveWindow{StartMonth: 4, EndMonth: 10, EndDate: &endDate},
+	}
+
+	id, err := s.repo.CreateTask(ctx, task)
+	s.Require().NoError(err)
+
+	savedTask, err := s.repo.GetTask(ctx, id)
+	s.Require().NoError(err)
+	s.Equal(4, savedTask.ActiveWindow.StartMonth)
+	s.Equal(10, savedTask.ActiveWindow.EndMonth)
+	s.Nil(savedTask.ActiveWindow.StartDate)
+	s.Require().NotNil(savedTask.ActiveWindow.EndDate)
+	s.Equal(endDate, savedTask.ActiveWindow.EndDate.UTC())
+}
+
 func (s *TaskTestSuite) TestUpsertTask() {
 	ctx := context.Background()
 	dueDate := time.Now().Add(24 * time.Hour)
@@ -113,19 +169,189 @@ func (s *TaskTestSuite) TestUpsertTask() {
 	}
 
 	// Create
-	err := s.repo.UpsertTask(ctx, task)
+	err := s.repo.UpsertTask(ctx, task, nil, nil)
 	s.Require().NoError(err)
 	s.Require().Greater(task.ID, 0)
 
 	// Update
 	task.Title = "Updated Test Task"
-	err = s.repo.UpsertTask(ctx, task)
+	err = s.repo.UpsertTask(ctx, task, nil, nil)
 	s.Require().NoError(err)
 
 	var updatedTask models.Task
 	err = s.DB.First(&updatedTask, task.ID).Error
 	s.Require().NoError(err)
 	s.Equal("Updated Test Task", updatedTask.Title)
+
+	// Without changes, no audit entry is recorded.
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, &models.TaskAudit{UserID: s.testUser.ID}))
+	audits, err := s.repo.GetTaskAudit(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Empty(audits)
+}
+
+func (s *TaskTestSuite) TestUpsertTaskRejectsStaleVersion() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	task := &models.Task{
+		Title:       "Mow lawn",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, nil))
+	s.Equal(1, task.Version)
+
+	// Two copies of the same version: the first edit wins.
+	first, second := *task, *task
+	first.Title = "Mow the lawn"
+	s.Require().NoError(s.repo.UpsertTask(ctx, &first, nil, nil))
+	s.Equal(2, first.Version)
+
+	second.Title = "Mow front lawn"
+	s.ErrorIs(s.repo.UpsertTask(ctx, &second, nil, nil), ErrVersionConflict)
+
+	saved, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal("Mow the lawn", saved.Title)
+	s.Equal(2, saved.Version)
+
+	// Completing the task moves it to a new version too.
+	nextDueDate := dueDate.Add(24 * time.Hour)
+	completedDate := time.Now()
+	s.Require().NoError(s.repo.CompleteTask(ctx, saved, s.testUser.ID, &nextDueDate, &completedDate))
+	s.ErrorIs(s.repo.UpsertTask(ctx, saved, nil, nil), ErrVersionConflict)
+
+	saved, err = s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(3, saved.Version)
+}
+
+func (s *TaskTestSuite) TestUpsertTaskLabels() {
+	ctx := context.Background()
+
+	labels := []*models.Label{
+		{Name: "Garden", Color: "#00FF00", CreatedBy: s.testUser.ID},
+		{Name: "Weekend", Color: "#0000FF", CreatedBy: s.testUser.ID},
+	}
+	s.Require().NoError(s.DB.Create(&labels).Error)
+
+	task := &models.Task{
+		Title:     "Rake leaves",
+		CreatedBy: s.testUser.ID,
+		IsActive:  true,
+		Frequency: models.Frequency{Type: models.RepeatOnce},
+	}
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, []int{labels[0].ID}, nil))
+
+	labelIDs := func() []int {
+		saved, err := s.repo.GetTask(ctx, task.ID)
+		s.Require().NoError(err)
+		var ids []int
+		for _, label := range saved.Labels {
+			ids = append(ids, label.ID)
+		}
+		return ids
+	}
+	s.Equal([]int{labels[0].ID}, labelIDs())
+
+	// An edit losing a version conflict leaves the labels alone.
+	stale := *task
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, nil))
+	s.ErrorIs(s.repo.UpsertTask(ctx, &stale, []int{labels[1].ID}, nil), ErrVersionConflict)
+	s.Equal([]int{labels[0].ID}, labelIDs())
+
+	// Nil labels keep them; an empty list removes them.
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, []int{labels[1].ID}, nil))
+	s.Equal([]int{labels[1].ID}, labelIDs())
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, nil))
+	s.Equal([]int{labels[1].ID}, labelIDs())
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, []int{}, nil))
+	s.Empty(labelIDs())
+}
+
+func (s *TaskTestSuite) TestUpsertTaskRecordsAudit() {
+	ctx := context.Background()
+	dueDate := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	task := &models.Task{
+		Title:       "Water plants",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatOnce},
+	}
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, nil))
+
+	before := *task
+	task.Title = "Water the plants"
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, &models.TaskAudit{
+		UserID:  s.testUser.ID,
+		Changes: DiffTask(&before, task),
+	}))
+
+	before = *task
+	task.Priority = 3
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, &models.TaskAudit{
+		UserID:  s.testUser.ID,
+		Changes: DiffTask(&before, task),
+	}))
+
+	audits, err := s.repo.GetTaskAudit(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(audits, 2)
+
+	// Most recent edit first.
+	s.Equal(s.testUser.ID, audits[0].UserID)
+	s.Require().Len(audits[0].Changes, 1)
+	s.Equal("priority", audits[0].Changes[0].Field)
+	s.JSONEq(`0`, string(audits[0].Changes[0].Before))
+	s.JSONEq(`3`, string(audits[0].Changes[0].After))
+
+	s.Require().Len(audits[1].Changes, 1)
+	s.Equal("title", audits[1].Changes[0].Field)
+	s.JSONEq(`"Water plants"`, string(audits[1].Changes[0].Before))
+	s.JSONEq(`"Water the plants"`, string(audits[1].Changes[0].After))
+	s.False(audits[1].CreatedAt.IsZero())
+}
+
+func (s *TaskTestSuite) TestDiffTask() {
+	dueDate := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	before := &models.Task{
+		Title:         "Pay rent",
+		NextDueDate:   &dueDate,
+		Frequency:     models.Frequency{Type: models.RepeatMonthly},
+		Labels:        []models.Label{{ID: 2, Name: "home"}, {ID: 1, Name: "bills"}},
+		HabitProgress: 1,
+	}
+
+	// The same instant in another zone, labels in another order and fields
+	// outside the audit are no change.
+	sameDue := dueDate.In(time.FixedZone("CET", 3600))
+	after := *before
+	after.NextDueDate = &sameDue
+	after.Labels = []models.Label{{ID: 1}, {ID: 2}}
+	after.HabitProgress = 2
+	s.Empty(DiffTask(before, &after))
+
+	newDue := dueDate.AddDate(0, 1, 0)
+	after.Title = "Pay the rent"
+	after.NextDueDate = &newDue
+	after.Labels = []models.Label{{ID: 1}}
+	changes := DiffTask(before, &after)
+	s.Require().Len(changes, 3)
+
+	s.Equal("title", changes[0].Field)
+	s.JSONEq(`"Pay rent"`, string(changes[0].Before))
+	s.JSONEq(`"Pay the rent"`, string(changes[0].After))
+	s.Equal("next_due_date", changes[1].Field)
+	s.JSONEq(`"2026-03-02T09:00:00Z"`, string(changes[1].Before))
+	s.JSONEq(`"2026-04-02T09:00:00Z"`, string(changes[1].After))
+	s.Equal("labels", changes[2].Field)
+	s.JSONEq(`[1,2]`, string(changes[2].Before))
+	s.JSONEq(`[1]`, string(changes[2].After))
 }
 
 func (s *TaskTestSuite) TestGetTask() {
@@ -243,6 +469,106 @@ func (s *TaskTestSuite) TestGetTasks() {
 	s.Equal("Task 2", retrievedTasks[1].Title)
 }
 
+func (s *TaskTestSuite) TestGetTasksSorted() {
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	tasks := []*models.Task{
+		{Title: "b chores", Priority: models.PriorityHigh, NextDueDate: ptrTo(now.Add(48 * time.Hour))},
+		{Title: "Anytime", Priority: models.PriorityLow},
+		{Title: "C errands", Priority: models.PriorityHigh, NextDueDate: ptrTo(now.Add(24 * time.Hour))},
+		{Title: "a bills", Priority: models.PriorityNone, NextDueDate: ptrTo(now.Add(72 * time.Hour))},
+	}
+	for _, task := range tasks {
+		task.CreatedBy = s.testUser.ID
+		task.IsActive = true
+		task.Frequency = models.Frequency{Type: models.RepeatOnce}
+		s.Require().NoError(s.DB.Create(task).Error)
+	}
+
+	titles := func(sorts ...models.TaskSort) []string {
+		retrieved, err := s.repo.GetTasks(ctx, s.testUser.ID, sorts...)
+		s.Require().NoError(err)
+		var out []string
+		for _, task := range retrieved {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	// Tasks without a due date come last in either direction.
+	s.Equal([]string{"C errands", "b chores", "a bills", "Anytime"}, titles())
+	s.Equal([]string{"a bills", "b chores", "C errands", "Anytime"}, titles(models.TaskSort{Key: models.TaskSortDue, Descending: true}))
+
+	// Titles compare without case and equal priorities fall back to the ID.
+	s.Equal([]string{"a bills", "Anytime", "b chores", "C errands"}, titles(models.TaskSort{Key: models.TaskSortTitle}))
+	s.Equal([]string{"b chores", "C errands", "Anytime", "a bills"}, titles(models.TaskSort{Key: models.TaskSortPriority, Descending: true}))
+	s.Equal([]string{"C errands", "b chores", "Anytime", "a bills"}, titles(
+		models.TaskSort{Key: models.TaskSortPriority, Descending: true},
+		models.TaskSort{Key: models.TaskSortDue},
+	))
+}
+
+func (s *TaskTestSuite) TestGetTasksSortedByCreatedAfterEdit() {
+	ctx := context.Background()
+
+	created := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	var tasks []*models.Task
+	for i, title := range []string{"First", "Second", "Third"} {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: s.testUser.ID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+			CreatedAt: created.AddDate(0, 0, i),
+		}
+		s.Require().NoError(s.DB.Create(task).Error)
+		tasks = append(tasks, task)
+	}
+
+	// An edit is saved from a task built anew, without its creation time.
+	edit := &models.Task{
+		ID:        tasks[2].ID,
+		Title:     "Third, edited",
+		CreatedBy: s.testUser.ID,
+		IsActive:  true,
+		Frequency: models.Frequency{Type: models.RepeatOnce},
+		Version:   tasks[2].Version,
+	}
+	s.Require().NoError(s.repo.UpsertTask(ctx, edit, nil, nil))
+
+	retrieved, err := s.repo.GetTasks(ctx, s.testUser.ID, models.TaskSort{Key: models.TaskSortCreated})
+	s.Require().NoError(err)
+	var titles []string
+	for _, task := range retrieved {
+		titles = append(titles, task.Title)
+	}
+	s.Equal([]string{"First", "Second", "Third, edited"}, titles)
+
+	saved, err := s.repo.GetTask(ctx, tasks[2].ID)
+	s.Require().NoError(err)
+	s.True(created.AddDate(0, 0, 2).Equal(saved.CreatedAt))
+}
+
+func (s *TaskTestSuite) TestParseTaskSort() {
+	sorts, err := ParseTaskSort(" priority:DESC, due ,title:asc")
+	s.Require().NoError(err)
+	s.Equal([]models.TaskSort{
+		{Key: models.TaskSortPriority, Descending: true},
+		{Key: models.TaskSortDue},
+		{Key: models.TaskSortTitle},
+	}, sorts)
+
+	sorts, err = ParseTaskSort("")
+	s.Require().NoError(err)
+	s.Empty(sorts)
+
+	for _, raw := range []string{"colour", "due:sideways", "due,due:desc", "due,"} {
+		_, err := ParseTaskSort(raw)
+		s.Error(err, raw)
+	}
+}
+
 func (s *TaskTestSuite) TestDeleteTask() {
 	ctx := context.Background()
 	dueDate := time.Now().Add(24 * time.Hour)
@@ -359,92 +685,182 @@ func (s *TaskTestSuite) TestCompleteTask() {
 	s.WithinDuration(*updatedRecurringTask.NextDueDate, nextDueDate, time.Second)
 }
 
-func (s *TaskTestSuite) TestRevertActivity() {
+func (s *TaskTestSuite) TestCompleteTaskRecordsMissedOccurrences() {
 	ctx := context.Background()
-	dueDate := time.Now().Add(24 * time.Hour)
-	completedDate := time.Now()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	missed := []time.Time{dueDate.AddDate(0, 0, 1), dueDate.AddDate(0, 0, 2)}
+	nextDueDate := dueDate.AddDate(0, 0, 3)
+	completedDate := time.Date(2025, time.January, 3, 12, 0, 0, 0, time.UTC)
 
 	task := &models.Task{
-		Title:       "Undo Task",
+		Title:       "Daily Task",
 		CreatedBy:   s.testUser.ID,
 		NextDueDate: &dueDate,
 		IsActive:    true,
+		CatchUp:     models.CatchUpSkipMissed,
 		Frequency: models.Frequency{
-			Type: models.RepeatOnce,
+			Type: models.RepeatDaily,
 		},
 	}
+	s.Require().NoError(s.DB.Create(task).Error)
 
-	err := s.DB.Create(task).Error
+	err := s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate, missed...)
 	s.Require().NoError(err)
 
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, nil, &completedDate)
-	s.Require().NoError(err)
+	var history []models.TaskHistory
+	s.Require().NoError(s.DB.Where("task_id = ?", task.ID).Order("id").Find(&history).Error)
+	s.Require().Len(history, 3)
+	s.Equal(dueDate, history[0].DueDate.UTC())
+	s.NotNil(history[0].CompletedDate)
+	for i, missedDate := range missed {
+		s.Equal(missedDate, history[i+1].DueDate.UTC())
+		s.Nil(history[i+1].CompletedDate)
+	}
 
-	var history models.TaskHistory
-	err = s.DB.Where("task_id = ?", task.ID).First(&history).Error
+	var updatedTask models.Task
+	s.Require().NoError(s.DB.First(&updatedTask, task.ID).Error)
+	s.Equal(nextDueDate, updatedTask.NextDueDate.UTC())
+	s.Equal(models.CatchUpSkipMissed, updatedTask.CatchUp)
+}
+
+func (s *TaskTestSuite) TestCompleteTaskStopsAtMaxOccurrences() {
+	ctx := context.Background()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	completedDate := time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	task := &models.Task{
+		Title:          "Medication",
+		CreatedBy:      s.testUser.ID,
+		NextDueDate:    &dueDate,
+		MaxOccurrences: 2,
+		IsActive:       true,
+		Frequency: models.Frequency{
+			Type: models.RepeatDaily,
+		},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	secondDueDate := dueDate.AddDate(0, 0, 1)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &secondDueDate, &completedDate))
+
+	task, err := s.repo.GetTask(ctx, task.ID)
 	s.Require().NoError(err)
+	s.True(task.IsActive)
+	s.Equal(secondDueDate, task.NextDueDate.UTC())
+
+	// The second occurrence is the last one, whatever the caller scheduled.
+	thirdDueDate := dueDate.AddDate(0, 0, 2)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &thirdDueDate, &completedDate))
 
-	err = s.repo.RevertActivity(ctx, task.ID, history.ID)
+	task, err = s.repo.GetTask(ctx, task.ID)
 	s.Require().NoError(err)
+	s.False(task.IsActive)
+	s.Nil(task.NextDueDate)
 
-	var updatedTask models.Task
-	err = s.DB.First(&updatedTask, task.ID).Error
+	// Undoing the last completion gives the occurrence back.
+	s.Require().NoError(s.repo.RevertActivity(ctx, task.ID, 0, 1))
+
+	task, err = s.repo.GetTask(ctx, task.ID)
 	s.Require().NoError(err)
-	s.True(updatedTask.IsActive)
-	s.WithinDuration(dueDate, *updatedTask.NextDueDate, time.Second)
+	s.True(task.IsActive)
+	s.Equal(secondDueDate, task.NextDueDate.UTC())
 
-	var count int64
-	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
-	s.Equal(int64(0), count)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &thirdDueDate, &completedDate))
+
+	task, err = s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.False(task.IsActive)
 }
 
-func (s *TaskTestSuite) TestRevertActivityRejectsStaleEntry() {
+func (s *TaskTestSuite) TestCompleteTaskTruncatesMissedAtMaxOccurrences() {
 	ctx := context.Background()
-	dueDate := time.Now().Add(24 * time.Hour)
-	completedDate := time.Now()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	nextDueDate := dueDate.AddDate(0, 0, 4)
+	completedDate := time.Date(2025, time.January, 4, 12, 0, 0, 0, time.UTC)
+	missed := []time.Time{dueDate.AddDate(0, 0, 1), dueDate.AddDate(0, 0, 2), dueDate.AddDate(0, 0, 3)}
 
 	task := &models.Task{
-		Title:       "Recurring Undo Task",
-		CreatedBy:   s.testUser.ID,
-		NextDueDate: &dueDate,
-		IsActive:    true,
+		Title:          "Course",
+		CreatedBy:      s.testUser.ID,
+		NextDueDate:    &dueDate,
+		MaxOccurrences: 3,
+		IsActive:       true,
+		CatchUp:        models.CatchUpSkipMissed,
 		Frequency: models.Frequency{
 			Type: models.RepeatDaily,
 		},
 	}
+	s.Require().NoError(s.DB.Create(task).Error)
 
-	err := s.DB.Create(task).Error
-	s.Require().NoError(err)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate, missed...))
 
-	nextDueDate := dueDate.Add(24 * time.Hour)
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate)
+	history, err := s.repo.GetTaskHistory(ctx, task.ID)
 	s.Require().NoError(err)
+	s.Len(history, 3)
 
-	var firstHistory models.TaskHistory
-	err = s.DB.Where("task_id = ?", task.ID).Order("id asc").First(&firstHistory).Error
+	task, err = s.repo.GetTask(ctx, task.ID)
 	s.Require().NoError(err)
+	s.False(task.IsActive)
+	s.Nil(task.NextDueDate)
+}
 
-	// A second completion makes the first history entry stale.
-	laterDueDate := nextDueDate.Add(24 * time.Hour)
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &laterDueDate, &completedDate)
+func (s *TaskTestSuite) TestHabitPeriods() {
+	ctx := context.Background()
+	dueDate := time.Date(2025, time.January, 5, 23, 0, 0, 0, time.UTC)
+	nextDueDate := dueDate.AddDate(0, 0, 7)
+	completedDate := time.Date(2025, time.January, 3, 18, 0, 0, 0, time.UTC)
+
+	task := &models.Task{
+		Title:       "Exercise",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		HabitTarget: 3,
+		Frequency: models.Frequency{
+			Type: models.RepeatWeekly,
+		},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	for i := 1; i <= 3; i++ {
+		s.Require().NoError(s.repo.AddHabitProgress(ctx, task))
+		s.Equal(i, task.HabitProgress)
+	}
+
+	// Meeting the target records the period and starts the next one.
+	err := s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate, nextDueDate.AddDate(0, 0, -1))
 	s.Require().NoError(err)
 
-	err = s.repo.RevertActivity(ctx, task.ID, firstHistory.ID)
-	s.Require().ErrorIs(err, ErrActivityNotLatest)
+	var history []models.TaskHistory
+	s.Require().NoError(s.DB.Where("task_id = ?", task.ID).Order("id").Find(&history).Error)
+	s.Require().Len(history, 2)
+	s.Equal(3, history[0].Progress)
+	s.Equal(3, history[0].Target)
+	s.NotNil(history[0].CompletedDate)
+	s.Zero(history[1].Progress)
+	s.Equal(3, history[1].Target)
 
-	// Both history rows remain.
-	var count int64
-	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
-	s.Equal(int64(2), count)
+	var updatedTask models.Task
+	s.Require().NoError(s.DB.First(&updatedTask, task.ID).Error)
+	s.Zero(updatedTask.HabitProgress)
+	s.Equal(nextDueDate, updatedTask.NextDueDate.UTC())
+
+	// Undoing the completion that met the target reopens the period with
+	// the progress made before it.
+	s.Require().NoError(s.repo.RevertActivity(ctx, task.ID, 0, 1))
+	s.Require().NoError(s.repo.RevertActivity(ctx, task.ID, 0, 1))
+	s.Require().NoError(s.DB.First(&updatedTask, task.ID).Error)
+	s.Equal(2, updatedTask.HabitProgress)
+	s.Equal(dueDate, updatedTask.NextDueDate.UTC())
 }
 
-func (s *TaskTestSuite) TestRevertActivityDefaultsToLatest() {
+func (s *TaskTestSuite) TestCompleteTaskLeavesRegularHistoryUntouched() {
 	ctx := context.Background()
-	dueDate := time.Now().Add(24 * time.Hour)
-	completedDate := time.Now()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	nextDueDate := dueDate.AddDate(0, 0, 1)
 
 	task := &models.Task{
-		Title:       "Default Latest Undo Task",
+		Title:       "Water plants",
 		CreatedBy:   s.testUser.ID,
 		NextDueDate: &dueDate,
 		IsActive:    true,
@@ -452,76 +868,356 @@ func (s *TaskTestSuite) TestRevertActivityDefaultsToLatest() {
 			Type: models.RepeatDaily,
 		},
 	}
+	s.Require().NoError(s.DB.Create(task).Error)
 
-	err := s.DB.Create(task).Error
-	s.Require().NoError(err)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &dueDate))
 
-	nextDueDate := dueDate.Add(24 * time.Hour)
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate)
-	s.Require().NoError(err)
+	var progress *int
+	s.Require().NoError(s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Select("progress").Scan(&progress).Error)
+	s.Nil(progress)
+}
 
-	laterDueDate := nextDueDate.Add(24 * time.Hour)
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &laterDueDate, &completedDate)
-	s.Require().NoError(err)
+func (s *TaskTestSuite) TestGetLapsedHabitTasks() {
+	ctx := context.Background()
+	now := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
 
-	// A non-positive history id reverts the most recent action.
-	err = s.repo.RevertActivity(ctx, task.ID, 0)
-	s.Require().NoError(err)
+	lapsed := &models.Task{
+		Title:       "Read",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: ptrTo(now.Add(-time.Hour)),
+		IsActive:    true,
+		HabitTarget: 5,
+		Frequency:   models.Frequency{Type: models.RepeatWeekly},
+	}
+	running := &models.Task{
+		Title:       "Meditate",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: ptrTo(now.Add(time.Hour)),
+		IsActive:    true,
+		HabitTarget: 5,
+		Frequency:   models.Frequency{Type: models.RepeatWeekly},
+	}
+	overdue := &models.Task{
+		Title:       "Taxes",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: ptrTo(now.Add(-time.Hour)),
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatYearly},
+	}
+	for _, task := range []*models.Task{lapsed, running, overdue} {
+		s.Require().NoError(s.DB.Create(task).Error)
+	}
 
-	var count int64
-	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
-	s.Equal(int64(1), count)
+	tasks, err := s.repo.GetLapsedHabitTasks(ctx, now)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Equal(lapsed.ID, tasks[0].ID)
 }
 
-func (s *TaskTestSuite) TestRevertActivityNoHistory() {
+func (s *TaskTestSuite) TestChecklist() {
 	ctx := context.Background()
 
 	task := &models.Task{
-		Title:     "No History Task",
+		Title:     "Pack for trip",
 		CreatedBy: s.testUser.ID,
 		IsActive:  true,
 		Frequency: models.Frequency{Type: models.RepeatOnce},
 	}
-	err := s.DB.Create(task).Error
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	titles := func() []string {
+		items, err := s.repo.GetChecklist(ctx, task.ID)
+		s.Require().NoError(err)
+		var out []string
+		for i, item := range items {
+			s.Equal(i, item.Position)
+			out = append(out, item.Title)
+		}
+		return out
+	}
+
+	passport := &models.ChecklistItem{Title: "Passport"}
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, task.ID, passport, nil))
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, task.ID, &models.ChecklistItem{Title: "Charger"}, nil))
+	first := 0
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, task.ID, &models.ChecklistItem{Title: "Tickets"}, &first))
+	s.Equal([]string{"Tickets", "Passport", "Charger"}, titles())
+
+	// Positions past the end move the item last.
+	done, last := true, 10
+	err := s.repo.UpdateChecklistItem(ctx, task.ID, passport.ID, models.UpdateChecklistItemReq{IsDone: &done, Position: &last})
 	s.Require().NoError(err)
+	s.Equal([]string{"Tickets", "Charger", "Passport"}, titles())
 
-	err = s.repo.RevertActivity(ctx, task.ID, 0)
-	s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+	retrievedTask, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(retrievedTask.Checklist, 3)
+	s.True(retrievedTask.Checklist[2].IsDone)
+
+	s.Require().NoError(s.repo.DeleteChecklistItem(ctx, task.ID, retrievedTask.Checklist[0].ID))
+	s.Equal([]string{"Charger", "Passport"}, titles())
+
+	// Items are only reachable through their own task.
+	err = s.repo.UpdateChecklistItem(ctx, task.ID+1, passport.ID, models.UpdateChecklistItemReq{IsDone: &done})
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	s.ErrorIs(s.repo.DeleteChecklistItem(ctx, task.ID+1, passport.ID), gorm.ErrRecordNotFound)
 }
 
-func (s *TaskTestSuite) TestRevertActivityRejectsWrongTask() {
+func (s *TaskTestSuite) TestDependencies() {
 	ctx := context.Background()
-	completedDate := time.Now()
 
-	task := &models.Task{
-		Title:     "Owned Task",
-		CreatedBy: s.testUser.ID,
-		IsActive:  true,
-		Frequency: models.Frequency{Type: models.RepeatOnce},
+	var tasks []*models.Task
+	for _, title := range []string{"Buy paint", "Paint fence", "Invite neighbours"} {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: s.testUser.ID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+		}
+		s.Require().NoError(s.DB.Create(task).Error)
+		tasks = append(tasks, task)
 	}
-	err := s.DB.Create(task).Error
+	paint, fence, party := tasks[0], tasks[1], tasks[2]
+
+	s.Require().NoError(s.repo.AddDependency(ctx, s.testUser.ID, fence.ID, paint.ID))
+	s.Require().NoError(s.repo.AddDependency(ctx, s.testUser.ID, party.ID, fence.ID))
+	// Adding the same dependency twice is harmless.
+	s.Require().NoError(s.repo.AddDependency(ctx, s.testUser.ID, party.ID, fence.ID))
+
+	s.ErrorIs(s.repo.AddDependency(ctx, s.testUser.ID, paint.ID, paint.ID), ErrDependencyCycle)
+	s.ErrorIs(s.repo.AddDependency(ctx, s.testUser.ID, paint.ID, party.ID), ErrDependencyCycle)
+
+	blockers, dependents, err := s.repo.GetDependencies(ctx, fence.ID)
 	s.Require().NoError(err)
+	s.Require().Len(blockers, 1)
+	s.Equal(paint.ID, blockers[0].ID)
+	s.Require().Len(dependents, 1)
+	s.Equal(party.ID, dependents[0].ID)
+	s.True(dependents[0].Blocked)
 
-	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, nil, &completedDate)
+	retrieved, err := s.repo.GetTasks(ctx, s.testUser.ID)
 	s.Require().NoError(err)
+	blocked := map[int]bool{}
+	for _, task := range retrieved {
+		blocked[task.ID] = task.Blocked
+	}
+	s.Equal(map[int]bool{paint.ID: false, fence.ID: true, party.ID: true}, blocked)
 
-	var history models.TaskHistory
-	err = s.DB.Where("task_id = ?", task.ID).First(&history).Error
+	// A blocker stops blocking once it is no longer active.
+	s.Require().NoError(s.DB.Model(paint).Update("is_active", false).Error)
+	retrievedTask, err := s.repo.GetTask(ctx, fence.ID)
 	s.Require().NoError(err)
+	s.False(retrievedTask.Blocked)
 
-	// The history id does not belong to task id 99999.
-	err = s.repo.RevertActivity(ctx, 99999, history.ID)
-	s.Require().Error(err)
+	s.Require().NoError(s.repo.RemoveDependency(ctx, party.ID, fence.ID))
+	s.ErrorIs(s.repo.RemoveDependency(ctx, party.ID, fence.ID), gorm.ErrRecordNotFound)
+	retrievedTask, err = s.repo.GetTask(ctx, party.ID)
+	s.Require().NoError(err)
+	s.False(retrievedTask.Blocked)
 }
 
-func (s *TaskTestSuite) TestGetTaskHistory() {
+func (s *TaskTestSuite) TestCompleteTaskResetsChecklist() {
 	ctx := context.Background()
-	dueDate := time.Now().Add(-24 * time.Hour) // Due yesterday
-	completedDate := time.Now()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	nextDueDate := dueDate.AddDate(0, 0, 7)
 
-	// Create a task
 	task := &models.Task{
-		Title:       "Test Task",
+		Title:       "Weekly review",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatWeekly},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	item := &models.ChecklistItem{Title: "Inbox zero"}
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, task.ID, item, nil))
+	done := true
+	s.Require().NoError(s.repo.UpdateChecklistItem(ctx, task.ID, item.ID, models.UpdateChecklistItemReq{IsDone: &done}))
+
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &dueDate))
+
+	items, err := s.repo.GetChecklist(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.False(items[0].IsDone)
+}
+
+func (s *TaskTestSuite) TestRevertActivity() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+	completedDate := time.Now()
+
+	task := &models.Task{
+		Title:       "Undo Task",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency: models.Frequency{
+			Type: models.RepeatOnce,
+		},
+	}
+
+	err := s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, nil, &completedDate)
+	s.Require().NoError(err)
+
+	var history models.TaskHistory
+	err = s.DB.Where("task_id = ?", task.ID).First(&history).Error
+	s.Require().NoError(err)
+
+	err = s.repo.RevertActivity(ctx, task.ID, history.ID, 1)
+	s.Require().NoError(err)
+
+	var updatedTask models.Task
+	err = s.DB.First(&updatedTask, task.ID).Error
+	s.Require().NoError(err)
+	s.True(updatedTask.IsActive)
+	s.WithinDuration(dueDate, *updatedTask.NextDueDate, time.Second)
+
+	var count int64
+	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
+	s.Equal(int64(0), count)
+}
+
+func (s *TaskTestSuite) TestRevertActivityUndoesLaterEntries() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+	completedDate := time.Now()
+
+	task := &models.Task{
+		Title:       "Recurring Undo Task",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency: models.Frequency{
+			Type: models.RepeatDaily,
+		},
+	}
+
+	err := s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	nextDueDate := dueDate.Add(24 * time.Hour)
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate)
+	s.Require().NoError(err)
+
+	var firstHistory models.TaskHistory
+	err = s.DB.Where("task_id = ?", task.ID).Order("id asc").First(&firstHistory).Error
+	s.Require().NoError(err)
+
+	// A second completion makes the first history entry stale.
+	laterDueDate := nextDueDate.Add(24 * time.Hour)
+	task.NextDueDate = &nextDueDate
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &laterDueDate, &completedDate)
+	s.Require().NoError(err)
+
+	err = s.repo.RevertActivity(ctx, task.ID, firstHistory.ID, 1)
+	s.Require().ErrorIs(err, ErrActivityNotLatest)
+
+	// Both history rows remain.
+	var count int64
+	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
+	s.Equal(int64(2), count)
+
+	// Undoing two steps from the first entry takes the second with it.
+	err = s.repo.RevertActivity(ctx, task.ID, firstHistory.ID, 2)
+	s.Require().NoError(err)
+
+	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
+	s.Equal(int64(0), count)
+
+	var updatedTask models.Task
+	s.Require().NoError(s.DB.First(&updatedTask, task.ID).Error)
+	s.WithinDuration(dueDate, *updatedTask.NextDueDate, time.Second)
+}
+
+func (s *TaskTestSuite) TestRevertActivityDefaultsToLatest() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+	completedDate := time.Now()
+
+	task := &models.Task{
+		Title:       "Default Latest Undo Task",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency: models.Frequency{
+			Type: models.RepeatDaily,
+		},
+	}
+
+	err := s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	nextDueDate := dueDate.Add(24 * time.Hour)
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate)
+	s.Require().NoError(err)
+
+	laterDueDate := nextDueDate.Add(24 * time.Hour)
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, &laterDueDate, &completedDate)
+	s.Require().NoError(err)
+
+	// A non-positive history id reverts the most recent action.
+	err = s.repo.RevertActivity(ctx, task.ID, 0, 1)
+	s.Require().NoError(err)
+
+	var count int64
+	s.DB.Model(&models.TaskHistory{}).Where("task_id = ?", task.ID).Count(&count)
+	s.Equal(int64(1), count)
+}
+
+func (s *TaskTestSuite) TestRevertActivityNoHistory() {
+	ctx := context.Background()
+
+	task := &models.Task{
+		Title:     "No History Task",
+		CreatedBy: s.testUser.ID,
+		IsActive:  true,
+		Frequency: models.Frequency{Type: models.RepeatOnce},
+	}
+	err := s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	err = s.repo.RevertActivity(ctx, task.ID, 0, 1)
+	s.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *TaskTestSuite) TestRevertActivityRejectsWrongTask() {
+	ctx := context.Background()
+	completedDate := time.Now()
+
+	task := &models.Task{
+		Title:     "Owned Task",
+		CreatedBy: s.testUser.ID,
+		IsActive:  true,
+		Frequency: models.Frequency{Type: models.RepeatOnce},
+	}
+	err := s.DB.Create(task).Error
+	s.Require().NoError(err)
+
+	err = s.repo.CompleteTask(ctx, task, s.testUser.ID, nil, &completedDate)
+	s.Require().NoError(err)
+
+	var history models.TaskHistory
+	err = s.DB.Where("task_id = ?", task.ID).First(&history).Error
+	s.Require().NoError(err)
+
+	// The history id does not belong to task id 99999.
+	err = s.repo.RevertActivity(ctx, 99999, history.ID, 1)
+	s.Require().Error(err)
+}
+
+func (s *TaskTestSuite) TestGetTaskHistory() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(-24 * time.Hour) // Due yesterday
+	completedDate := time.Now()
+
+	// Create a task
+	task := &models.Task{
+		Title:       "Test Task",
 		CreatedBy:   s.testUser.ID,
 		NextDueDate: &dueDate,
 		IsActive:    true,
@@ -669,6 +1365,43 @@ func (s *TaskTestSuite) TestScheduleNextDueDate() {
 			completedDate: now,
 			expectedType:  "nil", // Should not calculate a next due date
 		},
+		{
+			name: "Recurrence rule",
+			task: &models.Task{
+				NextDueDate: &now,
+				Frequency: models.Frequency{
+					Type:  models.RepeatRRule,
+					RRule: "FREQ=DAILY;INTERVAL=2",
+				},
+			},
+			completedDate: now,
+			expectedType:  "time",
+			expectedDelta: 48 * time.Hour,
+		},
+		{
+			name: "Recurrence rule with exhausted count",
+			task: &models.Task{
+				NextDueDate: &now,
+				Frequency: models.Frequency{
+					Type:  models.RepeatRRule,
+					RRule: "FREQ=DAILY;COUNT=1",
+				},
+			},
+			completedDate: now,
+			expectedType:  "nil",
+		},
+		{
+			name: "Invalid recurrence rule",
+			task: &models.Task{
+				NextDueDate: &now,
+				Frequency: models.Frequency{
+					Type:  models.RepeatRRule,
+					RRule: "FREQ=SOMETIMES",
+				},
+			},
+			completedDate: now,
+			expectError:   true,
+		},
 		{
 			name: "Nil NextDueDate with non-rolling task",
 			task: &models.Task{
@@ -698,7 +1431,7 @@ func (s *TaskTestSuite) TestScheduleNextDueDate() {
 
 	for _, tc := range testCases {
 		s.Run(tc.name, func() {
-			nextDueDate, err := ScheduleNextDueDate(tc.task, tc.completedDate)
+			nextDueDate, err := ScheduleNextDueDate(tc.task, tc.completedDate, time.UTC)
 
 			if tc.expectError {
 				s.Require().Error(err)
@@ -726,68 +1459,933 @@ func (s *TaskTestSuite) TestScheduleNextDueDate() {
 	}
 }
 
-func (s *TaskTestSuite) TestGetRecentActivity() {
-	ctx := context.Background()
-	completedDate := time.Now()
-	dueDate := time.Now().Add(24 * time.Hour)
+func (s *TaskTestSuite) TestScheduleNextDueDateRRule() {
+	dtstart := time.Date(2025, time.March, 28, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{
+		NextDueDate: &dtstart,
+		Frequency: models.Frequency{
+			Type:  models.RepeatRRule,
+			RRule: "DTSTART:20250328T090000Z\nRRULE:FREQ=YEARLY;BYMONTH=3,6,9,12;BYDAY=-1FR;COUNT=3",
+		},
+	}
 
-	// Non-recurring task completed once.
-	taskA := &models.Task{
-		Title:       "Task A",
-		CreatedBy:   s.testUser.ID,
-		NextDueDate: &dueDate,
-		IsActive:    true,
-		Frequency:   models.Frequency{Type: models.RepeatOnce},
+	expected := []time.Time{
+		time.Date(2025, time.June, 27, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, time.September, 26, 9, 0, 0, 0, time.UTC),
 	}
-	s.Require().NoError(s.DB.Create(taskA).Error)
-	s.Require().NoError(s.repo.CompleteTask(ctx, taskA, s.testUser.ID, nil, &completedDate))
 
-	// Recurring task completed twice.
-	taskB := &models.Task{
-		Title:       "Task B",
-		CreatedBy:   s.testUser.ID,
-		NextDueDate: &dueDate,
-		IsActive:    true,
-		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	for _, want := range expected {
+		next, err := ScheduleNextDueDate(task, time.Now(), time.UTC)
+		s.Require().NoError(err)
+		s.Require().NotNil(next)
+		s.Equal(want, *next)
+		task.NextDueDate = next
 	}
-	s.Require().NoError(s.DB.Create(taskB).Error)
-	nextB1 := dueDate.Add(24 * time.Hour)
-	s.Require().NoError(s.repo.CompleteTask(ctx, taskB, s.testUser.ID, &nextB1, &completedDate))
-	taskB.NextDueDate = &nextB1
-	nextB2 := nextB1.Add(24 * time.Hour)
-	s.Require().NoError(s.repo.CompleteTask(ctx, taskB, s.testUser.ID, &nextB2, &completedDate))
 
-	// Another user's activity must not leak.
-	anotherUser := &models.User{}
-	s.Require().NoError(s.DB.Create(anotherUser).Error)
-	otherTask := &models.Task{Title: "Other", CreatedBy: anotherUser.ID, IsActive: true, Frequency: models.Frequency{Type: models.RepeatOnce}}
-	s.Require().NoError(s.DB.Create(otherTask).Error)
-	s.Require().NoError(s.repo.CompleteTask(ctx, otherTask, anotherUser.ID, nil, &completedDate))
+	next, err := ScheduleNextDueDate(task, time.Now(), time.UTC)
+	s.Require().NoError(err)
+	s.Nil(next, "series should end once COUNT occurrences have been scheduled")
+}
 
-	entries, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, 0, 20)
+func (s *TaskTestSuite) TestScheduleNextDueDateInTimezone() {
+	ny, err := time.LoadLocation("America/New_York")
 	s.Require().NoError(err)
-	s.Require().Len(entries, 3)
 
-	// Reverse-chronological (id desc): taskB second, taskB first, taskA.
-	s.Equal("Task B", entries[0].TaskTitle)
-	s.True(entries[0].IsLatest)
-	s.Equal("Task B", entries[1].TaskTitle)
-	s.False(entries[1].IsLatest)
-	s.Equal("Task A", entries[2].TaskTitle)
-	s.True(entries[2].IsLatest)
+	local := func(y int, m time.Month, d, hh, mm int) time.Time {
+		return time.Date(y, m, d, hh, mm, 0, 0, ny)
+	}
 
-	// Cursor pagination excludes entries with id >= before_id.
-	cursor := entries[1].ID
-	older, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, cursor, 20)
-	s.Require().NoError(err)
-	s.Require().Len(older, 1)
-	s.Equal("Task A", older[0].TaskTitle)
+	testCases := []struct {
+		name      string
+		frequency models.Frequency
+		due       time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "Daily keeps wall clock across spring forward",
+			frequency: models.Frequency{Type: models.RepeatDaily},
+			due:       local(2025, time.March, 8, 8, 0),
+			expected:  local(2025, time.March, 9, 8, 0),
+		},
+		{
+			name:      "Weekly keeps wall clock across fall back",
+			frequency: models.Frequency{Type: models.RepeatWeekly},
+			due:       local(2025, time.October, 28, 8, 0),
+			expected:  local(2025, time.November, 4, 8, 0),
+		},
+		{
+			name:      "Time in the spring forward gap moves past it",
+			frequency: models.Frequency{Type: models.RepeatDaily},
+			due:       local(2025, time.March, 8, 2, 30),
+			expected:  local(2025, time.March, 9, 3, 30),
+		},
+		{
+			name:      "Hourly interval is elapsed time",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.Interval, Every: 24, Unit: models.Hours},
+			due:       local(2025, time.March, 8, 8, 0),
+			expected:  local(2025, time.March, 9, 9, 0),
+		},
+		{
+			name:      "Monthly clamps to the end of a shorter month",
+			frequency: models.Frequency{Type: models.RepeatMonthly},
+			due:       local(2025, time.January, 31, 8, 0),
+			expected:  local(2025, time.February, 28, 8, 0),
+		},
+		{
+			name:      "Monthly lands on a leap day",
+			frequency: models.Frequency{Type: models.RepeatMonthly},
+			due:       local(2024, time.January, 31, 8, 0),
+			expected:  local(2024, time.February, 29, 8, 0),
+		},
+		{
+			name:      "Yearly from a leap day clamps to February 28th",
+			frequency: models.Frequency{Type: models.RepeatYearly},
+			due:       local(2024, time.February, 29, 8, 0),
+			expected:  local(2025, time.February, 28, 8, 0),
+		},
+		{
+			name:      "Weekdays are evaluated in the local zone",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DaysOfTheWeek, Days: []int32{1}},
+			// Friday 21:00 in New York is already Saturday in UTC.
+			due:      local(2025, time.January, 3, 21, 0),
+			expected: local(2025, time.January, 6, 21, 0),
+		},
+		{
+			name:      "Recurrence rule keeps wall clock across fall back",
+			frequency: models.Frequency{Type: models.RepeatRRule, RRule: "FREQ=MONTHLY;BYDAY=1SU"},
+			due:       local(2025, time.October, 5, 1, 30),
+			expected:  local(2025, time.November, 2, 1, 30),
+		},
+		{
+			name:      "Recurrence rule date-only until ends in the local zone",
+			frequency: models.Frequency{Type: models.RepeatRRule, RRule: "FREQ=DAILY;UNTIL=20250105"},
+			// 21:00 on the UNTIL date in New York is already the next day in UTC.
+			due:      local(2025, time.January, 4, 21, 0),
+			expected: local(2025, time.January, 5, 21, 0),
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			due := tc.due.UTC()
+			task := &models.Task{NextDueDate: &due, Frequency: tc.frequency}
+
+			next, err := ScheduleNextDueDate(task, time.Now(), ny)
+			s.Require().NoError(err)
+			s.Require().NotNil(next)
+			s.Equal(time.UTC, next.Location())
+
+			got := next.In(ny)
+			s.Equal(tc.expected.Format("2006-01-02 15:04"), got.Format("2006-01-02 15:04"))
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestScheduleNextDueDateMonthModes() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name      string
+		frequency models.Frequency
+		due       time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "Day of the month",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 3},
+			due:       at(2025, time.January, 3),
+			expected:  at(2025, time.February, 3),
+		},
+		{
+			name:      "Day of the month realigns an off-schedule due date",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 3},
+			due:       at(2025, time.January, 1),
+			expected:  at(2025, time.January, 3),
+		},
+		{
+			name:      "Day of the month every other month",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 3, Every: 2},
+			due:       at(2025, time.January, 3),
+			expected:  at(2025, time.March, 3),
+		},
+		{
+			name:      "Day of the month skips short months",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 31},
+			due:       at(2025, time.January, 31),
+			expected:  at(2025, time.March, 31),
+		},
+		{
+			name:      "Day of the month clamps to the last day",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 31, ClampToLastDay: true},
+			due:       at(2025, time.January, 31),
+			expected:  at(2025, time.February, 28),
+		},
+		{
+			name:      "Clamped day of the month returns to the requested day",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 30, ClampToLastDay: true},
+			due:       at(2024, time.February, 29),
+			expected:  at(2024, time.March, 30),
+		},
+		{
+			name:      "Second Tuesday",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: 2, Days: []int32{2}},
+			due:       at(2025, time.January, 14),
+			expected:  at(2025, time.February, 11),
+		},
+		{
+			name:      "Last Monday",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: models.LastWeekOfMonth, Days: []int32{1}},
+			due:       at(2025, time.May, 26),
+			expected:  at(2025, time.June, 30),
+		},
+		{
+			name:      "Fifth Friday skips months without one",
+			frequency: models.Frequency{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: 5, Days: []int32{5}},
+			due:       at(2025, time.January, 31),
+			expected:  at(2025, time.May, 30),
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			due := tc.due
+			task := &models.Task{NextDueDate: &due, Frequency: tc.frequency}
+
+			next, err := ScheduleNextDueDate(task, time.Now(), time.UTC)
+			s.Require().NoError(err)
+			s.Require().NotNil(next)
+			s.Equal(tc.expected, *next)
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestScheduleCatchUp() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+	// A daily task due on January 1st, finished a week late.
+	now := time.Date(2025, time.January, 8, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		task           models.Task
+		expectedNext   *time.Time
+		expectedMissed []time.Time
+	}{
+		{
+			name:         "Next occurrence keeps the old behaviour",
+			task:         models.Task{Frequency: models.Frequency{Type: models.RepeatDaily}, CatchUp: models.CatchUpNextOccurrence},
+			expectedNext: ptrTo(at(2025, time.January, 2)),
+		},
+		{
+			name:         "Unset policy means next occurrence",
+			task:         models.Task{Frequency: models.Frequency{Type: models.RepeatDaily}},
+			expectedNext: ptrTo(at(2025, time.January, 2)),
+		},
+		{
+			name:         "After now",
+			task:         models.Task{Frequency: models.Frequency{Type: models.RepeatDaily}, CatchUp: models.CatchUpAfterNow},
+			expectedNext: ptrTo(at(2025, time.January, 9)),
+		},
+		{
+			name:         "Skip missed",
+			task:         models.Task{Frequency: models.Frequency{Type: models.RepeatDaily}, CatchUp: models.CatchUpSkipMissed},
+			expectedNext: ptrTo(at(2025, time.January, 9)),
+			expectedMissed: []time.Time{
+				at(2025, time.January, 2),
+				at(2025, time.January, 3),
+				at(2025, time.January, 4),
+				at(2025, time.January, 5),
+				at(2025, time.January, 6),
+				at(2025, time.January, 7),
+				at(2025, time.January, 8),
+			},
+		},
+		{
+			name: "Series ending while catching up",
+			task: models.Task{
+				Frequency: models.Frequency{Type: models.RepeatDaily},
+				CatchUp:   models.CatchUpSkipMissed,
+				EndDate:   ptrTo(at(2025, time.January, 3)),
+			},
+			expectedNext:   nil,
+			expectedMissed: []time.Time{at(2025, time.January, 2), at(2025, time.January, 3)},
+		},
+		{
+			name:         "Rolling tasks never catch up",
+			task:         models.Task{Frequency: models.Frequency{Type: models.RepeatDaily}, CatchUp: models.CatchUpSkipMissed, IsRolling: true},
+			expectedNext: ptrTo(now.AddDate(0, 0, 1)),
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			task := tc.task
+			task.NextDueDate = ptrTo(at(2025, time.January, 1))
+
+			schedule, err := ScheduleCatchUp(&task, now, now, time.UTC)
+			s.Require().NoError(err)
+			s.Equal(tc.expectedNext, schedule.NextDueDate)
+			s.Equal(tc.expectedMissed, schedule.Missed)
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestScheduleNextDueDateWithOverrides() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	overrides := []models.OccurrenceOverride{
+		{OriginalDate: at(2025, time.January, 8)},
+		{OriginalDate: at(2025, time.January, 15), NewDate: ptrTo(at(2025, time.January, 17))},
+	}
+
+	task := &models.Task{
+		Frequency:   models.Frequency{Type: models.RepeatWeekly},
+		NextDueDate: ptrTo(at(2025, time.January, 1)),
+		Overrides:   overrides,
+	}
+
+	// The cancelled occurrence on the 8th is stepped over and the one on the
+	// 15th is moved to the 17th.
+	next, err := ScheduleNextDueDate(task, time.Now(), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 17), *next)
+
+	// The moved occurrence does not shift the rest of the series.
+	task.NextDueDate = next
+	next, err = ScheduleNextDueDate(task, time.Now(), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 22), *next)
+}
+
+func (s *TaskTestSuite) TestScheduleNextDueDateActiveWindow() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name     string
+		task     models.Task
+		due      time.Time
+		expected *time.Time
+	}{
+		{
+			name: "Inside the season",
+			task: models.Task{
+				Frequency:    models.Frequency{Type: models.RepeatWeekly},
+				ActiveWindow: models.ActiveWindow{StartMonth: 4, EndMonth: 10},
+			},
+			due:      at(2025, time.June, 7),
+			expected: ptrTo(at(2025, time.June, 14)),
+		},
+		{
+			name: "Jumps to the next season on the same weekday",
+			task: models.Task{
+				Frequency:    models.Frequency{Type: models.RepeatWeekly},
+				ActiveWindow: models.ActiveWindow{StartMonth: 4, EndMonth: 10},
+			},
+			due:      at(2025, time.October, 25), // Saturday
+			expected: ptrTo(at(2026, time.April, 4)),
+		},
+		{
+			name: "Season wrapping around the new year",
+			task: models.Task{
+				Frequency:    models.Frequency{Type: models.RepeatMonthly},
+				ActiveWindow: models.ActiveWindow{StartMonth: 11, EndMonth: 2},
+			},
+			due:      at(2025, time.February, 1),
+			expected: ptrTo(at(2025, time.November, 1)),
+		},
+		{
+			name: "Waits for the date range to start",
+			task: models.Task{
+				Frequency:    models.Frequency{Type: models.RepeatDaily},
+				ActiveWindow: models.ActiveWindow{StartDate: ptrTo(at(2025, time.March, 1))},
+			},
+			due:      at(2025, time.January, 1),
+			expected: ptrTo(at(2025, time.March, 1)),
+		},
+		{
+			name: "Ends with the date range",
+			task: models.Task{
+				Frequency:    models.Frequency{Type: models.RepeatDaily},
+				ActiveWindow: models.ActiveWindow{EndDate: ptrTo(at(2025, time.January, 1))},
+			},
+			due:      at(2025, time.January, 1),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			task := tc.task
+			task.NextDueDate = ptrTo(tc.due)
+
+			next, err := ScheduleNextDueDate(&task, time.Now(), time.UTC)
+			s.Require().NoError(err)
+			s.Equal(tc.expected, next)
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestIsDormant() {
+	task := &models.Task{ActiveWindow: models.ActiveWindow{StartMonth: 11, EndMonth: 2}}
+
+	s.False(IsDormant(task, time.Date(2025, time.December, 15, 0, 0, 0, 0, time.UTC), time.UTC))
+	s.True(IsDormant(task, time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC), time.UTC))
+	// Late on October 31st in New York is already November in UTC.
+	newYork, err := time.LoadLocation("America/New_York")
+	s.Require().NoError(err)
+	s.True(IsDormant(task, time.Date(2025, time.November, 1, 2, 0, 0, 0, time.UTC), newYork))
+
+	s.False(IsDormant(&models.Task{}, time.Now(), time.UTC))
+}
+
+func (s *TaskTestSuite) TestValidateActiveWindow() {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	s.NoError(ValidateActiveWindow(models.ActiveWindow{}))
+	s.NoError(ValidateActiveWindow(models.ActiveWindow{StartMonth: 11, EndMonth: 2}))
+	s.NoError(ValidateActiveWindow(models.ActiveWindow{StartDate: &start, EndDate: &end}))
+	s.NoError(ValidateActiveWindow(models.ActiveWindow{StartDate: &start}))
+
+	s.Error(ValidateActiveWindow(models.ActiveWindow{StartMonth: 4}))
+	s.Error(ValidateActiveWindow(models.ActiveWindow{StartMonth: 0, EndMonth: 13}))
+	s.Error(ValidateActiveWindow(models.ActiveWindow{StartDate: &end, EndDate: &start}))
+	s.Error(ValidateActiveWindow(models.ActiveWindow{StartMonth: 4, EndMonth: 10, StartDate: &start}))
+}
+
+func (s *TaskTestSuite) TestDueDateWithOverride() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	task := &models.Task{
+		Frequency:   models.Frequency{Type: models.RepeatWeekly},
+		NextDueDate: ptrTo(at(2025, time.January, 1)),
+	}
+
+	// Overriding a later occurrence leaves the due date alone.
+	schedule, err := DueDateWithOverride(task, models.OccurrenceOverride{OriginalDate: at(2025, time.January, 8)}, time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 1), *schedule.NextDueDate)
+
+	// Cancelling the current occurrence advances to the next one.
+	schedule, err = DueDateWithOverride(task, models.OccurrenceOverride{OriginalDate: at(2025, time.January, 1)}, time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 8), *schedule.NextDueDate)
+
+	// Moving it returns the new date, and removing that override restores it.
+	moved := models.OccurrenceOverride{OriginalDate: at(2025, time.January, 1), NewDate: ptrTo(at(2025, time.January, 3))}
+	schedule, err = DueDateWithOverride(task, moved, time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 3), *schedule.NextDueDate)
+
+	task.NextDueDate = schedule.NextDueDate
+	task.Overrides = []models.OccurrenceOverride{moved}
+	s.Equal(at(2025, time.January, 1), *DueDateWithoutOverride(task, moved))
+
+	// Cancelling a moved occurrence advances from its original date.
+	schedule, err = DueDateWithOverride(task, models.OccurrenceOverride{OriginalDate: at(2025, time.January, 1)}, time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 8), *schedule.NextDueDate)
+}
+
+func (s *TaskTestSuite) TestOccurrenceOverrides() {
+	ctx := context.Background()
+	dueDate := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	movedDate := time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)
+
+	task := &models.Task{
+		Title:       "Weekly Task",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency: models.Frequency{
+			Type: models.RepeatWeekly,
+		},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	override := &models.OccurrenceOverride{TaskID: task.ID, OriginalDate: dueDate}
+	s.Require().NoError(s.repo.SaveOccurrenceOverride(ctx, task, override, &dueDate))
+
+	// Saving the same occurrence again replaces the override.
+	replacement := &models.OccurrenceOverride{TaskID: task.ID, OriginalDate: dueDate, NewDate: &movedDate}
+	s.Require().NoError(s.repo.SaveOccurrenceOverride(ctx, task, replacement, &movedDate))
+	s.Equal(override.ID, replacement.ID)
+
+	task, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(movedDate, task.NextDueDate.UTC())
+	s.Require().Len(task.Overrides, 1)
+	s.Equal(movedDate, task.Overrides[0].NewDate.UTC())
+
+	overrides, err := s.repo.GetOccurrenceOverrides(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Len(overrides, 1)
+
+	s.Require().NoError(s.repo.DeleteOccurrenceOverride(ctx, task, replacement.ID, &dueDate))
+
+	task, err = s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(dueDate, task.NextDueDate.UTC())
+	s.Empty(task.Overrides)
+
+	err = s.repo.DeleteOccurrenceOverride(ctx, task, replacement.ID, &dueDate)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func (s *TaskTestSuite) TestValidateCatchUpPolicy() {
+	s.NoError(ValidateCatchUpPolicy(""))
+	s.NoError(ValidateCatchUpPolicy(models.CatchUpAfterNow))
+	s.Error(ValidateCatchUpPolicy("whenever"))
+}
+
+func (s *TaskTestSuite) TestValidateBusinessDays() {
+	s.NoError(ValidateBusinessDays(""))
+	s.NoError(ValidateBusinessDays(models.BusinessDaysBackward))
+	s.Error(ValidateBusinessDays("sideways"))
+}
+
+func (s *TaskTestSuite) TestRollToBusinessDay() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+	calendar := &models.HolidayCalendar{
+		Holidays: []models.Holiday{{Date: "2025-01-01"}, {Date: "2025-01-06"}},
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	s.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		roll     models.BusinessDayRoll
+		date     time.Time
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			name:     "Any day without the setting",
+			date:     at(2025, time.January, 4),
+			expected: at(2025, time.January, 4),
+		},
+		{
+			name:     "Working day stays",
+			roll:     models.BusinessDaysForward,
+			date:     at(2025, time.January, 2),
+			expected: at(2025, time.January, 2),
+		},
+		{
+			name:     "Holiday rolls forward",
+			roll:     models.BusinessDaysForward,
+			date:     at(2025, time.January, 1),
+			expected: at(2025, time.January, 2),
+		},
+		{
+			name:     "Weekend rolls forward past a holiday",
+			roll:     models.BusinessDaysForward,
+			date:     at(2025, time.January, 4),
+			expected: at(2025, time.January, 7),
+		},
+		{
+			name:     "Weekend rolls backward",
+			roll:     models.BusinessDaysBackward,
+			date:     at(2025, time.January, 5),
+			expected: at(2025, time.January, 3),
+		},
+		{
+			name:     "Days are counted in the user's time zone",
+			roll:     models.BusinessDaysForward,
+			date:     time.Date(2025, time.January, 4, 2, 0, 0, 0, time.UTC), // Friday evening in New York
+			loc:      newYork,
+			expected: time.Date(2025, time.January, 4, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			task := &models.Task{BusinessDays: tc.roll, HolidayCalendar: calendar}
+			s.Equal(tc.expected, RollToBusinessDay(task, tc.date, tc.loc))
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestScheduleNextDueDateBusinessDays() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	// A weekly task on Mondays whose second occurrence is a holiday.
+	task := &models.Task{
+		ID:           1,
+		Frequency:    models.Frequency{Type: models.RepeatWeekly},
+		NextDueDate:  ptrTo(at(2025, time.January, 6)),
+		BusinessDays: models.BusinessDaysBackward,
+		HolidayCalendar: &models.HolidayCalendar{
+			Holidays: []models.Holiday{{Date: "2025-01-13"}},
+		},
+	}
+
+	schedule, err := ScheduleCatchUp(task, at(2025, time.January, 6), at(2025, time.January, 6), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 10), *schedule.NextDueDate)
+	s.Equal(at(2025, time.January, 13), *schedule.RolledFrom)
+
+	// Once the roll is kept, the series continues from the holiday rather
+	// than drifting to Fridays.
+	task.Overrides = []models.OccurrenceOverride{*schedule.RollOverride(task.ID)}
+	task.NextDueDate = schedule.NextDueDate
+	next, err := ScheduleNextDueDate(task, at(2025, time.January, 10), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 20), *next)
+
+	task.Overrides = nil
+	task.NextDueDate = ptrTo(at(2025, time.January, 6))
+	occurrences, err := PreviewOccurrences(task, 4, time.UTC)
+	s.Require().NoError(err)
+	s.Equal([]time.Time{
+		at(2025, time.January, 6),
+		at(2025, time.January, 10),
+		at(2025, time.January, 20),
+		at(2025, time.January, 27),
+	}, occurrences)
+
+	// Catching up keeps the rolled occurrence in the series as well.
+	task.CatchUp = models.CatchUpSkipMissed
+	schedule, err = ScheduleCatchUp(task, at(2025, time.January, 21), at(2025, time.January, 21), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 27), *schedule.NextDueDate)
+	s.Nil(schedule.RolledFrom)
+	s.Equal([]time.Time{at(2025, time.January, 10), at(2025, time.January, 20)}, schedule.Missed)
+}
+
+func (s *TaskTestSuite) TestScheduleNextDueDateBusinessDaysSkipsCollapsedOccurrences() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	// Rolling the weekend back onto Friday would repeat the occurrence just
+	// completed, so the daily series resumes on Monday.
+	task := &models.Task{
+		Frequency:    models.Frequency{Type: models.RepeatDaily},
+		NextDueDate:  ptrTo(at(2025, time.January, 3)),
+		BusinessDays: models.BusinessDaysBackward,
+	}
+
+	next, err := ScheduleNextDueDate(task, at(2025, time.January, 3), time.UTC)
+	s.Require().NoError(err)
+	s.Equal(at(2025, time.January, 6), *next)
+}
+
+func (s *TaskTestSuite) TestGetTaskLoadsHolidayCalendar() {
+	ctx := context.Background()
+
+	calendar := &models.HolidayCalendar{
+		Name:     "Public holidays",
+		UserID:   s.testUser.ID,
+		Holidays: []models.Holiday{{Date: "2025-12-25", Name: "Christmas Day"}},
+	}
+	s.Require().NoError(s.DB.Create(calendar).Error)
+
+	task := &models.Task{
+		Title:             "Payroll",
+		CreatedBy:         s.testUser.ID,
+		IsActive:          true,
+		Frequency:         models.Frequency{Type: models.RepeatMonthly},
+		BusinessDays:      models.BusinessDaysForward,
+		HolidayCalendarID: &calendar.ID,
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	retrievedTask, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(models.BusinessDaysForward, retrievedTask.BusinessDays)
+	s.Require().NotNil(retrievedTask.HolidayCalendar)
+	s.Require().Len(retrievedTask.HolidayCalendar.Holidays, 1)
+	s.Equal("2025-12-25", retrievedTask.HolidayCalendar.Holidays[0].Date)
+}
+
+func (s *TaskTestSuite) TestPreviewOccurrences() {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name     string
+		task     models.Task
+		count    int
+		expected []time.Time
+	}{
+		{
+			name:  "Weekly",
+			task:  models.Task{Frequency: models.Frequency{Type: models.RepeatWeekly}},
+			count: 3,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.January, 8),
+				at(2025, time.January, 15),
+			},
+		},
+		{
+			name:     "Once",
+			task:     models.Task{Frequency: models.Frequency{Type: models.RepeatOnce}},
+			count:    3,
+			expected: []time.Time{at(2025, time.January, 1)},
+		},
+		{
+			name: "Stops at the end date",
+			task: models.Task{
+				Frequency: models.Frequency{Type: models.RepeatDaily},
+				EndDate:   ptrTo(at(2025, time.January, 2)),
+			},
+			count: 5,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.January, 2),
+			},
+		},
+		{
+			name: "Rolling tasks assume on-time completion",
+			task: models.Task{
+				Frequency: models.Frequency{Type: models.RepeatCustom, On: models.Interval, Every: 10, Unit: models.Days},
+				IsRolling: true,
+			},
+			count: 3,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.January, 11),
+				at(2025, time.January, 21),
+			},
+		},
+		{
+			name:  "Days of the week",
+			task:  models.Task{Frequency: models.Frequency{Type: models.RepeatCustom, On: models.DaysOfTheWeek, Days: []int32{1, 5}}},
+			count: 4,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.January, 3),
+				at(2025, time.January, 6),
+				at(2025, time.January, 10),
+			},
+		},
+		{
+			name:  "Recurrence rule with a count",
+			task:  models.Task{Frequency: models.Frequency{Type: models.RepeatRRule, RRule: "DTSTART:20250101T090000Z\nRRULE:FREQ=MONTHLY;COUNT=2"}},
+			count: 5,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.February, 1),
+			},
+		},
+		{
+			name: "Stops at the occurrence limit",
+			task: models.Task{
+				Frequency:      models.Frequency{Type: models.RepeatDaily},
+				MaxOccurrences: 2,
+			},
+			count: 5,
+			expected: []time.Time{
+				at(2025, time.January, 1),
+				at(2025, time.January, 2),
+			},
+		},
+		{
+			name: "Due date past the end date",
+			task: models.Task{
+				Frequency: models.Frequency{Type: models.RepeatDaily},
+				EndDate:   ptrTo(at(2024, time.December, 31)),
+			},
+			count:    5,
+			expected: []time.Time{},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			task := tc.task
+			task.NextDueDate = ptrTo(at(2025, time.January, 1))
+
+			occurrences, err := PreviewOccurrences(&task, tc.count, time.UTC)
+			s.Require().NoError(err)
+			s.Equal(tc.expected, occurrences)
+		})
+	}
+}
+
+func (s *TaskTestSuite) TestValidateFrequency() {
+	valid := []models.Frequency{
+		{Type: models.RepeatOnce},
+		{Type: models.RepeatCustom, On: models.Interval, Every: 2, Unit: models.Days},
+		{Type: models.RepeatRRule, RRule: "FREQ=MONTHLY;BYMONTHDAY=1,15"},
+		{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 31, ClampToLastDay: true},
+		{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: models.LastWeekOfMonth, Days: []int32{1}},
+	}
+	for _, freq := range valid {
+		s.NoError(ValidateFrequency(freq))
+	}
+
+	invalid := []models.Frequency{
+		{Type: "fortnightly"},
+		{Type: models.RepeatRRule},
+		{Type: models.RepeatRRule, RRule: "FREQ=WEEKLY;BYDAY=1MO"},
+		{Type: models.RepeatCustom, On: "fortnight"},
+		{Type: models.RepeatCustom, On: models.DayOfTheMonth},
+		{Type: models.RepeatCustom, On: models.DayOfTheMonth, DayOfMonth: 32},
+		{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: 2},
+		{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: 6, Days: []int32{1}},
+		{Type: models.RepeatCustom, On: models.WeekdayOfTheMonth, WeekOfMonth: 1, Days: []int32{7}},
+	}
+	for _, freq := range invalid {
+		s.Error(ValidateFrequency(freq))
+	}
+}
+
+func (s *TaskTestSuite) TestGetRecentActivity() {
+	ctx := context.Background()
+	completedDate := time.Now()
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	// Non-recurring task completed once.
+	taskA := &models.Task{
+		Title:       "Task A",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatOnce},
+	}
+	s.Require().NoError(s.DB.Create(taskA).Error)
+	s.Require().NoError(s.repo.CompleteTask(ctx, taskA, s.testUser.ID, nil, &completedDate))
+
+	// Recurring task completed twice.
+	taskB := &models.Task{
+		Title:       "Task B",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	s.Require().NoError(s.DB.Create(taskB).Error)
+	nextB1 := dueDate.Add(24 * time.Hour)
+	s.Require().NoError(s.repo.CompleteTask(ctx, taskB, s.testUser.ID, &nextB1, &completedDate))
+	taskB.NextDueDate = &nextB1
+	nextB2 := nextB1.Add(24 * time.Hour)
+	s.Require().NoError(s.repo.CompleteTask(ctx, taskB, s.testUser.ID, &nextB2, &completedDate))
+
+	// Another user's activity must not leak.
+	anotherUser := &models.User{}
+	s.Require().NoError(s.DB.Create(anotherUser).Error)
+	otherTask := &models.Task{Title: "Other", CreatedBy: anotherUser.ID, IsActive: true, Frequency: models.Frequency{Type: models.RepeatOnce}}
+	s.Require().NoError(s.DB.Create(otherTask).Error)
+	s.Require().NoError(s.repo.CompleteTask(ctx, otherTask, anotherUser.ID, nil, &completedDate))
+
+	entries, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, nil, 20)
+	s.Require().NoError(err)
+	s.Require().Len(entries, 3)
+
+	// Reverse-chronological: taskB second, taskB first, taskA.
+	s.Equal(models.ActivityHistory, entries[0].Type)
+	s.False(entries[0].RecordedAt.IsZero())
+	s.Equal("Task B", entries[0].TaskTitle)
+	s.True(entries[0].IsLatest)
+	s.Equal(1, entries[0].UndoSteps)
+	s.Equal("Task B", entries[1].TaskTitle)
+	s.False(entries[1].IsLatest)
+	s.Equal(2, entries[1].UndoSteps)
+	s.Equal("Task A", entries[2].TaskTitle)
+	s.True(entries[2].IsLatest)
+	s.Equal(1, entries[2].UndoSteps)
+
+	// Cursor pagination returns the entries following the given one.
+	cursor := &models.ActivityRef{Type: models.ActivityHistory, ID: entries[1].ID}
+	older, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, cursor, 20)
+	s.Require().NoError(err)
+	s.Require().Len(older, 1)
+	s.Equal("Task A", older[0].TaskTitle)
+
+	// Limit is respected.
+	limited, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, nil, 1)
+	s.Require().NoError(err)
+	s.Require().Len(limited, 1)
+	s.Equal(entries[0].ID, limited[0].ID)
+}
+
+func (s *TaskTestSuite) TestGetRecentActivityMergesAudit() {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	task := &models.Task{
+		Title:       "Sweep",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, nil))
+
+	// Edit, complete, then edit again.
+	edit := func(title string) {
+		before := *task
+		task.Title = title
+		s.Require().NoError(s.repo.UpsertTask(ctx, task, nil, &models.TaskAudit{
+			UserID:  s.testUser.ID,
+			Changes: DiffTask(&before, task),
+		}))
+	}
+	edit("Sweep floor")
+	completedDate := time.Now()
+	nextDueDate := dueDate.Add(24 * time.Hour)
+	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, &completedDate))
+	task, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	edit("Sweep the floor")
+
+	entries, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, nil, 20)
+	s.Require().NoError(err)
+	s.Require().Len(entries, 3)
+
+	s.Equal(models.ActivityAudit, entries[0].Type)
+	s.Equal("Sweep the floor", entries[0].TaskTitle)
+	s.Require().Len(entries[0].Changes, 1)
+	s.JSONEq(`"Sweep the floor"`, string(entries[0].Changes[0].After))
+	s.Equal(models.ActivityHistory, entries[1].Type)
+	s.True(entries[1].IsLatest)
+	s.Empty(entries[1].Changes)
+	s.Equal(models.ActivityAudit, entries[2].Type)
+	s.JSONEq(`"Sweep floor"`, string(entries[2].Changes[0].After))
+
+	// Paging one entry at a time walks the same feed.
+	var paged []*models.ActivityEntry
+	var cursor *models.ActivityRef
+	for {
+		page, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, cursor, 1)
+		s.Require().NoError(err)
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, page...)
+		cursor = &models.ActivityRef{Type: page[0].Type, ID: page[0].ID}
+	}
+	s.Require().Len(paged, 3)
+	for i := range entries {
+		s.Equal(entries[i].Type, paged[i].Type)
+		s.Equal(entries[i].ID, paged[i].ID)
+	}
 
-	// Limit is respected.
-	limited, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, 0, 1)
+	// A cursor naming an entry the user does not have is rejected.
+	_, err = s.repo.GetRecentActivity(ctx, s.testUser.ID, &models.ActivityRef{Type: models.ActivityAudit, ID: 9999}, 20)
+	s.ErrorIs(err, ErrInvalidCursor)
+
+	// Entries of tasks in the trash drop out of the feed.
+	s.Require().NoError(s.repo.DeleteTask(ctx, task.ID))
+	entries, err = s.repo.GetRecentActivity(ctx, s.testUser.ID, nil, 20)
 	s.Require().NoError(err)
-	s.Require().Len(limited, 1)
-	s.Equal(entries[0].ID, limited[0].ID)
+	s.Empty(entries)
 }
 
 func (s *TaskTestSuite) TestGetRecentActivityIncludesSkips() {
@@ -807,14 +2405,14 @@ func (s *TaskTestSuite) TestGetRecentActivityIncludesSkips() {
 	nextDueDate := dueDate.Add(24 * time.Hour)
 	s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, &nextDueDate, nil))
 
-	entries, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, 0, 20)
+	entries, err := s.repo.GetRecentActivity(ctx, s.testUser.ID, nil, 20)
 	s.Require().NoError(err)
 	s.Require().Len(entries, 1)
 	s.Nil(entries[0].CompletedDate)
 	s.True(entries[0].IsLatest)
 }
 
-func (s *TaskTestSuite) TestGetTasksDueBefore() {
+func (s *TaskTestSuite) TestListTasksDueBefore() {
 	ctx := context.Background()
 
 	now := time.Now().UTC()
@@ -883,7 +2481,7 @@ func (s *TaskTestSuite) TestGetTasksDueBefore() {
 	err = s.DB.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?)", tasks[1].ID, label.ID).Error
 	s.Require().NoError(err)
 
-	result, err := s.repo.GetTasksDueBefore(ctx, s.testUser.ID, cutoff)
+	result, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{DueBefore: &cutoff})
 	s.Require().NoError(err)
 	s.Require().Len(result, 2)
 
@@ -894,9 +2492,20 @@ func (s *TaskTestSuite) TestGetTasksDueBefore() {
 	// Labels are preloaded
 	s.Require().Len(result[1].Labels, 1)
 	s.Equal("Urgent", result[1].Labels[0].Name)
+
+	// Due filters combine into a range
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{DueAfter: &now, DueBefore: &cutoff})
+	s.Require().NoError(err)
+	s.Require().Len(result, 1)
+	s.Equal("Soon Task", result[0].Title)
+
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Overdue: true})
+	s.Require().NoError(err)
+	s.Require().Len(result, 1)
+	s.Equal("Past Task", result[0].Title)
 }
 
-func (s *TaskTestSuite) TestGetTasksByLabel() {
+func (s *TaskTestSuite) TestListTasksByLabel() {
 	ctx := context.Background()
 
 	now := time.Now().UTC()
@@ -983,7 +2592,7 @@ func (s *TaskTestSuite) TestGetTasksByLabel() {
 	err = s.DB.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?)", otherUserTask.ID, label.ID).Error
 	s.Require().NoError(err)
 
-	result, err := s.repo.GetTasksByLabel(ctx, s.testUser.ID, label.ID)
+	result, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Labels: []int{label.ID}})
 	s.Require().NoError(err)
 	s.Require().Len(result, 2)
 
@@ -993,9 +2602,22 @@ func (s *TaskTestSuite) TestGetTasksByLabel() {
 
 	// All labels are preloaded (not just the filtered one)
 	s.Require().Len(result[1].Labels, 2)
+
+	// Several labels match tasks carrying any of them, or all of them
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Labels: []int{label.ID, otherLabel.ID}})
+	s.Require().NoError(err)
+	s.Require().Len(result, 3)
+
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{
+		Labels:     []int{label.ID, otherLabel.ID, label.ID},
+		LabelMatch: models.LabelMatchAll,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(result, 1)
+	s.Equal("Work Task A", result[0].Title)
 }
 
-func (s *TaskTestSuite) TestSearchTasksByTitle() {
+func (s *TaskTestSuite) TestListTasksSearch() {
 	ctx := context.Background()
 
 	now := time.Now().UTC()
@@ -1052,14 +2674,717 @@ func (s *TaskTestSuite) TestSearchTasksByTitle() {
 	s.Require().NoError(s.DB.Create(otherUserTask).Error)
 
 	// Case-insensitive, matches substring, only active tasks for this user
-	result, err := s.repo.SearchTasksByTitle(ctx, s.testUser.ID, "grocer")
+	result, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Search: parseSearch(s.T(), "grocer")})
 	s.Require().NoError(err)
 	s.Require().Len(result, 2)
 	s.Equal("Sort Groceries in pantry", result[0].Title)
 	s.Equal("Buy groceries", result[1].Title)
 
 	// LIKE wildcards in query are treated literally
-	resultLiteral, err := s.repo.SearchTasksByTitle(ctx, s.testUser.ID, "%")
+	resultLiteral, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Search: parseSearch(s.T(), "%")})
 	s.Require().NoError(err)
 	s.Require().Len(resultLiteral, 0)
+
+	// Notes are only searched on request; tasks without notes still match by title
+	shop := &models.Task{
+		Title:       "Weekly shop",
+		Notes:       "Pick up **groceries** for the week",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: ptrTo(now.Add(72 * time.Hour)),
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatOnce},
+	}
+	s.Require().NoError(s.DB.Create(shop).Error)
+	s.Require().NoError(s.DB.Model(&models.Task{}).Where("id = ?", groceries.ID).Update("notes", nil).Error)
+
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Search: parseSearch(s.T(), "grocer")})
+	s.Require().NoError(err)
+	s.Require().Len(result, 2)
+
+	result, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Search: parseSearch(s.T(), "grocer"), SearchNotes: true})
+	s.Require().NoError(err)
+	s.Require().Len(result, 3)
+	s.Equal("Buy groceries", result[1].Title)
+	s.Empty(result[1].Notes)
+	s.Equal("Weekly shop", result[2].Title)
+}
+
+func (s *TaskTestSuite) TestListTasksFilters() {
+	ctx := context.Background()
+
+	tasks := []*models.Task{
+		{Title: "Water plants", Frequency: models.Frequency{Type: models.RepeatDaily}, Notification: models.NotificationTriggerOptions{Enabled: true, DueDate: true}},
+		{Title: "Renew passport", Frequency: models.Frequency{Type: models.RepeatOnce}, Notification: models.NotificationTriggerOptions{Enabled: true, DueDate: true}},
+		{Title: "Clean gutters", Frequency: models.Frequency{Type: models.RepeatYearly}},
+	}
+	for _, task := range tasks {
+		task.CreatedBy = s.testUser.ID
+		task.IsActive = true
+		s.Require().NoError(s.DB.Create(task).Error)
+	}
+
+	titles := func(query models.TaskQuery) []string {
+		result, _, err := s.repo.ListTasks(ctx, s.testUser.ID, query)
+		s.Require().NoError(err)
+		var out []string
+		for _, task := range result {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	yes, no := true, false
+	s.Equal([]string{"Water plants", "Clean gutters"}, titles(models.TaskQuery{Recurring: &yes}))
+	s.Equal([]string{"Renew passport"}, titles(models.TaskQuery{Recurring: &no}))
+	s.Equal([]string{"Water plants", "Renew passport"}, titles(models.TaskQuery{HasNotifications: &yes}))
+	s.Equal([]string{"Water plants"}, titles(models.TaskQuery{HasNotifications: &yes, Recurring: &yes}))
+}
+
+func (s *TaskTestSuite) TestListTasksPagination() {
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	var dueDates []*time.Time
+	for i := range 3 {
+		dueDates = append(dueDates, ptrTo(now.Add(time.Duration(i)*time.Hour)))
+	}
+	// Two tasks share a due date and two have none, so that ties and NULLs
+	// have to be resumed correctly.
+	dueDates = append(dueDates, dueDates[1], nil, nil)
+
+	for i, due := range dueDates {
+		task := &models.Task{
+			Title:       fmt.Sprintf("Task %d", i),
+			Priority:    models.Priority(i % 2),
+			CreatedBy:   s.testUser.ID,
+			NextDueDate: due,
+			IsActive:    true,
+			Frequency:   models.Frequency{Type: models.RepeatOnce},
+		}
+		s.Require().NoError(s.DB.Create(task).Error)
+	}
+
+	for _, sorts := range [][]models.TaskSort{
+		nil,
+		{{Key: models.TaskSortDue, Descending: true}},
+		{{Key: models.TaskSortPriority, Descending: true}, {Key: models.TaskSortDue}},
+	} {
+		all, next, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Sort: sorts})
+		s.Require().NoError(err)
+		s.Require().Len(all, len(dueDates))
+		s.Zero(next)
+
+		var want []int
+		for _, task := range all {
+			want = append(want, task.ID)
+		}
+
+		for _, limit := range []int{1, 4} {
+			var got []int
+			afterID := 0
+			for {
+				page, next, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Sort: sorts, AfterID: afterID, Limit: limit})
+				s.Require().NoError(err)
+				s.Require().LessOrEqual(len(page), limit)
+				for _, task := range page {
+					got = append(got, task.ID)
+				}
+				if next == 0 {
+					break
+				}
+				afterID = next
+			}
+			s.Equal(want, got, "sorts %v, limit %d", sorts, limit)
+		}
+	}
+
+	_, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{AfterID: 999, Limit: 2})
+	s.ErrorIs(err, ErrInvalidCursor)
+}
+
+func (s *TaskTestSuite) TestListTasksSearchLanguage() {
+	ctx := context.Background()
+
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	s.Require().NoError(err)
+	now := time.Now().In(loc)
+	// Due dates are stored in UTC, as the service does.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, loc).UTC()
+
+	home := &models.Label{Name: "Home", Color: "#00FF00", CreatedBy: s.testUser.ID}
+	s.Require().NoError(s.DB.Create(home).Error)
+	work := &models.Label{Name: "Work", Color: "#0000FF", CreatedBy: s.testUser.ID}
+	s.Require().NoError(s.DB.Create(work).Error)
+
+	tasks := []*models.Task{
+		{Title: "Replace furnace filter", Notes: "Part FX-220", NextDueDate: ptrTo(today.AddDate(0, 0, -2)), Frequency: models.Frequency{Type: models.RepeatMonthly}, Labels: []models.Label{*home}},
+		{Title: "Paint the fence", Priority: models.PriorityHigh, NextDueDate: ptrTo(today.AddDate(0, 0, 3)), Frequency: models.Frequency{Type: models.RepeatOnce}, Labels: []models.Label{*home, *work}},
+		{Title: "File expenses", NextDueDate: ptrTo(today.AddDate(0, 0, 10)), Frequency: models.Frequency{Type: models.RepeatWeekly}, Labels: []models.Label{*work}},
+		{Title: "Someday: learn the fence trade", Frequency: models.Frequency{Type: models.RepeatOnce}},
+	}
+	for _, task := range tasks {
+		task.CreatedBy = s.testUser.ID
+		task.IsActive = true
+		s.Require().NoError(s.DB.Create(task).Error)
+	}
+
+	titles := func(raw string) []string {
+		result, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Search: parseSearch(s.T(), raw), SearchNotes: true, Location: loc})
+		s.Require().NoError(err)
+		var out []string
+		for _, task := range result {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	s.Equal([]string{"Replace furnace filter", "Paint the fence"}, titles("label:home"))
+	s.Equal([]string{"Replace furnace filter"}, titles("label:HOME -label:work"))
+	s.Equal([]string{"Replace furnace filter", "Paint the fence"}, titles("due:<7d"))
+	s.Equal([]string{"Paint the fence"}, titles("due:3d"))
+	s.Equal([]string{"File expenses", "Someday: learn the fence trade"}, titles("-due:<=7d"))
+	s.Equal([]string{"Someday: learn the fence trade"}, titles("due:none"))
+	s.Equal([]string{"Replace furnace filter"}, titles("overdue is:recurring"))
+	s.Equal([]string{"Replace furnace filter"}, titles("fx-220"))
+	s.Equal([]string{"Paint the fence", "Someday: learn the fence trade"}, titles("fence"))
+	s.Equal([]string{"Paint the fence"}, titles(`"the fence" priority:>=high`))
+	s.Equal([]string{"Replace furnace filter", "File expenses", "Someday: learn the fence trade"}, titles("-priority:high"))
+	s.Equal([]string{"Replace furnace filter"}, titles("has:notes"))
+}
+
+func parseSearch(t *testing.T, raw string) *search.Query {
+	t.Helper()
+	query, err := search.Parse(raw)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", raw, err)
+	}
+	return query
+}
+
+func ptrTo(t time.Time) *time.Time {
+	return &t
+}
+
+func (s *TaskTestSuite) TestSearchTasks() {
+	ctx := context.Background()
+
+	otherUser := &models.User{ID: 2, CreatedAt: time.Now()}
+	s.Require().NoError(s.DB.Create(otherUser).Error)
+
+	label := &models.Label{Name: "Kitchen", Color: "#00FF00", CreatedBy: s.testUser.ID}
+	s.Require().NoError(s.DB.Create(label).Error)
+
+	create := func(title string, userID int) *models.Task {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: userID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+		}
+		_, err := s.repo.CreateTask(ctx, task)
+		s.Require().NoError(err)
+		return task
+	}
+
+	cleanKitchen := create("Clean the kitchen", s.testUser.ID)
+	groceries := create("Buy groceries", s.testUser.ID)
+	done := create("Kitchen done", s.testUser.ID)
+	s.Require().NoError(s.DB.Model(done).Update("is_active", false).Error)
+	create("Kitchen of someone else", otherUser.ID)
+
+	s.Require().NoError(s.DB.Create(&models.TaskLabel{TaskID: groceries.ID, LabelID: label.ID}).Error)
+	s.Require().NoError(s.repo.search.Sync(s.DB, groceries.ID))
+
+	results, err := s.repo.SearchTasks(ctx, s.testUser.ID, "kitch", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	s.Equal(cleanKitchen.ID, results[0].Task.ID, "title matches rank above label matches")
+	s.Equal("Clean the <mark>kitchen</mark>", results[0].Snippet)
+	s.Empty(results[0].MatchedLabels)
+	s.Equal(groceries.ID, results[1].Task.ID)
+	s.Equal("Buy groceries", results[1].Snippet)
+	s.Equal([]string{"<mark>Kitchen</mark>"}, results[1].MatchedLabels)
+	s.Greater(results[0].Score, results[1].Score)
+
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "CLEAN, kitchen!", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal(cleanKitchen.ID, results[0].Task.ID)
+
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "kitchen", 1)
+	s.Require().NoError(err)
+	s.Len(results, 1)
+
+	cleanKitchen.Title = "Clean the <b>bathroom</b>"
+	s.Require().NoError(s.repo.UpsertTask(ctx, cleanKitchen, nil, nil))
+
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "kitchen", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal(groceries.ID, results[0].Task.ID)
+
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "bath", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal("Clean the &lt;b&gt;<mark>bathroom</mark>&lt;/b&gt;", results[0].Snippet)
+
+	s.Require().NoError(s.repo.DeleteTask(ctx, cleanKitchen.ID))
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "bathroom", 10)
+	s.Require().NoError(err)
+	s.Empty(results)
+
+	var indexed int64
+	s.Require().NoError(s.DB.Table("task_search").Where("rowid = ?", cleanKitchen.ID).Count(&indexed).Error)
+	s.Zero(indexed)
+}
+
+func (s *TaskTestSuite) TestArchiveAndRestoreTasks() {
+	ctx := context.Background()
+
+	completeOnce := func(title string) *models.Task {
+		due := time.Now().UTC().Add(-time.Hour)
+		task := &models.Task{
+			Title:       title,
+			CreatedBy:   s.testUser.ID,
+			NextDueDate: &due,
+			IsActive:    true,
+			Frequency:   models.Frequency{Type: models.RepeatOnce},
+		}
+		_, err := s.repo.CreateTask(ctx, task)
+		s.Require().NoError(err)
+
+		completed := time.Now().UTC()
+		s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, nil, &completed))
+		return task
+	}
+
+	passport := completeOnce("Renew passport")
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, passport.ID, &models.ChecklistItem{Title: "Photos"}, nil))
+	s.Require().NoError(s.DB.Model(&models.ChecklistItem{}).Where("task_id = ?", passport.ID).Update("is_done", true).Error)
+	car := completeOnce("Sell car")
+	s.Require().NoError(s.DB.Model(car).Update("archived_at", time.Now().UTC().Add(time.Minute)).Error)
+	later := time.Now().UTC().Add(time.Hour)
+	active := &models.Task{
+		Title:       "Still open",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &later,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatOnce},
+	}
+	_, err := s.repo.CreateTask(ctx, active)
+	s.Require().NoError(err)
+
+	archived, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{
+		Archived: true,
+		Sort:     []models.TaskSort{{Key: models.TaskSortArchived, Descending: true}},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(archived, 2)
+	s.Equal(car.ID, archived[0].ID)
+	s.Equal(passport.ID, archived[1].ID)
+	s.NotNil(archived[1].ArchivedAt)
+
+	query, err := search.Parse("passport")
+	s.Require().NoError(err)
+	archived, _, err = s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Archived: true, Search: query})
+	s.Require().NoError(err)
+	s.Require().Len(archived, 1)
+	s.Equal(passport.ID, archived[0].ID)
+
+	tasks, err := s.repo.GetTasks(ctx, s.testUser.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Equal(active.ID, tasks[0].ID)
+
+	due := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+	s.Require().NoError(s.repo.RestoreTask(ctx, passport.ID, due))
+
+	restored, err := s.repo.GetTask(ctx, passport.ID)
+	s.Require().NoError(err)
+	s.True(restored.IsActive)
+	s.Nil(restored.ArchivedAt)
+	s.Require().NotNil(restored.NextDueDate)
+	s.True(due.Equal(*restored.NextDueDate))
+	s.Require().Len(restored.Checklist, 1)
+	s.False(restored.Checklist[0].IsDone)
+
+	err = s.repo.RestoreTask(ctx, active.ID, due)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	s.Require().NoError(s.repo.RevertActivity(ctx, car.ID, 0, 1))
+	reverted, err := s.repo.GetTask(ctx, car.ID)
+	s.Require().NoError(err)
+	s.True(reverted.IsActive)
+	s.Nil(reverted.ArchivedAt)
+}
+
+func (s *TaskTestSuite) TestPurgeArchivedTasks() {
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	create := func(title string) *models.Task {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: s.testUser.ID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+		}
+		_, err := s.repo.CreateTask(ctx, task)
+		s.Require().NoError(err)
+		return task
+	}
+
+	old := create("Archived long ago")
+	recent := create("Archived recently")
+	active := create("Active")
+	s.Require().NoError(s.DB.Model(old).Updates(map[string]interface{}{"is_active": false, "archived_at": now.Add(-48 * time.Hour)}).Error)
+	s.Require().NoError(s.DB.Model(recent).Updates(map[string]interface{}{"is_active": false, "archived_at": now.Add(-time.Hour)}).Error)
+
+	purged, err := s.repo.PurgeArchivedTasks(ctx, now.Add(-24*time.Hour))
+	s.Require().NoError(err)
+	s.Equal(1, purged)
+
+	_, err = s.repo.GetTask(ctx, old.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+	_, err = s.repo.GetTask(ctx, recent.ID)
+	s.NoError(err)
+	_, err = s.repo.GetTask(ctx, active.ID)
+	s.NoError(err)
+
+	var count int64
+	s.Require().NoError(s.DB.Unscoped().Model(&models.Task{}).Where("id = ?", old.ID).Count(&count).Error)
+	s.Zero(count)
+
+	var indexed int64
+	s.Require().NoError(s.DB.Table("task_search").Where("rowid = ?", old.ID).Count(&indexed).Error)
+	s.Zero(indexed)
+}
+
+func (s *TaskTestSuite) TestTrashAndRestoreTasks() {
+	ctx := context.Background()
+
+	create := func(title string) *models.Task {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: s.testUser.ID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+		}
+		_, err := s.repo.CreateTask(ctx, task)
+		s.Require().NoError(err)
+		return task
+	}
+
+	paint := create("Paint fence")
+	fence := create("Build fence")
+	kept := create("Water plants")
+	s.Require().NoError(s.repo.AddDependency(ctx, s.testUser.ID, fence.ID, paint.ID))
+	s.Require().NoError(s.DB.Create(&models.Notification{
+		TaskID:       paint.ID,
+		UserID:       s.testUser.ID,
+		Text:         "Paint fence is due",
+		Type:         models.NotificationType("due"),
+		ScheduledFor: time.Now().UTC(),
+	}).Error)
+
+	s.Require().NoError(s.repo.DeleteTask(ctx, paint.ID))
+	s.Require().NoError(s.repo.DeleteTask(ctx, kept.ID))
+	s.Require().NoError(s.DB.Unscoped().Model(&models.Task{}).Where("id = ?", paint.ID).
+		Update("deleted_at", time.Now().UTC().Add(time.Minute)).Error)
+
+	_, err := s.repo.GetTask(ctx, paint.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	var notifications int64
+	s.Require().NoError(s.DB.Model(&models.Notification{}).Where("task_id = ?", paint.ID).Count(&notifications).Error)
+	s.Zero(notifications)
+
+	tasks, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{})
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Equal(fence.ID, tasks[0].ID)
+	s.False(tasks[0].Blocked)
+
+	results, err := s.repo.SearchTasks(ctx, s.testUser.ID, "paint", 10)
+	s.Require().NoError(err)
+	s.Empty(results)
+
+	trash, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{
+		Deleted: true,
+		Sort:    []models.TaskSort{{Key: models.TaskSortDeleted, Descending: true}},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(trash, 2)
+	s.Equal(paint.ID, trash[0].ID)
+	s.Equal(kept.ID, trash[1].ID)
+	s.True(trash[0].DeletedAt.Valid)
+
+	deleted, err := s.repo.GetDeletedTask(ctx, paint.ID)
+	s.Require().NoError(err)
+	s.Equal("Paint fence", deleted.Title)
+	_, err = s.repo.GetDeletedTask(ctx, fence.ID)
+	s.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	s.Require().NoError(s.repo.UndeleteTask(ctx, paint.ID))
+	s.ErrorIs(s.repo.UndeleteTask(ctx, paint.ID), gorm.ErrRecordNotFound)
+
+	restored, err := s.repo.GetTask(ctx, paint.ID)
+	s.Require().NoError(err)
+	s.False(restored.DeletedAt.Valid)
+
+	results, err = s.repo.SearchTasks(ctx, s.testUser.ID, "paint", 10)
+	s.Require().NoError(err)
+	s.Require().Len(results, 1)
+	s.Equal(paint.ID, results[0].Task.ID)
+
+	blocked, err := s.repo.GetTask(ctx, fence.ID)
+	s.Require().NoError(err)
+	s.True(blocked.Blocked)
+}
+
+func (s *TaskTestSuite) TestPurgeDeletedTasks() {
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	create := func(title string) *models.Task {
+		task := &models.Task{
+			Title:     title,
+			CreatedBy: s.testUser.ID,
+			IsActive:  true,
+			Frequency: models.Frequency{Type: models.RepeatOnce},
+		}
+		_, err := s.repo.CreateTask(ctx, task)
+		s.Require().NoError(err)
+		return task
+	}
+
+	old := create("Deleted long ago")
+	recent := create("Deleted recently")
+	active := create("Active")
+	s.Require().NoError(s.repo.DeleteTask(ctx, old.ID))
+	s.Require().NoError(s.repo.DeleteTask(ctx, recent.ID))
+	s.Require().NoError(s.DB.Unscoped().Model(old).Update("deleted_at", now.Add(-48*time.Hour)).Error)
+
+	purged, err := s.repo.PurgeDeletedTasks(ctx, now.Add(-24*time.Hour))
+	s.Require().NoError(err)
+	s.Equal(1, purged)
+
+	var remaining []int
+	s.Require().NoError(s.DB.Unscoped().Model(&models.Task{}).Order("id ASC").Pluck("id", &remaining).Error)
+	s.Equal([]int{recent.ID, active.ID}, remaining)
+}
+
+func (s *TaskTestSuite) TestCorrectActivity() {
+	ctx := context.Background()
+
+	dueDate := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{
+		Title:       "Water plants",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		IsRolling:   true,
+		Frequency:   models.Frequency{Type: models.RepeatCustom, On: models.Interval, Every: 3, Unit: models.Days},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+
+	complete := func(completedDate time.Time) {
+		next, err := ScheduleNextDueDate(task, completedDate, time.UTC)
+		s.Require().NoError(err)
+		s.Require().NoError(s.repo.CompleteTask(ctx, task, s.testUser.ID, next, &completedDate))
+		task.NextDueDate = next
+	}
+	complete(dueDate)
+	complete(dueDate.Add(72 * time.Hour))
+
+	history, err := s.repo.GetTaskHistory(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	first, latest := history[1], history[0]
+
+	// The latest completion was really a day later.
+	corrected := dueDate.Add(96 * time.Hour)
+	latest.CompletedDate = &corrected
+	next, err := ScheduleFromHistory(task, history, time.UTC)
+	s.Require().NoError(err)
+	s.Require().NotNil(next)
+	s.True(dueDate.Add(168 * time.Hour).Equal(*next))
+	s.Require().NoError(s.repo.UpdateActivity(ctx, task.ID, latest.ID, corrected, next))
+
+	updated, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.True(next.Equal(*updated.NextDueDate))
+	history, err = s.repo.GetTaskHistory(ctx, task.ID)
+	s.Require().NoError(err)
+	s.True(corrected.Equal(*history[0].CompletedDate))
+
+	// Correcting or deleting an older entry leaves the schedule alone, even
+	// a due date moved by hand since the latest completion.
+	moved := next.Add(24 * time.Hour)
+	s.Require().NoError(s.DB.Model(&models.Task{}).Where("id = ?", task.ID).Update("next_due_date", moved).Error)
+	s.Require().NoError(s.repo.UpdateActivity(ctx, task.ID, first.ID, dueDate.Add(time.Hour), next))
+	s.Require().NoError(s.repo.DeleteActivity(ctx, task.ID, first.ID))
+	history, err = s.repo.GetTaskHistory(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 1)
+	s.Equal(latest.ID, history[0].ID)
+	updated, err = s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.True(moved.Equal(*updated.NextDueDate))
+
+	s.ErrorIs(s.repo.DeleteActivity(ctx, task.ID, first.ID), gorm.ErrRecordNotFound)
+	s.ErrorIs(s.repo.UpdateActivity(ctx, 99999, latest.ID, corrected, next), gorm.ErrRecordNotFound)
+
+	// A task left without a next due date is archived.
+	s.Require().NoError(s.repo.UpdateActivity(ctx, task.ID, latest.ID, corrected, nil))
+	archived, _, err := s.repo.ListTasks(ctx, s.testUser.ID, models.TaskQuery{Archived: true})
+	s.Require().NoError(err)
+	s.Require().Len(archived, 1)
+	s.Equal(task.ID, archived[0].ID)
+	s.Require().NotNil(archived[0].ArchivedAt)
+	archivedAt := *archived[0].ArchivedAt
+
+	// An archived task stays archived when its history is corrected.
+	s.Require().NoError(s.repo.UpdateActivity(ctx, task.ID, latest.ID, corrected, next))
+	updated, err = s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.False(updated.IsActive)
+	s.Nil(updated.NextDueDate)
+	s.Require().NotNil(updated.ArchivedAt)
+	s.True(archivedAt.Equal(*updated.ArchivedAt))
+}
+
+func (s *TaskTestSuite) TestScheduleFromHistory() {
+	dueDate := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	completed := dueDate.Add(time.Hour)
+	task := &models.Task{
+		NextDueDate:    &dueDate,
+		MaxOccurrences: 3,
+		Frequency:      models.Frequency{Type: models.RepeatDaily},
+	}
+
+	history := []*models.TaskHistory{
+		{ID: 1, DueDate: &dueDate, CompletedDate: &completed},
+	}
+	next, err := ScheduleFromHistory(task, history, time.UTC)
+	s.Require().NoError(err)
+	s.Require().NotNil(next)
+	s.True(dueDate.AddDate(0, 0, 1).Equal(*next))
+
+	// A skipped occurrence continues the series just the same.
+	skipped := dueDate.AddDate(0, 0, 1)
+	history = append(history, &models.TaskHistory{ID: 2, DueDate: &skipped})
+	next, err = ScheduleFromHistory(task, history, time.UTC)
+	s.Require().NoError(err)
+	s.Require().NotNil(next)
+	s.True(dueDate.AddDate(0, 0, 2).Equal(*next))
+
+	// The last of the MaxOccurrences ends the series.
+	last := dueDate.AddDate(0, 0, 2)
+	history = append(history, &models.TaskHistory{ID: 3, DueDate: &last, CompletedDate: &last})
+	next, err = ScheduleFromHistory(task, history, time.UTC)
+	s.Require().NoError(err)
+	s.Nil(next)
+
+	_, err = ScheduleFromHistory(task, nil, time.UTC)
+	s.Error(err)
+}
+
+func (s *TaskTestSuite) TestScheduleBackfill() {
+	dueDate := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := dueDate.AddDate(0, 0, 4).Add(time.Hour)
+	completions := []time.Time{
+		dueDate.Add(2 * time.Hour),
+		dueDate.AddDate(0, 0, 2).Add(3 * time.Hour),
+	}
+
+	// A fixed daily task closes its occurrences in order and catches up to
+	// now from the latest.
+	fixed := &models.Task{
+		NextDueDate: &dueDate,
+		CatchUp:     models.CatchUpSkipMissed,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	backfill, err := ScheduleBackfill(fixed, 0, completions, now, time.UTC)
+	s.Require().NoError(err)
+	s.Require().Len(backfill.History, 5)
+	s.True(dueDate.Equal(*backfill.History[0].DueDate))
+	s.True(completions[0].Equal(*backfill.History[0].CompletedDate))
+	s.True(dueDate.AddDate(0, 0, 1).Equal(*backfill.History[1].DueDate))
+	s.True(completions[1].Equal(*backfill.History[1].CompletedDate))
+	s.Nil(backfill.History[2].CompletedDate)
+	s.True(dueDate.AddDate(0, 0, 2).Equal(*backfill.History[2].DueDate))
+	s.True(dueDate.AddDate(0, 0, 4).Equal(*backfill.History[4].DueDate))
+	s.Require().NotNil(backfill.NextDueDate)
+	s.True(dueDate.AddDate(0, 0, 5).Equal(*backfill.NextDueDate))
+
+	// A rolling task continues from each completion.
+	rolling := &models.Task{
+		NextDueDate: &dueDate,
+		IsRolling:   true,
+		Frequency:   models.Frequency{Type: models.RepeatCustom, On: models.Interval, Every: 3, Unit: models.Days},
+	}
+	backfill, err = ScheduleBackfill(rolling, 0, completions, now, time.UTC)
+	s.Require().NoError(err)
+	s.Require().Len(backfill.History, 2)
+	s.True(completions[0].AddDate(0, 0, 3).Equal(*backfill.History[1].DueDate))
+	s.Require().NotNil(backfill.NextDueDate)
+	s.True(completions[1].AddDate(0, 0, 3).Equal(*backfill.NextDueDate))
+
+	// Skipped occurrences give way to completions within MaxOccurrences.
+	fixed.MaxOccurrences = 4
+	backfill, err = ScheduleBackfill(fixed, 1, completions, now, time.UTC)
+	s.Require().NoError(err)
+	s.Require().Len(backfill.History, 3)
+	s.NotNil(backfill.History[1].CompletedDate)
+	s.Nil(backfill.NextDueDate)
+
+	_, err = ScheduleBackfill(fixed, 3, completions, now, time.UTC)
+	s.ErrorIs(err, ErrOccurrencesUsedUp)
+
+	once := &models.Task{NextDueDate: &dueDate, Frequency: models.Frequency{Type: models.RepeatOnce}}
+	_, err = ScheduleBackfill(once, 0, completions, now, time.UTC)
+	s.ErrorIs(err, ErrSeriesEnded)
+
+	// The end date stops the series before the second completion.
+	endDate := dueDate.Add(time.Hour)
+	ending := &models.Task{
+		NextDueDate: &dueDate,
+		EndDate:     &endDate,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	_, err = ScheduleBackfill(ending, 0, completions, now, time.UTC)
+	s.ErrorIs(err, ErrSeriesEnded)
+}
+
+func (s *TaskTestSuite) TestBackfillTask() {
+	ctx := context.Background()
+
+	dueDate := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := &models.Task{
+		Title:       "Take out bins",
+		CreatedBy:   s.testUser.ID,
+		NextDueDate: &dueDate,
+		IsActive:    true,
+		Frequency:   models.Frequency{Type: models.RepeatDaily},
+	}
+	s.Require().NoError(s.DB.Create(task).Error)
+	s.Require().NoError(s.repo.AddChecklistItem(ctx, task.ID, &models.ChecklistItem{Title: "Recycling"}, nil))
+	s.Require().NoError(s.DB.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Update("is_done", true).Error)
+
+	completions := []time.Time{dueDate, dueDate.AddDate(0, 0, 1)}
+	backfill, err := ScheduleBackfill(task, 0, completions, dueDate.AddDate(0, 0, 1), time.UTC)
+	s.Require().NoError(err)
+	s.Require().NoError(s.repo.BackfillTask(ctx, task.ID, backfill))
+
+	history, err := s.repo.GetTaskHistory(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+
+	updated, err := s.repo.GetTask(ctx, task.ID)
+	s.Require().NoError(err)
+	s.True(updated.IsActive)
+	s.True(dueDate.AddDate(0, 0, 2).Equal(*updated.NextDueDate))
+	s.Require().Len(updated.Checklist, 1)
+	s.False(updated.Checklist[0].IsDone)
 }
@@ -19,6 +19,7 @@ type IUserRepo interface {
 	FindByEntraID(c context.Context, directoryID string, objectID string) (*models.User, error)
 	EnsureUser(c context.Context, directoryID string, objectID string) (*models.User, error)
 	UpdateNotificationSettings(c context.Context, userID int, provider models.NotificationProvider, triggers models.NotificationTriggerOptions) error
+	UpdateTimezone(c context.Context, userID int, timezone string) error
 	DeleteNotificationsForUser(c context.Context, userID int) error
 	GetLastCreatedOrModifiedForUserResources(c context.Context, userID int) (string, error)
 	RequestDeletion(c context.Context, userID int) error
@@ -109,6 +110,10 @@ func (r *UserRepository) UpdateNotificationSettings(c context.Context, userID in
 	}).Error
 }
 
+func (r *UserRepository) UpdateTimezone(c context.Context, userID int, timezone string) error {
+	return r.db.WithContext(c).Model(&models.User{}).Where("id = ?", userID).Update("timezone", timezone).Error
+}
+
 func (r *UserRepository) DeleteNotificationsForUser(c context.Context, userID int) error {
 	return r.db.WithContext(c).Where("user_id = ?", userID).Delete(&models.NotificationSettings{}).Error
 }
@@ -0,0 +1,194 @@
+package calendars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"taskwiz.app/core/internal/models"
+	repos "taskwiz.app/core/internal/repos/calendar"
+	"taskwiz.app/core/internal/services/logging"
+	"taskwiz.app/core/internal/telemetry"
+	"taskwiz.app/core/internal/utils/ics"
+	"taskwiz.app/core/internal/ws"
+)
+
+const maxHolidays = 1000
+
+type CalendarService struct {
+	r  *repos.CalendarRepository
+	ws *ws.WSServer
+}
+
+func NewCalendarService(r *repos.CalendarRepository, ws *ws.WSServer) *CalendarService {
+	return &CalendarService{r: r, ws: ws}
+}
+
+func (s *CalendarService) GetUserCalendars(ctx context.Context, userID int) (int, interface{}) {
+	calendars, err := s.r.GetUserCalendars(ctx, userID)
+	if err != nil {
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to get calendars: %s", err.Error())
+		telemetry.TrackError(ctx, "calendar_get_failed", "calendar-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to get calendars",
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"calendars": calendars,
+	}
+}
+
+func (s *CalendarService) CreateCalendar(ctx context.Context, userID int, req models.CreateHolidayCalendarReq) (int, interface{}) {
+	holidays, err := collectHolidays(req)
+	if err != nil {
+		telemetry.TrackWarning(ctx, "calendar_invalid_holidays", "calendar-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	calendar := &models.HolidayCalendar{
+		Name:     strings.TrimSpace(req.Name),
+		UserID:   userID,
+		Holidays: holidays,
+	}
+	if err := s.r.CreateCalendar(ctx, calendar); err != nil {
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to create calendar: %s", err.Error())
+		telemetry.TrackError(ctx, "calendar_create_failed", "calendar-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to create calendar",
+		}
+	}
+
+	response := gin.H{
+		"calendar": calendar,
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "calendar_created",
+		Data:   response,
+	})
+
+	return http.StatusCreated, response
+}
+
+func (s *CalendarService) UpdateCalendar(ctx context.Context, userID int, req models.UpdateHolidayCalendarReq) (int, interface{}) {
+	holidays, err := collectHolidays(req.CreateHolidayCalendarReq)
+	if err != nil {
+		telemetry.TrackWarning(ctx, "calendar_invalid_holidays", "calendar-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	calendar := &models.HolidayCalendar{
+		ID:       req.ID,
+		Name:     strings.TrimSpace(req.Name),
+		UserID:   userID,
+		Holidays: holidays,
+	}
+	if err := s.r.UpdateCalendar(ctx, userID, calendar); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{
+				"error": "Calendar not found",
+			}
+		}
+
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to update calendar: %s", err.Error())
+		telemetry.TrackError(ctx, "calendar_update_failed", "calendar-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to update calendar",
+		}
+	}
+
+	response := gin.H{
+		"calendar": calendar,
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "calendar_updated",
+		Data:   response,
+	})
+
+	return http.StatusOK, response
+}
+
+func (s *CalendarService) DeleteCalendar(ctx context.Context, userID int, calendarID int) (int, interface{}) {
+	if err := s.r.DeleteCalendar(ctx, userID, calendarID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{
+				"error": "Calendar not found",
+			}
+		}
+
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to delete calendar: %s", err.Error())
+		telemetry.TrackError(ctx, "calendar_delete_failed", "calendar-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete calendar",
+		}
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "calendar_deleted",
+		Data: gin.H{
+			"id": calendarID,
+		},
+	})
+	return http.StatusNoContent, nil
+}
+
+// collectHolidays merges the listed holidays with those imported from the
+// request's iCalendar data. A date listed explicitly wins over an imported
+// one; the result is sorted by date.
+func collectHolidays(req models.CreateHolidayCalendarReq) ([]models.Holiday, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("Calendar name is required")
+	}
+
+	holidays := make([]models.Holiday, 0, len(req.Holidays))
+	seen := make(map[string]bool)
+	for _, h := range req.Holidays {
+		if _, err := time.Parse(ics.DateLayout, h.Date); err != nil {
+			return nil, fmt.Errorf("Invalid holiday date: %s", h.Date)
+		}
+		if seen[h.Date] {
+			continue
+		}
+		seen[h.Date] = true
+		holidays = append(holidays, models.Holiday{Date: h.Date, Name: strings.TrimSpace(h.Name)})
+	}
+
+	if req.ICS != "" {
+		dates, err := ics.ParseDates(req.ICS)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid calendar file: %s", err.Error())
+		}
+		for _, d := range dates {
+			if seen[d.Date] {
+				continue
+			}
+			seen[d.Date] = true
+			holidays = append(holidays, models.Holiday{Date: d.Date, Name: d.Summary})
+		}
+	}
+
+	if len(holidays) > maxHolidays {
+		return nil, fmt.Errorf("A calendar cannot have more than %d holidays", maxHolidays)
+	}
+
+	slices.SortFunc(holidays, func(a, b models.Holiday) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	return holidays, nil
+}
@@ -0,0 +1,91 @@
+package calendars
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/ws"
+)
+
+type CalendarsMessageHandler struct {
+	cs *CalendarService
+}
+
+func NewCalendarsMessageHandler(cs *CalendarService) *CalendarsMessageHandler {
+	return &CalendarsMessageHandler{
+		cs: cs,
+	}
+}
+
+func (h *CalendarsMessageHandler) getCalendars(ctx context.Context, userID int, _ ws.WSMessage) *ws.WSResponse {
+	status, response := h.cs.GetUserCalendars(ctx, userID)
+
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *CalendarsMessageHandler) createCalendar(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.CreateHolidayCalendarReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.cs.CreateCalendar(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *CalendarsMessageHandler) updateCalendar(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.UpdateHolidayCalendarReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.cs.UpdateCalendar(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *CalendarsMessageHandler) deleteCalendar(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var calendarID int
+	if err := json.Unmarshal(msg.Data, &calendarID); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid calendar ID",
+			},
+		}
+	}
+
+	status, response := h.cs.DeleteCalendar(ctx, userID, calendarID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func CalendarMessages(ws *ws.WSServer, h *CalendarsMessageHandler) {
+	ws.RegisterHandler("get_calendars", h.getCalendars)
+	ws.RegisterHandler("create_calendar", h.createCalendar)
+	ws.RegisterHandler("update_calendar", h.updateCalendar)
+	ws.RegisterHandler("delete_calendar", h.deleteCalendar)
+}
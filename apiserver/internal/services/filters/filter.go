@@ -0,0 +1,239 @@
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"taskwiz.app/core/internal/models"
+	repos "taskwiz.app/core/internal/repos/filter"
+	"taskwiz.app/core/internal/services/logging"
+	tService "taskwiz.app/core/internal/services/tasks"
+	"taskwiz.app/core/internal/telemetry"
+	"taskwiz.app/core/internal/ws"
+)
+
+const (
+	maxFilterNameLength = 100
+	maxSavedFilters     = 100
+)
+
+type FilterService struct {
+	r  *repos.FilterRepository
+	ts *tService.TaskService
+	ws *ws.WSServer
+}
+
+func NewFilterService(r *repos.FilterRepository, ts *tService.TaskService, ws *ws.WSServer) *FilterService {
+	return &FilterService{r: r, ts: ts, ws: ws}
+}
+
+func (s *FilterService) GetUserFilters(ctx context.Context, userID int) (int, interface{}) {
+	filters, err := s.r.GetUserFilters(ctx, userID)
+	if err != nil {
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to get filters: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_get_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to get filters",
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"filters": filters,
+	}
+}
+
+func (s *FilterService) CreateFilter(ctx context.Context, userID int, req models.CreateSavedFilterReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	name, status, response := s.validate(ctx, userID, 0, req)
+	if response != nil {
+		return status, response
+	}
+
+	count, err := s.r.CountUserFilters(ctx, userID)
+	if err != nil {
+		log.Errorf("Failed to count filters: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_check_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to create filter",
+		}
+	}
+	if count >= maxSavedFilters {
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("You cannot have more than %d saved filters", maxSavedFilters),
+		}
+	}
+
+	filter := &models.SavedFilter{
+		Name:   name,
+		UserID: userID,
+		Filter: req.Filter,
+	}
+	if err := s.r.CreateFilter(ctx, filter); err != nil {
+		if isDuplicateKeyError(err) {
+			return http.StatusConflict, gin.H{
+				"error": "A filter with this name already exists",
+			}
+		}
+
+		log.Errorf("Failed to create filter: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_create_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to create filter",
+		}
+	}
+
+	response = gin.H{
+		"filter": filter,
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "filter_created",
+		Data:   response,
+	})
+
+	return http.StatusCreated, response
+}
+
+func (s *FilterService) UpdateFilter(ctx context.Context, userID int, req models.UpdateSavedFilterReq) (int, interface{}) {
+	name, status, response := s.validate(ctx, userID, req.ID, req.CreateSavedFilterReq)
+	if response != nil {
+		return status, response
+	}
+
+	filter := &models.SavedFilter{
+		ID:     req.ID,
+		Name:   name,
+		UserID: userID,
+		Filter: req.Filter,
+	}
+	if err := s.r.UpdateFilter(ctx, userID, filter); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{
+				"error": "Filter not found",
+			}
+		}
+		if isDuplicateKeyError(err) {
+			return http.StatusConflict, gin.H{
+				"error": "A filter with this name already exists",
+			}
+		}
+
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to update filter: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_update_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to update filter",
+		}
+	}
+
+	response = gin.H{
+		"filter": filter,
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "filter_updated",
+		Data:   response,
+	})
+
+	return http.StatusOK, response
+}
+
+func (s *FilterService) DeleteFilter(ctx context.Context, userID int, filterID int) (int, interface{}) {
+	if err := s.r.DeleteFilter(ctx, userID, filterID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{
+				"error": "Filter not found",
+			}
+		}
+
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to delete filter: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_delete_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete filter",
+		}
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "filter_deleted",
+		Data: gin.H{
+			"id": filterID,
+		},
+	})
+	return http.StatusNoContent, nil
+}
+
+// EvaluateFilter lists a page of the tasks matching one of the user's saved
+// filters, as the task list would with the same filters.
+func (s *FilterService) EvaluateFilter(ctx context.Context, userID int, req models.EvaluateSavedFilterReq) (int, interface{}) {
+	filter, err := s.r.GetUserFilter(ctx, userID, req.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{
+				"error": "Filter not found",
+			}
+		}
+
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to get filter: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_get_failed", "filter-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to get filter",
+		}
+	}
+
+	return s.ts.ListTasks(ctx, userID, models.ListTasksReq{
+		TaskFilter: filter.Filter,
+		Cursor:     req.Cursor,
+		Limit:      req.Limit,
+	})
+}
+
+// validate checks a filter about to be saved, returning its trimmed name or
+// the response to fail with.
+func (s *FilterService) validate(ctx context.Context, userID, filterID int, req models.CreateSavedFilterReq) (string, int, gin.H) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", http.StatusBadRequest, gin.H{"error": "Filter name is required"}
+	}
+	if utf8.RuneCountInString(name) > maxFilterNameLength {
+		return "", http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Filter name must be at most %d characters", maxFilterNameLength),
+		}
+	}
+
+	if response := tService.ValidateFilter(req.Filter); response != nil {
+		telemetry.TrackWarning(ctx, "filter_invalid", "filter-service", fmt.Sprint(response["error"]), nil)
+		return "", http.StatusBadRequest, response
+	}
+
+	exists, err := s.r.FilterExistsByName(ctx, userID, name, filterID)
+	if err != nil {
+		log := logging.FromContext(ctx)
+		log.Errorf("Failed to check filter existence: %s", err.Error())
+		telemetry.TrackError(ctx, "filter_check_failed", "filter-service", err, nil)
+		return "", http.StatusInternalServerError, gin.H{"error": "Failed to save filter"}
+	}
+	if exists {
+		return "", http.StatusConflict, gin.H{"error": "A filter with this name already exists"}
+	}
+
+	return name, 0, nil
+}
+
+func isDuplicateKeyError(err error) bool {
+	msg := err.Error()
+	// SQLite: "UNIQUE constraint failed: ..."
+	// MySQL: "Error 1062 (23000): Duplicate entry ..."
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "Duplicate entry") ||
+		strings.Contains(msg, "Error 1062")
+}
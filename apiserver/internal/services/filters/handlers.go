@@ -0,0 +1,110 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"taskwiz.app/core/internal/models"
+	"taskwiz.app/core/internal/ws"
+)
+
+type FiltersMessageHandler struct {
+	fs *FilterService
+}
+
+func NewFiltersMessageHandler(fs *FilterService) *FiltersMessageHandler {
+	return &FiltersMessageHandler{
+		fs: fs,
+	}
+}
+
+func (h *FiltersMessageHandler) getFilters(ctx context.Context, userID int, _ ws.WSMessage) *ws.WSResponse {
+	status, response := h.fs.GetUserFilters(ctx, userID)
+
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *FiltersMessageHandler) createFilter(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.CreateSavedFilterReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.fs.CreateFilter(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *FiltersMessageHandler) updateFilter(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.UpdateSavedFilterReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.fs.UpdateFilter(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *FiltersMessageHandler) deleteFilter(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var filterID int
+	if err := json.Unmarshal(msg.Data, &filterID); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid filter ID",
+			},
+		}
+	}
+
+	status, response := h.fs.DeleteFilter(ctx, userID, filterID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *FiltersMessageHandler) evaluateFilter(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.EvaluateSavedFilterReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.fs.EvaluateFilter(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func FilterMessages(ws *ws.WSServer, h *FiltersMessageHandler) {
+	ws.RegisterHandler("get_filters", h.getFilters)
+	ws.RegisterHandler("create_filter", h.createFilter)
+	ws.RegisterHandler("update_filter", h.updateFilter)
+	ws.RegisterHandler("delete_filter", h.deleteFilter)
+	ws.RegisterHandler("evaluate_filter", h.evaluateFilter)
+}
@@ -8,6 +8,7 @@ import (
 	sRepo "taskwiz.app/core/internal/repos/session"
 	"taskwiz.app/core/internal/services/logging"
 	"taskwiz.app/core/internal/services/notifications"
+	"taskwiz.app/core/internal/services/tasks"
 	"taskwiz.app/core/internal/services/users"
 	"taskwiz.app/core/internal/telemetry"
 )
@@ -16,15 +17,17 @@ type Scheduler struct {
 	stopChan    chan bool
 	notifier    *notifications.Notifier
 	userService *users.UserService
+	taskService *tasks.TaskService
 	sessionRepo sRepo.ISessionRepo
 	config      config.SchedulerConfig
 }
 
-func NewScheduler(cfg *config.Config, n *notifications.Notifier, us *users.UserService, sr sRepo.ISessionRepo) *Scheduler {
+func NewScheduler(cfg *config.Config, n *notifications.Notifier, us *users.UserService, ts *tasks.TaskService, sr sRepo.ISessionRepo) *Scheduler {
 	return &Scheduler{
 		stopChan:    make(chan bool),
 		notifier:    n,
 		userService: us,
+		taskService: ts,
 		sessionRepo: sr,
 		config:      cfg.SchedulerJobs,
 	}
@@ -38,7 +41,24 @@ func (s *Scheduler) Start(c context.Context) {
 	go s.runScheduler(c, "NOTIFICATION_SENDER", s.notifier.LoadAndSendNotificationJob, s.config.DueFrequency)
 	go s.runScheduler(c, "NOTIFICATION_CLEANUP", s.notifier.CleanupNotifications, s.config.NotificationCleanup)
 	go s.runScheduler(c, "ACCOUNT_DELETION", s.userService.ProcessDeletions, s.config.AccountDeletionFrequency)
+	go s.runScheduler(c, "HABIT_PERIODS", s.taskService.CloseLapsedHabitPeriods, s.config.HabitPeriodFrequency)
 	go s.runScheduler(c, "SESSION_CLEANUP", s.sessionRepo.CleanupExpired, 1*time.Hour)
+
+	if s.config.ArchiveRetention > 0 {
+		frequency := s.config.ArchiveCleanupFrequency
+		if frequency <= 0 {
+			frequency = 1 * time.Hour
+		}
+		go s.runScheduler(c, "ARCHIVE_CLEANUP", s.purgeArchivedTasks, frequency)
+	}
+
+	if s.config.TrashRetention > 0 {
+		frequency := s.config.TrashCleanupFrequency
+		if frequency <= 0 {
+			frequency = 1 * time.Hour
+		}
+		go s.runScheduler(c, "TRASH_CLEANUP", s.purgeDeletedTasks, frequency)
+	}
 }
 
 func (s *Scheduler) runScheduler(c context.Context, jobName string, job func(c context.Context) error, interval time.Duration) {
@@ -63,6 +83,14 @@ func (s *Scheduler) runScheduler(c context.Context, jobName string, job func(c c
 	}
 }
 
+func (s *Scheduler) purgeArchivedTasks(c context.Context) error {
+	return s.taskService.PurgeArchivedTasks(c, s.config.ArchiveRetention)
+}
+
+func (s *Scheduler) purgeDeletedTasks(c context.Context) error {
+	return s.taskService.PurgeDeletedTasks(c, s.config.TrashRetention)
+}
+
 func (s *Scheduler) Stop() {
 	s.stopChan <- true
 }
@@ -20,8 +20,57 @@ func NewTasksMessageHandler(ts *TaskService) *TasksMessageHandler {
 	return &TasksMessageHandler{ts: ts}
 }
 
-func (h *TasksMessageHandler) getUserTasks(ctx context.Context, userID int, _ ws.WSMessage) *ws.WSResponse {
-	status, response := h.ts.GetUserTasks(ctx, userID)
+func (h *TasksMessageHandler) getUserTasks(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.ListTasksReq
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			return &ws.WSResponse{
+				Status: http.StatusBadRequest,
+				Data: gin.H{
+					"error": "Invalid request data",
+				},
+			}
+		}
+	}
+	status, response := h.ts.ListTasks(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) getArchivedTasks(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.ListTasksReq
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			return &ws.WSResponse{
+				Status: http.StatusBadRequest,
+				Data: gin.H{
+					"error": "Invalid request data",
+				},
+			}
+		}
+	}
+	status, response := h.ts.ListArchivedTasks(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) getDeletedTasks(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.ListTasksReq
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			return &ws.WSResponse{
+				Status: http.StatusBadRequest,
+				Data: gin.H{
+					"error": "Invalid request data",
+				},
+			}
+		}
+	}
+	status, response := h.ts.ListDeletedTasks(ctx, userID, req)
 	return &ws.WSResponse{
 		Status: status,
 		Data:   response,
@@ -30,8 +79,9 @@ func (h *TasksMessageHandler) getUserTasks(ctx context.Context, userID int, _ ws
 
 func (h *TasksMessageHandler) getActivity(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var req struct {
-		BeforeID int `json:"before_id"`
-		Limit    int `json:"limit"`
+		Cursor   string `json:"cursor"`
+		BeforeID int    `json:"before_id"`
+		Limit    int    `json:"limit"`
 	}
 	if err := json.Unmarshal(msg.Data, &req); err != nil {
 		return &ws.WSResponse{
@@ -47,7 +97,24 @@ func (h *TasksMessageHandler) getActivity(ctx context.Context, userID int, msg w
 	if req.BeforeID < 0 {
 		req.BeforeID = 0
 	}
-	status, response := h.ts.GetRecentActivity(ctx, userID, req.BeforeID, req.Limit)
+	status, response := h.ts.GetRecentActivity(ctx, userID, req.Cursor, req.BeforeID, req.Limit)
+	return &ws.WSResponse{Status: status, Data: response}
+}
+
+func (h *TasksMessageHandler) searchTasks(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		Query string `json:"q"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.SearchTasks(ctx, userID, req.Query, req.Limit)
 	return &ws.WSResponse{Status: status, Data: response}
 }
 
@@ -68,6 +135,24 @@ func (h *TasksMessageHandler) getTask(ctx context.Context, userID int, msg ws.WS
 	}
 }
 
+func (h *TasksMessageHandler) previewOccurrences(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.PreviewOccurrencesReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.ts.PreviewOccurrences(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 func (h *TasksMessageHandler) createTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var req models.CreateTaskReq
 	if err := json.Unmarshal(msg.Data, &req); err != nil {
@@ -102,6 +187,27 @@ func (h *TasksMessageHandler) updateTask(ctx context.Context, userID int, msg ws
 	}
 }
 
+func (h *TasksMessageHandler) patchTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID      int             `json:"id"`
+		Version int             `json:"version"`
+		Patch   json.RawMessage `json:"patch"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.PatchTask(ctx, userID, req.ID, req.Version, req.Patch)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 func (h *TasksMessageHandler) deleteTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var id int
 	if err := json.Unmarshal(msg.Data, &id); err != nil {
@@ -119,6 +225,23 @@ func (h *TasksMessageHandler) deleteTask(ctx context.Context, userID int, msg ws
 	}
 }
 
+func (h *TasksMessageHandler) restoreDeletedTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var id int
+	if err := json.Unmarshal(msg.Data, &id); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid task ID",
+			},
+		}
+	}
+	status, response := h.ts.RestoreDeletedTask(ctx, userID, id)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 func (h *TasksMessageHandler) skipTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var id int
 	if err := json.Unmarshal(msg.Data, &id); err != nil {
@@ -156,10 +279,31 @@ func (h *TasksMessageHandler) updateDueDate(ctx context.Context, userID int, msg
 	}
 }
 
+func (h *TasksMessageHandler) restoreTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+		models.RestoreTaskReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.RestoreTask(ctx, userID, req.ID, req.RestoreTaskReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 func (h *TasksMessageHandler) completeTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var req struct {
-		ID            int  `json:"id"`
-		EndRecurrence bool `json:"endRecurrence"`
+		ID            int    `json:"id"`
+		EndRecurrence bool   `json:"endRecurrence"`
+		CompletedDate string `json:"completed_date"`
 	}
 	if err := json.Unmarshal(msg.Data, &req); err != nil {
 		return &ws.WSResponse{
@@ -169,7 +313,27 @@ func (h *TasksMessageHandler) completeTask(ctx context.Context, userID int, msg
 			},
 		}
 	}
-	status, response := h.ts.CompleteTask(ctx, userID, req.ID, req.EndRecurrence)
+	status, response := h.ts.CompleteTask(ctx, userID, req.ID, req.EndRecurrence, req.CompletedDate)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) backfillTask(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+		models.BackfillTaskReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.BackfillTask(ctx, userID, req.ID, req.BackfillTaskReq)
 	return &ws.WSResponse{
 		Status: status,
 		Data:   response,
@@ -178,8 +342,9 @@ func (h *TasksMessageHandler) completeTask(ctx context.Context, userID int, msg
 
 func (h *TasksMessageHandler) revertAction(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
 	var req struct {
-		ID        int `json:"id"`
-		HistoryID int `json:"history_id"`
+		ID        int  `json:"id"`
+		HistoryID int  `json:"history_id"`
+		UndoSteps *int `json:"undo_steps"`
 	}
 	if err := json.Unmarshal(msg.Data, &req); err != nil {
 		return &ws.WSResponse{
@@ -197,7 +362,19 @@ func (h *TasksMessageHandler) revertAction(ctx context.Context, userID int, msg
 			},
 		}
 	}
-	status, response := h.ts.RevertAction(ctx, userID, req.ID, req.HistoryID)
+	steps := 1
+	if req.UndoSteps != nil {
+		if *req.UndoSteps <= 0 {
+			return &ws.WSResponse{
+				Status: http.StatusBadRequest,
+				Data: gin.H{
+					"error": "Invalid undo_steps value",
+				},
+			}
+		}
+		steps = *req.UndoSteps
+	}
+	status, response := h.ts.RevertAction(ctx, userID, req.ID, req.HistoryID, steps)
 	return &ws.WSResponse{
 		Status: status,
 		Data:   response,
@@ -221,17 +398,290 @@ func (h *TasksMessageHandler) getTaskHistory(ctx context.Context, userID int, ms
 	}
 }
 
+func (h *TasksMessageHandler) getTaskAudit(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var id int
+	if err := json.Unmarshal(msg.Data, &id); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid task ID",
+			},
+		}
+	}
+	status, response := h.ts.GetTaskAudit(ctx, userID, id)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) updateTaskHistory(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID        int `json:"id"`
+		HistoryID int `json:"history_id"`
+		models.UpdateTaskHistoryReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.UpdateTaskHistory(ctx, userID, req.ID, req.HistoryID, req.UpdateTaskHistoryReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) deleteTaskHistory(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID        int `json:"id"`
+		HistoryID int `json:"history_id"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.DeleteTaskHistory(ctx, userID, req.ID, req.HistoryID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) getOccurrenceOverrides(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var id int
+	if err := json.Unmarshal(msg.Data, &id); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid task ID",
+			},
+		}
+	}
+	status, response := h.ts.GetOccurrenceOverrides(ctx, userID, id)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) setOccurrenceOverride(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+		models.OccurrenceOverrideReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.SetOccurrenceOverride(ctx, userID, req.ID, req.OccurrenceOverrideReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) deleteOccurrenceOverride(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID         int `json:"id"`
+		OverrideID int `json:"override_id"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.DeleteOccurrenceOverride(ctx, userID, req.ID, req.OverrideID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) getChecklist(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var id int
+	if err := json.Unmarshal(msg.Data, &id); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid task ID",
+			},
+		}
+	}
+	status, response := h.ts.GetChecklist(ctx, userID, id)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) addChecklistItem(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+		models.CreateChecklistItemReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.AddChecklistItem(ctx, userID, req.ID, req.CreateChecklistItemReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) updateChecklistItem(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID     int `json:"id"`
+		ItemID int `json:"item_id"`
+		models.UpdateChecklistItemReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.UpdateChecklistItem(ctx, userID, req.ID, req.ItemID, req.UpdateChecklistItemReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) deleteChecklistItem(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID     int `json:"id"`
+		ItemID int `json:"item_id"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.DeleteChecklistItem(ctx, userID, req.ID, req.ItemID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) getDependencies(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.GetDependencies(ctx, userID, req.ID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) addDependency(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID int `json:"id"`
+		models.AddDependencyReq
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.AddDependency(ctx, userID, req.ID, req.AddDependencyReq)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
+func (h *TasksMessageHandler) removeDependency(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req struct {
+		ID        int `json:"id"`
+		BlockerID int `json:"blocker_id"`
+	}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+	status, response := h.ts.RemoveDependency(ctx, userID, req.ID, req.BlockerID)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 // TaskMessages registers websocket handlers for task actions.
 func TaskMessages(wsServer *ws.WSServer, h *TasksMessageHandler) {
 	wsServer.RegisterHandler("get_tasks", h.getUserTasks)
+	wsServer.RegisterHandler("get_archived_tasks", h.getArchivedTasks)
+	wsServer.RegisterHandler("get_deleted_tasks", h.getDeletedTasks)
 	wsServer.RegisterHandler("get_activity", h.getActivity)
+	wsServer.RegisterHandler("search_tasks", h.searchTasks)
 	wsServer.RegisterHandler("get_task", h.getTask)
 	wsServer.RegisterHandler("create_task", h.createTask)
+	wsServer.RegisterHandler("preview_occurrences", h.previewOccurrences)
 	wsServer.RegisterHandler("update_task", h.updateTask)
+	wsServer.RegisterHandler("patch_task", h.patchTask)
 	wsServer.RegisterHandler("delete_task", h.deleteTask)
+	wsServer.RegisterHandler("restore_deleted_task", h.restoreDeletedTask)
 	wsServer.RegisterHandler("skip_task", h.skipTask)
 	wsServer.RegisterHandler("update_due_date", h.updateDueDate)
+	wsServer.RegisterHandler("restore_task", h.restoreTask)
 	wsServer.RegisterHandler("complete_task", h.completeTask)
+	wsServer.RegisterHandler("backfill_task", h.backfillTask)
 	wsServer.RegisterHandler("uncomplete_task", h.revertAction)
 	wsServer.RegisterHandler("get_task_history", h.getTaskHistory)
+	wsServer.RegisterHandler("update_task_history", h.updateTaskHistory)
+	wsServer.RegisterHandler("delete_task_history", h.deleteTaskHistory)
+	wsServer.RegisterHandler("get_task_audit", h.getTaskAudit)
+	wsServer.RegisterHandler("get_occurrence_overrides", h.getOccurrenceOverrides)
+	wsServer.RegisterHandler("set_occurrence_override", h.setOccurrenceOverride)
+	wsServer.RegisterHandler("delete_occurrence_override", h.deleteOccurrenceOverride)
+	wsServer.RegisterHandler("get_checklist", h.getChecklist)
+	wsServer.RegisterHandler("add_checklist_item", h.addChecklistItem)
+	wsServer.RegisterHandler("update_checklist_item", h.updateChecklistItem)
+	wsServer.RegisterHandler("delete_checklist_item", h.deleteChecklistItem)
+	wsServer.RegisterHandler("get_dependencies", h.getDependencies)
+	wsServer.RegisterHandler("add_dependency", h.addDependency)
+	wsServer.RegisterHandler("remove_dependency", h.removeDependency)
 }
@@ -2,21 +2,35 @@ package tasks
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"taskwiz.app/core/config"
 	"taskwiz.app/core/internal/models"
+	cRepo "taskwiz.app/core/internal/repos/calendar"
+	"taskwiz.app/core/internal/repos/fulltext"
 	lRepo "taskwiz.app/core/internal/repos/label"
 	nRepo "taskwiz.app/core/internal/repos/notifier"
 	tRepo "taskwiz.app/core/internal/repos/task"
+	uRepo "taskwiz.app/core/internal/repos/user"
 	"taskwiz.app/core/internal/services/logging"
 	"taskwiz.app/core/internal/services/notifications"
 	"taskwiz.app/core/internal/telemetry"
+	"taskwiz.app/core/internal/utils/markdown"
+	"taskwiz.app/core/internal/utils/mergepatch"
+	"taskwiz.app/core/internal/utils/rrule"
+	"taskwiz.app/core/internal/utils/search"
 	"taskwiz.app/core/internal/ws"
 )
 
@@ -26,26 +40,102 @@ type TaskService struct {
 	notifier *notifications.Notifier
 	n        *nRepo.NotificationRepository
 	l        *lRepo.LabelRepository
+	c        *cRepo.CalendarRepository
+	u        uRepo.IUserRepo
+
+	requireVersion bool
 }
 
-func NewTaskService(t *tRepo.TaskRepository, ws *ws.WSServer, notifier *notifications.Notifier, n *nRepo.NotificationRepository, l *lRepo.LabelRepository) *TaskService {
+func NewTaskService(cfg *config.Config, t *tRepo.TaskRepository, ws *ws.WSServer, notifier *notifications.Notifier, n *nRepo.NotificationRepository, l *lRepo.LabelRepository, c *cRepo.CalendarRepository, u uRepo.IUserRepo) *TaskService {
 	return &TaskService{
-		t:        t,
-		ws:       ws,
-		notifier: notifier,
-		n:        n,
-		l:        l,
+		t:              t,
+		ws:             ws,
+		notifier:       notifier,
+		n:              n,
+		l:              l,
+		c:              c,
+		u:              u,
+		requireVersion: cfg.Server.RequireTaskVersion,
 	}
 }
 
+// maxHabitTarget bounds how many completions a habit task may ask for in a
+// single period.
+const maxHabitTarget = 1000
+
+// maxNotesLength bounds the length of a task's notes, in characters.
+const maxNotesLength = 10000
+
 // maxActivityPageSize bounds how many activity entries a single request may return,
 // regardless of the limit supplied over HTTP or WebSocket.
 const maxActivityPageSize = 20
 
-func (s *TaskService) GetUserTasks(ctx context.Context, userID int) (int, interface{}) {
+// maxSearchResults bounds how many tasks a full-text search returns.
+const maxSearchResults = 50
+
+// maxTaskPageSize bounds how many tasks a single page of the task list may
+// hold.
+const maxTaskPageSize = 200
+
+// ListTasks lists the user's active tasks matching the filters of req, a page
+// at a time when a limit is given. With HideDormant, tasks whose active window
+// excludes the current date are left out after the page is read, so such a
+// page may hold fewer tasks than the limit even when more follow.
+func (s *TaskService) ListTasks(ctx context.Context, userID int, req models.ListTasksReq) (int, interface{}) {
+	return s.listTasks(ctx, userID, req, nil)
+}
+
+// ListArchivedTasks lists the user's archived tasks, those completed for the
+// last time, with the same filters and paging as ListTasks. Unless req says
+// otherwise, the most recently archived come first.
+func (s *TaskService) ListArchivedTasks(ctx context.Context, userID int, req models.ListTasksReq) (int, interface{}) {
+	if req.Sort == "" {
+		req.Sort = string(models.TaskSortArchived) + ":desc"
+	}
+	req.HideDormant = false
+	return s.listTasks(ctx, userID, req, func(query *models.TaskQuery) {
+		query.Archived = true
+	})
+}
+
+// ListDeletedTasks lists the tasks in the user's trash, with the same filters
+// and paging as ListTasks. Unless req says otherwise, the most recently
+// deleted come first.
+func (s *TaskService) ListDeletedTasks(ctx context.Context, userID int, req models.ListTasksReq) (int, interface{}) {
+	if req.Sort == "" {
+		req.Sort = string(models.TaskSortDeleted) + ":desc"
+	}
+	req.HideDormant = false
+	return s.listTasks(ctx, userID, req, func(query *models.TaskQuery) {
+		query.Deleted = true
+	})
+}
+
+// listTasks lists the user's tasks selected by req; scope, if not nil,
+// adjusts the query to select another set of tasks than the active ones.
+func (s *TaskService) listTasks(ctx context.Context, userID int, req models.ListTasksReq, scope func(*models.TaskQuery)) (int, interface{}) {
 	log := logging.FromContext(ctx)
-	tasks, err := s.t.GetTasks(ctx, userID)
+
+	query, err := taskQuery(req)
 	if err != nil {
+		telemetry.TrackWarning(ctx, "task_get_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, invalidQuery(err)
+	}
+	if scope != nil {
+		scope(&query)
+	}
+	if query.Search != nil {
+		query.Location = s.userLocation(ctx, userID)
+	}
+
+	tasks, nextAfterID, err := s.t.ListTasks(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, tRepo.ErrInvalidCursor) {
+			telemetry.TrackWarning(ctx, "task_get_failed", "task-service", err.Error(), nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Cursor is no longer valid",
+			}
+		}
 		log.Errorf("error getting tasks: %s", err.Error())
 		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
@@ -53,80 +143,279 @@ func (s *TaskService) GetUserTasks(ctx context.Context, userID int) (int, interf
 		}
 	}
 
+	if req.HideDormant {
+		now := time.Now().UTC()
+		loc := s.userLocation(ctx, userID)
+		tasks = slices.DeleteFunc(tasks, func(task *models.Task) bool {
+			return tRepo.IsDormant(task, now, loc)
+		})
+	}
+
+	var nextCursor *string
+	if nextAfterID > 0 {
+		cursor := encodeCursor(nextAfterID)
+		nextCursor = &cursor
+	}
+
 	return http.StatusOK, gin.H{
-		"tasks": tasks,
+		"tasks":       tasks,
+		"next_cursor": nextCursor,
 	}
 }
 
-func (s *TaskService) GetTasksDueBefore(ctx context.Context, userID int, before time.Time) (int, interface{}) {
-	log := logging.FromContext(ctx)
-	tasks, err := s.t.GetTasksDueBefore(ctx, userID, before)
+// ValidateFilter checks the filters and sort order of a task list as a task
+// list request does, returning the body of the response such a request would
+// fail with, or nil if they are valid.
+func ValidateFilter(filter models.TaskFilter) gin.H {
+	if _, err := taskQuery(models.ListTasksReq{TaskFilter: filter}); err != nil {
+		return invalidQuery(err)
+	}
+	return nil
+}
+
+func invalidQuery(err error) gin.H {
+	var parseErr *search.ParseError
+	if errors.As(err, &parseErr) {
+		return gin.H{
+			"error":       "Invalid search query",
+			"query_error": parseErr,
+		}
+	}
+	return gin.H{
+		"error": err.Error(),
+	}
+}
+
+// taskQuery validates the filters of a task list request.
+func taskQuery(req models.ListTasksReq) (models.TaskQuery, error) {
+	query := models.TaskQuery{
+		Labels:           req.Labels,
+		LabelMatch:       req.LabelMatch,
+		Overdue:          req.Overdue,
+		Recurring:        req.Recurring,
+		HasNotifications: req.HasNotifications,
+		SearchNotes:      req.SearchNotes,
+		Limit:            req.Limit,
+	}
+
+	switch query.LabelMatch {
+	case "":
+		query.LabelMatch = models.LabelMatchAny
+	case models.LabelMatchAny, models.LabelMatchAll:
+	default:
+		return query, fmt.Errorf("unknown label match %q", req.LabelMatch)
+	}
+
+	for _, due := range []struct {
+		name string
+		raw  string
+		dst  **time.Time
+	}{
+		{"due_after", req.DueAfter, &query.DueAfter},
+		{"due_before", req.DueBefore, &query.DueBefore},
+	} {
+		if due.raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, due.raw)
+		if err != nil {
+			return query, fmt.Errorf("'%s' must be in RFC 3339 / ISO 8601 format (e.g. 2025-01-15T00:00:00Z)", due.name)
+		}
+		parsed = parsed.UTC()
+		*due.dst = &parsed
+	}
+
+	if query.Limit < 0 || query.Limit > maxTaskPageSize {
+		return query, fmt.Errorf("limit must be between 0 and %d", maxTaskPageSize)
+	}
+
+	sorts, err := tRepo.ParseTaskSort(req.Sort)
 	if err != nil {
-		log.Errorf("error getting tasks due before %s: %s", before.String(), err.Error())
-		telemetry.TrackError(ctx, "task_get_due_before_failed", "task-service", err, nil)
-		return http.StatusInternalServerError, gin.H{
-			"error": "Error getting tasks",
+		return query, err
+	}
+	query.Sort = sorts
+
+	if strings.TrimSpace(req.Query) != "" {
+		parsed, err := search.Parse(req.Query)
+		if err != nil {
+			return query, err
 		}
+		query.Search = parsed
 	}
 
-	return http.StatusOK, gin.H{
-		"tasks": tasks,
+	if req.Cursor != "" {
+		afterID, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return query, err
+		}
+		query.AfterID = afterID
+	}
+
+	return query, nil
+}
+
+// cursorPrefix marks the payload of a task list cursor, which is otherwise
+// opaque to clients.
+const cursorPrefix = "after:"
+
+func encodeCursor(afterID int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(afterID)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
 	}
+	afterID, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) || afterID <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return afterID, nil
+}
+
+// activityCursorPrefix marks the payload of an activity feed cursor, which
+// names the entry the next page follows.
+const activityCursorPrefix = "before:"
+
+func encodeActivityCursor(ref models.ActivityRef) string {
+	payload := activityCursorPrefix + string(ref.Type) + ":" + strconv.Itoa(ref.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload))
+}
+
+func decodeActivityCursor(cursor string) (models.ActivityRef, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.ActivityRef{}, errors.New("invalid cursor")
+	}
+	payload, ok := strings.CutPrefix(string(raw), activityCursorPrefix)
+	if !ok {
+		return models.ActivityRef{}, errors.New("invalid cursor")
+	}
+	entryType, rawID, _ := strings.Cut(payload, ":")
+	id, err := strconv.Atoi(rawID)
+	if err != nil || id <= 0 {
+		return models.ActivityRef{}, errors.New("invalid cursor")
+	}
+	ref := models.ActivityRef{Type: models.ActivityType(entryType), ID: id}
+	if ref.Type != models.ActivityHistory && ref.Type != models.ActivityAudit {
+		return models.ActivityRef{}, errors.New("invalid cursor")
+	}
+	return ref, nil
 }
 
-func (s *TaskService) GetTasksByLabel(ctx context.Context, userID int, labelID int) (int, interface{}) {
+// GetRecentActivity returns a page of the user's activity feed. The page
+// follows the entry named by cursor or, for older clients, the history entry
+// beforeID; the response carries the cursor of the next page, if any.
+func (s *TaskService) GetRecentActivity(ctx context.Context, userID int, cursor string, beforeID, limit int) (int, interface{}) {
 	log := logging.FromContext(ctx)
-	tasks, err := s.t.GetTasksByLabel(ctx, userID, labelID)
+
+	if limit <= 0 || limit > maxActivityPageSize {
+		limit = maxActivityPageSize
+	}
+
+	var before *models.ActivityRef
+	if cursor != "" {
+		ref, err := decodeActivityCursor(cursor)
+		if err != nil {
+			telemetry.TrackWarning(ctx, "task_get_activity_failed", "task-service", err.Error(), nil)
+			return http.StatusBadRequest, gin.H{"error": "Invalid cursor"}
+		}
+		before = &ref
+	} else if beforeID > 0 {
+		before = &models.ActivityRef{Type: models.ActivityHistory, ID: beforeID}
+	}
+
+	entries, err := s.t.GetRecentActivity(ctx, userID, before, limit)
 	if err != nil {
-		log.Errorf("error getting tasks by label %d: %s", labelID, err.Error())
-		telemetry.TrackError(ctx, "task_get_by_label_failed", "task-service", err, nil)
+		if errors.Is(err, tRepo.ErrInvalidCursor) {
+			telemetry.TrackWarning(ctx, "task_get_activity_failed", "task-service", "Cursor is no longer valid", nil)
+			return http.StatusBadRequest, gin.H{"error": "Cursor is no longer valid"}
+		}
+		log.Errorf("error getting recent activity: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_activity_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
-			"error": "Error getting tasks",
+			"error": "Error getting recent activity",
 		}
 	}
 
-	return http.StatusOK, gin.H{
-		"tasks": tasks,
+	resp := gin.H{
+		"activity": entries,
+	}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		resp["next_cursor"] = encodeActivityCursor(models.ActivityRef{Type: last.Type, ID: last.ID})
 	}
+	return http.StatusOK, resp
 }
 
-func (s *TaskService) SearchTasksByTitle(ctx context.Context, userID int, query string) (int, interface{}) {
+// GetTaskAudit returns the audit trail of one of the user's tasks, most
+// recent edit first.
+func (s *TaskService) GetTaskAudit(ctx context.Context, userID, taskID int) (int, interface{}) {
 	log := logging.FromContext(ctx)
-	tasks, err := s.t.SearchTasksByTitle(ctx, userID, query)
+
+	task, err := s.t.GetTask(ctx, taskID)
 	if err != nil {
-		log.Errorf("error searching tasks by title %q: %s", query, err.Error())
-		telemetry.TrackError(ctx, "task_search_failed", "task-service", err, nil)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
-			"error": "Error searching tasks",
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to view task audit", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	audits, err := s.t.GetTaskAudit(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting task audit: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_audit_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task audit",
 		}
 	}
 
 	return http.StatusOK, gin.H{
-		"tasks": tasks,
+		"audit": audits,
 	}
 }
 
-func (s *TaskService) GetRecentActivity(ctx context.Context, userID, beforeID, limit int) (int, interface{}) {
+// SearchTasks ranks the user's active tasks by how well their titles and
+// label names match query, using the full-text index.
+func (s *TaskService) SearchTasks(ctx context.Context, userID int, query string, limit int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
-	if limit <= 0 || limit > maxActivityPageSize {
-		limit = maxActivityPageSize
+	if len(fulltext.Terms(query)) == 0 {
+		return http.StatusBadRequest, gin.H{"error": "Search query must contain at least one word"}
 	}
-	if beforeID < 0 {
-		beforeID = 0
+	if limit <= 0 || limit > maxSearchResults {
+		limit = maxSearchResults
 	}
 
-	entries, err := s.t.GetRecentActivity(ctx, userID, beforeID, limit)
+	results, err := s.t.SearchTasks(ctx, userID, query, limit)
 	if err != nil {
-		log.Errorf("error getting recent activity: %s", err.Error())
-		telemetry.TrackError(ctx, "task_get_activity_failed", "task-service", err, nil)
+		log.Errorf("error searching tasks: %s", err.Error())
+		telemetry.TrackError(ctx, "task_search_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
-			"error": "Error getting recent activity",
+			"error": "Error searching tasks",
 		}
 	}
 
+	// tasks repeats the matched tasks in rank order for clients that predate
+	// results, such as the MCP server.
+	tasks := make([]*models.Task, 0, len(results))
+	for _, result := range results {
+		tasks = append(tasks, result.Task)
+	}
+
 	return http.StatusOK, gin.H{
-		"activity": entries,
+		"results": results,
+		"tasks":   tasks,
 	}
 }
 
@@ -155,6 +444,171 @@ func (s *TaskService) GetTask(ctx context.Context, userID, taskID int) (int, int
 	}
 }
 
+// userLocation resolves the time zone recurrences are computed in for a user,
+// falling back to UTC when none is set or it can no longer be loaded.
+func (s *TaskService) userLocation(ctx context.Context, userID int) *time.Location {
+	log := logging.FromContext(ctx)
+
+	user, err := s.u.GetUser(ctx, userID)
+	if err != nil {
+		log.Warnf("error getting user %d, scheduling in UTC: %s", userID, err.Error())
+		return time.UTC
+	}
+
+	if user.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		log.Warnf("invalid time zone %q for user %d, scheduling in UTC: %s", user.Timezone, userID, err.Error())
+		return time.UTC
+	}
+
+	return loc
+}
+
+// anchorRecurrenceRule pins a recurrence rule without a DTSTART to the task's
+// due date, so that COUNT is counted from the first occurrence rather than
+// restarting every time the task is rescheduled.
+func anchorRecurrenceRule(freq *models.Frequency, dueDate *time.Time) {
+	if freq.Type != models.RepeatRRule || dueDate == nil {
+		return
+	}
+
+	rule, err := rrule.Parse(freq.RRule)
+	if err != nil || rule.DTStart != nil {
+		return
+	}
+
+	start := dueDate.UTC()
+	rule.DTStart = &start
+	freq.RRule = rule.String()
+}
+
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 50
+)
+
+func (s *TaskService) PreviewOccurrences(ctx context.Context, userID int, req models.PreviewOccurrencesReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if req.Count == 0 {
+		req.Count = defaultPreviewCount
+	}
+	if req.Count < 0 || req.Count > maxPreviewCount {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", fmt.Sprintf("Invalid preview count: %d", req.Count), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Count must be between 1 and %d", maxPreviewCount),
+		}
+	}
+
+	if req.NextDueDate == "" {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", "Missing due date", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Due date is required to preview occurrences",
+		}
+	}
+
+	dueDate, err := time.Parse(time.RFC3339, req.NextDueDate)
+	if err != nil {
+		log.Errorf("error parsing due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_preview_failed", "task-service", err, nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Due date must be in UTC format",
+		}
+	}
+	dueDate = dueDate.UTC()
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		rawEndDate, err := time.Parse(time.RFC3339, req.EndDate)
+		if err != nil {
+			log.Errorf("error parsing end date: %s", err.Error())
+			telemetry.TrackError(ctx, "task_preview_failed", "task-service", err, nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "End date must be in UTC format",
+			}
+		}
+
+		rawEndDate = rawEndDate.UTC()
+		endDate = &rawEndDate
+	}
+
+	if err := tRepo.ValidateFrequency(req.Frequency); err != nil {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if req.MaxOccurrences < 0 {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", fmt.Sprintf("Invalid max occurrences: %d", req.MaxOccurrences), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Max occurrences cannot be negative",
+		}
+	}
+
+	if err := tRepo.ValidateActiveWindow(req.ActiveWindow); err != nil {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if err := tRepo.ValidateBusinessDays(req.BusinessDays); err != nil {
+		telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	holidayCalendar, err := s.holidayCalendar(ctx, userID, req.HolidayCalendarID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_preview_failed", "task-service", "Holiday calendar not found", nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Holiday calendar not found",
+			}
+		}
+		log.Errorf("error getting holiday calendar: %s", err.Error())
+		telemetry.TrackError(ctx, "task_preview_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting holiday calendar",
+		}
+	}
+
+	anchorRecurrenceRule(&req.Frequency, &dueDate)
+
+	task := &models.Task{
+		Frequency:         req.Frequency,
+		NextDueDate:       &dueDate,
+		EndDate:           endDate,
+		MaxOccurrences:    req.MaxOccurrences,
+		ActiveWindow:      req.ActiveWindow,
+		BusinessDays:      req.BusinessDays,
+		HolidayCalendarID: req.HolidayCalendarID,
+		HolidayCalendar:   holidayCalendar,
+		IsRolling:         req.IsRolling,
+		CreatedBy:         userID,
+		IsActive:          true,
+	}
+
+	occurrences, err := tRepo.PreviewOccurrences(task, req.Count, s.userLocation(ctx, userID))
+	if err != nil {
+		log.Errorf("error previewing occurrences: %s", err.Error())
+		telemetry.TrackError(ctx, "task_preview_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Error previewing occurrences: %s", err),
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"occurrences": occurrences,
+	}
+}
+
 func createShallowLabels(labelIds []int) []models.Label {
 	labels := make([]models.Label, len(labelIds))
 	for i, id := range labelIds {
@@ -196,15 +650,99 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int, req models.Cre
 		endDate = &rawEndDate
 	}
 
+	if err := tRepo.ValidateFrequency(req.Frequency); err != nil {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if req.MaxOccurrences < 0 {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", fmt.Sprintf("Invalid max occurrences: %d", req.MaxOccurrences), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Max occurrences cannot be negative",
+		}
+	}
+
+	if err := tRepo.ValidatePriority(req.Priority); err != nil {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if utf8.RuneCountInString(req.Notes) > maxNotesLength {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", "Notes too long", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Notes must be at most %d characters", maxNotesLength),
+		}
+	}
+
+	if req.HabitTarget < 0 || req.HabitTarget > maxHabitTarget {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", fmt.Sprintf("Invalid habit target: %d", req.HabitTarget), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Habit target must be between 0 and %d", maxHabitTarget),
+		}
+	}
+
+	if err := tRepo.ValidateActiveWindow(req.ActiveWindow); err != nil {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if err := tRepo.ValidateCatchUpPolicy(req.CatchUp); err != nil {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+	if req.CatchUp == "" {
+		req.CatchUp = models.CatchUpNextOccurrence
+	}
+
+	if err := tRepo.ValidateBusinessDays(req.BusinessDays); err != nil {
+		telemetry.TrackWarning(ctx, "task_create_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if _, err := s.holidayCalendar(ctx, userID, req.HolidayCalendarID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_create_failed", "task-service", "Holiday calendar not found", nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Holiday calendar not found",
+			}
+		}
+		log.Errorf("error getting holiday calendar: %s", err.Error())
+		telemetry.TrackError(ctx, "task_create_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting holiday calendar",
+		}
+	}
+
+	anchorRecurrenceRule(&req.Frequency, dueDate)
+
 	createdTask := &models.Task{
-		Title:        req.Title,
-		Frequency:    req.Frequency,
-		NextDueDate:  dueDate,
-		EndDate:      endDate,
-		CreatedBy:    userID,
-		IsRolling:    req.IsRolling,
-		IsActive:     true,
-		Notification: req.Notification,
+		Title:             req.Title,
+		Notes:             markdown.Sanitize(req.Notes),
+		Priority:          req.Priority,
+		Frequency:         req.Frequency,
+		NextDueDate:       dueDate,
+		EndDate:           endDate,
+		MaxOccurrences:    req.MaxOccurrences,
+		ActiveWindow:      req.ActiveWindow,
+		BusinessDays:      req.BusinessDays,
+		HolidayCalendarID: req.HolidayCalendarID,
+		HabitTarget:       req.HabitTarget,
+		EnforceDeps:       req.EnforceDeps,
+		CreatedBy:         userID,
+		IsRolling:         req.IsRolling,
+		CatchUp:           req.CatchUp,
+		IsActive:          true,
+		Notification:      req.Notification,
 	}
 
 	id, err := s.t.CreateTask(ctx, createdTask)
@@ -244,8 +782,33 @@ func (s *TaskService) CreateTask(ctx context.Context, userID int, req models.Cre
 }
 
 func (s *TaskService) EditTask(ctx context.Context, userID int, req models.UpdateTaskReq) (int, interface{}) {
+	return s.editTask(ctx, userID, req, nil)
+}
+
+// taskFields holds the fields of an UpdateTaskReq set by a patch, by their
+// JSON names. A nil taskFields stands for a full edit, which sets them all.
+type taskFields map[string]json.RawMessage
+
+func (f taskFields) has(field string) bool {
+	if f == nil {
+		return true
+	}
+	_, ok := f[field]
+	return ok
+}
+
+// editTask saves req over the task. Only the given fields are validated; the
+// others are expected to hold the task's current values.
+func (s *TaskService) editTask(ctx context.Context, userID int, req models.UpdateTaskReq, fields taskFields) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
+	if fields.has("title") && req.Title == "" {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", "Title required", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Title is required",
+		}
+	}
+
 	var dueDate *time.Time
 	if req.NextDueDate != "" {
 		rawDueDate, err := time.Parse(time.RFC3339, req.NextDueDate)
@@ -276,6 +839,83 @@ func (s *TaskService) EditTask(ctx context.Context, userID int, req models.Updat
 		endDate = &rawEndDate
 	}
 
+	if err := tRepo.ValidateFrequency(req.Frequency); fields.has("frequency") && err != nil {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if fields.has("max_occurrences") && req.MaxOccurrences < 0 {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", fmt.Sprintf("Invalid max occurrences: %d", req.MaxOccurrences), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Max occurrences cannot be negative",
+		}
+	}
+
+	if err := tRepo.ValidatePriority(req.Priority); fields.has("priority") && err != nil {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if fields.has("notes") && utf8.RuneCountInString(req.Notes) > maxNotesLength {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", "Notes too long", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Notes must be at most %d characters", maxNotesLength),
+		}
+	}
+
+	if fields.has("habit_target") && (req.HabitTarget < 0 || req.HabitTarget > maxHabitTarget) {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", fmt.Sprintf("Invalid habit target: %d", req.HabitTarget), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Habit target must be between 0 and %d", maxHabitTarget),
+		}
+	}
+
+	if err := tRepo.ValidateActiveWindow(req.ActiveWindow); fields.has("active_window") && err != nil {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if err := tRepo.ValidateCatchUpPolicy(req.CatchUp); fields.has("catch_up") && err != nil {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+	if req.CatchUp == "" {
+		req.CatchUp = models.CatchUpNextOccurrence
+	}
+
+	if err := tRepo.ValidateBusinessDays(req.BusinessDays); fields.has("business_days") && err != nil {
+		telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if fields.has("holiday_calendar_id") {
+		if _, err := s.holidayCalendar(ctx, userID, req.HolidayCalendarID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", "Holiday calendar not found", nil)
+				return http.StatusBadRequest, gin.H{
+					"error": "Holiday calendar not found",
+				}
+			}
+			log.Errorf("error getting holiday calendar: %s", err.Error())
+			telemetry.TrackError(ctx, "task_edit_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": "Error getting holiday calendar",
+			}
+		}
+	}
+
+	anchorRecurrenceRule(&req.Frequency, dueDate)
+
 	taskId := req.ID
 	oldTask, err := s.t.GetTask(ctx, taskId)
 
@@ -295,7 +935,29 @@ func (s *TaskService) EditTask(ctx context.Context, userID int, req models.Updat
 		return http.StatusNotFound, gin.H{"error": "Task not found"}
 	}
 
-	if err := s.l.AssignLabelsToTask(ctx, taskId, userID, req.Labels); err != nil {
+	// Clients predating versions send none; unless versions are required
+	// their edits apply to the current one.
+	if req.Version <= 0 {
+		if s.requireVersion {
+			telemetry.TrackWarning(ctx, "task_edit_failed", "task-service", "Task version required", nil)
+			return http.StatusPreconditionRequired, gin.H{"error": "Task version is required"}
+		}
+		req.Version = oldTask.Version
+	}
+	if req.Version != oldTask.Version {
+		telemetry.TrackWarning(ctx, "task_edit_conflict", "task-service", "Stale task version", nil)
+		return versionConflict(oldTask)
+	}
+
+	// A full edit without labels leaves them alone, while a patch setting
+	// none removes them all. They are saved along with the task, so an edit
+	// losing a version conflict changes neither.
+	var labels []int
+	if fields.has("labels") && (fields != nil || len(req.Labels) > 0) {
+		labels = append([]int{}, req.Labels...)
+	}
+	if len(labels) > 0 && !s.l.AreLabelsAssignableByUser(ctx, userID, labels) {
+		err := errors.New("labels are not assignable by user")
 		log.Errorf("error assigning labels to task: %s", err.Error())
 		telemetry.TrackError(ctx, "task_label_assign_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
@@ -304,18 +966,43 @@ func (s *TaskService) EditTask(ctx context.Context, userID int, req models.Updat
 	}
 
 	updatedTask := &models.Task{
-		ID:           taskId,
-		Title:        req.Title,
-		Frequency:    req.Frequency,
-		NextDueDate:  dueDate,
-		EndDate:      endDate,
-		CreatedBy:    userID,
-		IsRolling:    req.IsRolling,
-		Notification: req.Notification,
-		IsActive:     oldTask.IsActive,
-	}
-
-	if err := s.t.UpsertTask(ctx, updatedTask); err != nil {
+		ID:                taskId,
+		Title:             req.Title,
+		Notes:             markdown.Sanitize(req.Notes),
+		Priority:          req.Priority,
+		Frequency:         req.Frequency,
+		NextDueDate:       dueDate,
+		EndDate:           endDate,
+		MaxOccurrences:    req.MaxOccurrences,
+		ActiveWindow:      req.ActiveWindow,
+		BusinessDays:      req.BusinessDays,
+		HolidayCalendarID: req.HolidayCalendarID,
+		HabitTarget:       req.HabitTarget,
+		EnforceDeps:       req.EnforceDeps,
+		CreatedBy:         userID,
+		IsRolling:         req.IsRolling,
+		CatchUp:           req.CatchUp,
+		Notification:      req.Notification,
+		HabitProgress:     oldTask.HabitProgress,
+		IsActive:          oldTask.IsActive,
+		ArchivedAt:        oldTask.ArchivedAt,
+		Version:           req.Version,
+	}
+
+	edited := *updatedTask
+	edited.Labels = oldTask.Labels
+	if labels != nil {
+		edited.Labels = createShallowLabels(labels)
+	}
+	audit := &models.TaskAudit{
+		UserID:  userID,
+		Changes: tRepo.DiffTask(oldTask, &edited),
+	}
+	if err := s.t.UpsertTask(ctx, updatedTask, labels, audit); err != nil {
+		if errors.Is(err, tRepo.ErrVersionConflict) {
+			telemetry.TrackWarning(ctx, "task_edit_conflict", "task-service", "Stale task version", nil)
+			return s.currentVersionConflict(ctx, taskId)
+		}
 		log.Errorf("error upserting task: %s", err.Error())
 		telemetry.TrackError(ctx, "task_edit_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
@@ -323,7 +1010,7 @@ func (s *TaskService) EditTask(ctx context.Context, userID int, req models.Updat
 		}
 	}
 
-	updatedTask.Labels = createShallowLabels(req.Labels)
+	updatedTask.Labels = edited.Labels
 
 	go func(task *models.Task, logger *zap.SugaredLogger) {
 		ctx := logging.ContextWithLogger(context.Background(), logger)
@@ -335,9 +1022,154 @@ func (s *TaskService) EditTask(ctx context.Context, userID int, req models.Updat
 		Data:   updatedTask,
 	})
 
-	return http.StatusNoContent, nil
+	return http.StatusOK, gin.H{
+		"task": updatedTask,
+	}
+}
+
+// patchableFields are the fields of an UpdateTaskReq a patch may set.
+var patchableFields = map[string]bool{
+	"title":                true,
+	"notes":                true,
+	"priority":             true,
+	"next_due_date":        true,
+	"end_date":             true,
+	"max_occurrences":      true,
+	"is_rolling":           true,
+	"catch_up":             true,
+	"business_days":        true,
+	"holiday_calendar_id":  true,
+	"habit_target":         true,
+	"enforce_dependencies": true,
+	"frequency":            true,
+	"active_window":        true,
+	"notification":         true,
+	"labels":               true,
+}
+
+// PatchTask applies a JSON merge patch to one of the user's tasks, given as
+// the fields of an UpdateTaskReq. Only the fields the patch sets are
+// validated; the others keep their current values. version is the version of
+// the task the patch was made to; if it is 0, the patch may give it in a
+// version member instead.
+func (s *TaskService) PatchTask(ctx context.Context, userID, taskID, version int, patch []byte) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	members, err := mergepatch.Members(patch)
+	if err != nil {
+		telemetry.TrackWarning(ctx, "task_patch_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Patch must be a JSON object",
+		}
+	}
+
+	if raw, ok := members["version"]; ok {
+		delete(members, "version")
+		var patchVersion int
+		if err := json.Unmarshal(raw, &patchVersion); err != nil {
+			telemetry.TrackWarning(ctx, "task_patch_failed", "task-service", "Invalid version: "+string(raw), nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Invalid version",
+			}
+		}
+		if version == 0 {
+			version = patchVersion
+		}
+	}
+	for field := range members {
+		if !patchableFields[field] {
+			telemetry.TrackWarning(ctx, "task_patch_failed", "task-service", "Unknown field: "+field, nil)
+			return http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Field %s cannot be patched", field),
+			}
+		}
+	}
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to patch task", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	current, err := json.Marshal(taskUpdateReq(task))
+	if err != nil {
+		log.Errorf("error encoding task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_patch_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error patching task",
+		}
+	}
+	fieldPatch, err := json.Marshal(members)
+	if err != nil {
+		log.Errorf("error encoding patch: %s", err.Error())
+		telemetry.TrackError(ctx, "task_patch_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error patching task",
+		}
+	}
+
+	var req models.UpdateTaskReq
+	patched, err := mergepatch.Apply(current, fieldPatch)
+	if err == nil {
+		err = json.Unmarshal(patched, &req)
+	}
+	if err != nil {
+		telemetry.TrackWarning(ctx, "task_patch_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Invalid patch: " + err.Error(),
+		}
+	}
+	req.ID = taskID
+	req.Version = version
+
+	return s.editTask(ctx, userID, req, taskFields(members))
+}
+
+// taskUpdateReq returns the full edit that would leave the task as it is.
+func taskUpdateReq(task *models.Task) models.UpdateTaskReq {
+	req := models.UpdateTaskReq{
+		ID:                task.ID,
+		Version:           task.Version,
+		Title:             task.Title,
+		Notes:             task.Notes,
+		Priority:          task.Priority,
+		MaxOccurrences:    task.MaxOccurrences,
+		IsRolling:         task.IsRolling,
+		CatchUp:           task.CatchUp,
+		BusinessDays:      task.BusinessDays,
+		HolidayCalendarID: task.HolidayCalendarID,
+		HabitTarget:       task.HabitTarget,
+		EnforceDeps:       task.EnforceDeps,
+		Frequency:         task.Frequency,
+		ActiveWindow:      task.ActiveWindow,
+		Notification:      task.Notification,
+		Labels:            make([]int, 0, len(task.Labels)),
+	}
+	if task.NextDueDate != nil {
+		req.NextDueDate = task.NextDueDate.UTC().Format(time.RFC3339Nano)
+	}
+	if task.EndDate != nil {
+		req.EndDate = task.EndDate.UTC().Format(time.RFC3339Nano)
+	}
+	for _, label := range task.Labels {
+		req.Labels = append(req.Labels, label.ID)
+	}
+	return req
 }
 
+// DeleteTask moves one of the user's tasks to the trash, from where it can be
+// restored until the trash retention purges it.
 func (s *TaskService) DeleteTask(ctx context.Context, userID, taskID int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
@@ -353,26 +1185,757 @@ func (s *TaskService) DeleteTask(ctx context.Context, userID, taskID int) (int,
 		}
 	}
 
-	if err := s.t.DeleteTask(ctx, taskID); err != nil {
-		log.Errorf("error deleting task: %s", err.Error())
-		telemetry.TrackError(ctx, "task_delete_failed", "task-service", err, nil)
+	if err := s.t.DeleteTask(ctx, taskID); err != nil {
+		log.Errorf("error deleting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_delete_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error deleting task",
+		}
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "task_deleted",
+		Data: gin.H{
+			"id": taskID,
+		},
+	})
+
+	return http.StatusNoContent, nil
+}
+
+func (s *TaskService) SkipTask(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to skip task", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	if task.NextDueDate == nil {
+		telemetry.TrackWarning(ctx, "task_skip_failed", "task-service", "Task has no due date to skip", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Task has no due date to skip",
+		}
+	}
+
+	schedule, err := tRepo.ScheduleCatchUp(task, task.NextDueDate.UTC(), time.Now().UTC(), s.userLocation(ctx, userID))
+	if err != nil {
+		log.Errorf("error scheduling next due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_skip_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error scheduling next due date",
+		}
+	}
+
+	if err := s.keepBusinessDayRoll(ctx, task, schedule); err != nil {
+		log.Errorf("error saving business day roll: %s", err.Error())
+		telemetry.TrackError(ctx, "task_skip_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error scheduling next due date",
+		}
+	}
+
+	if err := s.t.CompleteTask(ctx, task, userID, schedule.NextDueDate, nil, schedule.Missed...); err != nil {
+		log.Errorf("error completing task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_skip_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error completing task",
+		}
+	}
+
+	updatedTask, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting updated task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting updated task",
+		}
+	}
+
+	go func(task *models.Task, logger *zap.SugaredLogger) {
+		ctx := logging.ContextWithLogger(context.Background(), logger)
+		s.n.GenerateNotifications(ctx, task)
+	}(updatedTask, log)
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "task_skipped",
+		Data:   updatedTask,
+	})
+
+	return http.StatusOK, gin.H{
+		"task": updatedTask,
+	}
+}
+
+func (s *TaskService) UpdateDueDate(ctx context.Context, userID, taskID int, req models.UpdateDueDateReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to update due date", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	if req.Version <= 0 {
+		if s.requireVersion {
+			telemetry.TrackWarning(ctx, "task_update_due_date_failed", "task-service", "Task version required", nil)
+			return http.StatusPreconditionRequired, gin.H{"error": "Task version is required"}
+		}
+		req.Version = task.Version
+	}
+	if req.Version != task.Version {
+		telemetry.TrackWarning(ctx, "task_edit_conflict", "task-service", "Stale task version", nil)
+		return versionConflict(task)
+	}
+
+	before := *task
+	if req.DueDate != "" {
+		rawDueDate, err := time.Parse(time.RFC3339, req.DueDate)
+		if err != nil {
+			log.Errorf("error parsing due date: %s", err.Error())
+			telemetry.TrackError(ctx, "task_update_due_date_failed", "task-service", err, nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Due date must be in UTC format",
+			}
+		}
+
+		rawDueDate = rawDueDate.UTC()
+		task.NextDueDate = &rawDueDate
+	}
+
+	audit := &models.TaskAudit{
+		UserID:  userID,
+		Changes: tRepo.DiffTask(&before, task),
+	}
+	if err := s.t.UpsertTask(ctx, task, nil, audit); err != nil {
+		if errors.Is(err, tRepo.ErrVersionConflict) {
+			telemetry.TrackWarning(ctx, "task_edit_conflict", "task-service", "Stale task version", nil)
+			return s.currentVersionConflict(ctx, taskID)
+		}
+		log.Errorf("error updating due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_update_due_date_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error updating due date",
+		}
+	}
+
+	go func(task *models.Task, logger *zap.SugaredLogger) {
+		ctx := logging.ContextWithLogger(context.Background(), logger)
+		s.n.GenerateNotifications(ctx, task)
+	}(task, log)
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "task_updated",
+		Data:   task,
+	})
+
+	return http.StatusOK, gin.H{
+		"task": task,
+	}
+}
+
+// RestoreTask makes one of the user's archived tasks active again, due on the
+// requested date.
+func (s *TaskService) RestoreTask(ctx context.Context, userID, taskID int, req models.RestoreTaskReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to restore task", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	if task.IsActive {
+		return http.StatusConflict, gin.H{"error": "Task is not archived"}
+	}
+
+	dueDate, err := time.Parse(time.RFC3339, req.DueDate)
+	if err != nil {
+		telemetry.TrackWarning(ctx, "task_restore_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Due date must be in UTC format",
+		}
+	}
+
+	if err := s.t.RestoreTask(ctx, taskID, dueDate.UTC()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusConflict, gin.H{"error": "Task is not archived"}
+		}
+		log.Errorf("error restoring task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_restore_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error restoring task",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+// RestoreDeletedTask takes one of the user's tasks out of the trash, as it was
+// when deleted.
+func (s *TaskService) RestoreDeletedTask(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, err := s.t.GetDeletedTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting deleted task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to restore task", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	if err := s.t.UndeleteTask(ctx, taskID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error restoring deleted task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_restore_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error restoring task",
+		}
+	}
+
+	restoredTask, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting restored task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting restored task",
+		}
+	}
+
+	go func(task *models.Task, logger *zap.SugaredLogger) {
+		ctx := logging.ContextWithLogger(context.Background(), logger)
+		s.n.GenerateNotifications(ctx, task)
+	}(restoredTask, log)
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "task_restored",
+		Data:   restoredTask,
+	})
+
+	return http.StatusOK, gin.H{
+		"task": restoredTask,
+	}
+}
+
+// PurgeDeletedTasks permanently deletes the tasks moved to the trash longer
+// ago than retention.
+func (s *TaskService) PurgeDeletedTasks(ctx context.Context, retention time.Duration) error {
+	log := logging.FromContext(ctx)
+
+	purged, err := s.t.PurgeDeletedTasks(ctx, time.Now().UTC().Add(-retention))
+	if purged > 0 {
+		log.Infof("purged %d deleted tasks", purged)
+	}
+	return err
+}
+
+// PurgeArchivedTasks deletes the tasks archived longer ago than retention.
+func (s *TaskService) PurgeArchivedTasks(ctx context.Context, retention time.Duration) error {
+	log := logging.FromContext(ctx)
+
+	purged, err := s.t.PurgeArchivedTasks(ctx, time.Now().UTC().Add(-retention))
+	if purged > 0 {
+		log.Infof("purged %d archived tasks", purged)
+	}
+	return err
+}
+
+func (s *TaskService) GetOccurrenceOverrides(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to view occurrence overrides", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_overrides_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting occurrence overrides",
+		}
+	}
+
+	overrides, err := s.t.GetOccurrenceOverrides(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting occurrence overrides: %s", err.Error())
+		telemetry.TrackError(ctx, "task_overrides_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting occurrence overrides",
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"overrides": overrides,
+	}
+}
+
+func (s *TaskService) SetOccurrenceOverride(ctx context.Context, userID, taskID int, req models.OccurrenceOverrideReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	originalDate, err := time.Parse(time.RFC3339, req.OriginalDate)
+	if err != nil {
+		log.Errorf("error parsing original date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Original date must be in UTC format",
+		}
+	}
+
+	if req.Cancelled == (req.NewDate != "") {
+		telemetry.TrackWarning(ctx, "task_override_failed", "task-service", "Override needs either a new date or a cancellation", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Either a new date or a cancellation is required",
+		}
+	}
+
+	override := &models.OccurrenceOverride{
+		TaskID:       taskID,
+		OriginalDate: originalDate.UTC(),
+	}
+
+	if req.NewDate != "" {
+		newDate, err := time.Parse(time.RFC3339, req.NewDate)
+		if err != nil {
+			log.Errorf("error parsing new date: %s", err.Error())
+			telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "New date must be in UTC format",
+			}
+		}
+
+		newDate = newDate.UTC()
+		override.NewDate = &newDate
+	}
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to override task occurrence", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	if task.Frequency.Type == models.RepeatOnce {
+		telemetry.TrackWarning(ctx, "task_override_failed", "task-service", "Task does not recur", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Only recurring tasks can have occurrence overrides",
+		}
+	}
+
+	if seriesDate := tRepo.SeriesDate(task); seriesDate != nil && override.OriginalDate.Before(*seriesDate) {
+		telemetry.TrackWarning(ctx, "task_override_failed", "task-service", "Occurrence already passed", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Only upcoming occurrences can be overridden",
+		}
+	}
+
+	schedule, err := tRepo.DueDateWithOverride(task, *override, s.userLocation(ctx, userID))
+	if err != nil {
+		log.Errorf("error scheduling next due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error scheduling next due date",
+		}
+	}
+
+	if err := s.keepBusinessDayRoll(ctx, task, schedule); err != nil {
+		log.Errorf("error saving business day roll: %s", err.Error())
+		telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error scheduling next due date",
+		}
+	}
+
+	if err := s.t.SaveOccurrenceOverride(ctx, task, override, schedule.NextDueDate); err != nil {
+		log.Errorf("error saving occurrence override: %s", err.Error())
+		telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error saving occurrence override",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+func (s *TaskService) DeleteOccurrenceOverride(ctx context.Context, userID, taskID, overrideID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to delete occurrence override", nil)
+		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	var override *models.OccurrenceOverride
+	for i := range task.Overrides {
+		if task.Overrides[i].ID == overrideID {
+			override = &task.Overrides[i]
+		}
+	}
+	if override == nil {
+		return http.StatusNotFound, gin.H{"error": "Occurrence override not found"}
+	}
+
+	nextDueDate := tRepo.DueDateWithoutOverride(task, *override)
+	if err := s.t.DeleteOccurrenceOverride(ctx, task, overrideID, nextDueDate); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Occurrence override not found"}
+		}
+		log.Errorf("error deleting occurrence override: %s", err.Error())
+		telemetry.TrackError(ctx, "task_override_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error deleting occurrence override",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+// maxChecklistItemTitle matches the size of the checklist_items.title column.
+const maxChecklistItemTitle = 255
+
+func validateChecklistItemTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return errors.New("checklist item title is required")
+	}
+	if utf8.RuneCountInString(title) > maxChecklistItemTitle {
+		return fmt.Errorf("checklist item title cannot be longer than %d characters", maxChecklistItemTitle)
+	}
+	return nil
+}
+
+func (s *TaskService) GetChecklist(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to view checklist", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting checklist",
+		}
+	}
+
+	items, err := s.t.GetChecklist(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting checklist: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting checklist",
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"checklist": items,
+	}
+}
+
+func (s *TaskService) AddChecklistItem(ctx context.Context, userID, taskID int, req models.CreateChecklistItemReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := validateChecklistItemTitle(req.Title); err != nil {
+		telemetry.TrackWarning(ctx, "task_checklist_failed", "task-service", err.Error(), nil)
+		return http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		}
+	}
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change checklist", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error adding checklist item",
+		}
+	}
+
+	item := &models.ChecklistItem{Title: strings.TrimSpace(req.Title)}
+	if err := s.t.AddChecklistItem(ctx, taskID, item, req.Position); err != nil {
+		log.Errorf("error adding checklist item: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error adding checklist item",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, userID, taskID, itemID int, req models.UpdateChecklistItemReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if req.Title != nil {
+		if err := validateChecklistItemTitle(*req.Title); err != nil {
+			telemetry.TrackWarning(ctx, "task_checklist_failed", "task-service", err.Error(), nil)
+			return http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			}
+		}
+		title := strings.TrimSpace(*req.Title)
+		req.Title = &title
+	}
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change checklist", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error updating checklist item",
+		}
+	}
+
+	if err := s.t.UpdateChecklistItem(ctx, taskID, itemID, req); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Checklist item not found"}
+		}
+		log.Errorf("error updating checklist item: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error updating checklist item",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, userID, taskID, itemID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change checklist", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error deleting checklist item",
+		}
+	}
+
+	if err := s.t.DeleteChecklistItem(ctx, taskID, itemID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Checklist item not found"}
+		}
+		log.Errorf("error deleting checklist item: %s", err.Error())
+		telemetry.TrackError(ctx, "task_checklist_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error deleting checklist item",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+func (s *TaskService) GetDependencies(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to view dependencies", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting dependencies",
+		}
+	}
+
+	blockers, dependents, err := s.t.GetDependencies(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting dependencies: %s", err.Error())
+		telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting dependencies",
+		}
+	}
+
+	return http.StatusOK, gin.H{
+		"blocked_by": blockers,
+		"blocking":   dependents,
+	}
+}
+
+func (s *TaskService) AddDependency(ctx context.Context, userID, taskID int, req models.AddDependencyReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	for _, id := range []int{taskID, req.BlockerID} {
+		if err := s.t.IsTaskOwner(ctx, id, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change dependencies", nil)
+				return http.StatusNotFound, gin.H{"error": "Task not found"}
+			}
+			log.Errorf("error checking task ownership: %s", err.Error())
+			telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": "Error adding dependency",
+			}
+		}
+	}
+
+	if err := s.t.AddDependency(ctx, userID, taskID, req.BlockerID); err != nil {
+		if errors.Is(err, tRepo.ErrDependencyCycle) {
+			telemetry.TrackWarning(ctx, "task_dependencies_failed", "task-service", err.Error(), nil)
+			return http.StatusConflict, gin.H{"error": err.Error()}
+		}
+		log.Errorf("error adding dependency: %s", err.Error())
+		telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error adding dependency",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+func (s *TaskService) RemoveDependency(ctx context.Context, userID, taskID, blockerID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	if err := s.t.IsTaskOwner(ctx, taskID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change dependencies", nil)
+			return http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error checking task ownership: %s", err.Error())
+		telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error removing dependency",
+		}
+	}
+
+	if err := s.t.RemoveDependency(ctx, taskID, blockerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "Dependency not found"}
+		}
+		log.Errorf("error removing dependency: %s", err.Error())
+		telemetry.TrackError(ctx, "task_dependencies_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error removing dependency",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+// broadcastTaskUpdated reloads a task after a change made outside of an edit,
+// refreshes its notifications and sends it to the user's other sessions.
+func (s *TaskService) broadcastTaskUpdated(ctx context.Context, userID, taskID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	updatedTask, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting updated task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
-			"error": "Error deleting task",
+			"error": "Error getting updated task",
 		}
 	}
 
+	go func(task *models.Task, logger *zap.SugaredLogger) {
+		ctx := logging.ContextWithLogger(context.Background(), logger)
+		s.n.GenerateNotifications(ctx, task)
+	}(updatedTask, log)
+
 	s.ws.BroadcastToUser(userID, ws.WSResponse{
-		Action: "task_deleted",
-		Data: gin.H{
-			"id": taskID,
-		},
+		Action: "task_updated",
+		Data:   updatedTask,
 	})
 
-	return http.StatusNoContent, nil
+	return http.StatusOK, gin.H{
+		"task": updatedTask,
+	}
 }
 
-func (s *TaskService) SkipTask(ctx context.Context, userID, taskID int) (int, interface{}) {
+// CompleteTask completes the current occurrence of one of the user's tasks,
+// now or, if completedDate is given, at that earlier time.
+func (s *TaskService) CompleteTask(ctx context.Context, userID, taskID int, endRecurrence bool, completedDate string) (int, interface{}) {
 	log := logging.FromContext(ctx)
+
+	now := time.Now().UTC()
+	completedAt := now
+	if completedDate != "" {
+		var errResponse gin.H
+		completedAt, errResponse = parseCompletedDate(completedDate)
+		if errResponse != nil {
+			telemetry.TrackWarning(ctx, "task_complete_failed", "task-service", "Invalid completed date: "+completedDate, nil)
+			return http.StatusBadRequest, errResponse
+		}
+	}
+
 	task, err := s.t.GetTask(ctx, taskID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -386,29 +1949,45 @@ func (s *TaskService) SkipTask(ctx context.Context, userID, taskID int) (int, in
 	}
 
 	if userID != task.CreatedBy {
-		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to skip task", nil)
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to complete task", nil)
 		return http.StatusNotFound, gin.H{"error": "Task not found"}
 	}
 
-	if task.NextDueDate == nil {
-		telemetry.TrackWarning(ctx, "task_skip_failed", "task-service", "Task has no due date to skip", nil)
-		return http.StatusBadRequest, gin.H{
-			"error": "Task has no due date to skip",
-		}
+	if task.EnforceDeps && task.Blocked {
+		telemetry.TrackWarning(ctx, "task_complete_blocked", "task-service", "Task is blocked by open tasks", nil)
+		return http.StatusConflict, gin.H{"error": "Task is blocked by open tasks"}
 	}
 
-	nextDueDate, err := tRepo.ScheduleNextDueDate(task, task.NextDueDate.UTC())
-	if err != nil {
-		log.Errorf("error scheduling next due date: %s", err.Error())
-		telemetry.TrackError(ctx, "task_skip_failed", "task-service", err, nil)
-		return http.StatusInternalServerError, gin.H{
-			"error": "Error scheduling next due date",
+	if task.HabitTarget > 0 && task.NextDueDate != nil && !endRecurrence {
+		return s.completeHabit(ctx, userID, task, completedAt)
+	}
+
+	var schedule tRepo.Schedule
+
+	if !endRecurrence {
+		// Rolling tasks continue from the completion; fixed ones catch up
+		// with the occurrences that passed until now.
+		schedule, err = tRepo.ScheduleCatchUp(task, completedAt, now, s.userLocation(ctx, userID))
+		if err != nil {
+			log.Errorf("error scheduling next due date: %s", err.Error())
+			telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Error scheduling next due date: %s", err),
+			}
+		}
+
+		if err := s.keepBusinessDayRoll(ctx, task, schedule); err != nil {
+			log.Errorf("error saving business day roll: %s", err.Error())
+			telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": "Error scheduling next due date",
+			}
 		}
 	}
 
-	if err := s.t.CompleteTask(ctx, task, userID, nextDueDate, nil); err != nil {
+	if err := s.t.CompleteTask(ctx, task, userID, schedule.NextDueDate, &completedAt, schedule.Missed...); err != nil {
 		log.Errorf("error completing task: %s", err.Error())
-		telemetry.TrackError(ctx, "task_skip_failed", "task-service", err, nil)
+		telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
 			"error": "Error completing task",
 		}
@@ -429,7 +2008,7 @@ func (s *TaskService) SkipTask(ctx context.Context, userID, taskID int) (int, in
 	}(updatedTask, log)
 
 	s.ws.BroadcastToUser(userID, ws.WSResponse{
-		Action: "task_skipped",
+		Action: "task_completed",
 		Data:   updatedTask,
 	})
 
@@ -438,67 +2017,130 @@ func (s *TaskService) SkipTask(ctx context.Context, userID, taskID int) (int, in
 	}
 }
 
-func (s *TaskService) UpdateDueDate(ctx context.Context, userID, taskID int, req models.UpdateDueDateReq) (int, interface{}) {
+// maxBackfillCompletions bounds how many completions a single backfill may
+// record.
+const maxBackfillCompletions = 100
+
+// BackfillTask records several past completions of one of the user's tasks in
+// one go, as if each had been logged on time: every completion closes the
+// occurrence due then, and the task is scheduled on from the latest one.
+func (s *TaskService) BackfillTask(ctx context.Context, userID, taskID int, req models.BackfillTaskReq) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
-	task, err := s.t.GetTask(ctx, taskID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return http.StatusNotFound, gin.H{"error": "Task not found"}
+	if len(req.CompletedDates) == 0 || len(req.CompletedDates) > maxBackfillCompletions {
+		telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", "Invalid number of completions", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Between 1 and %d completions can be backfilled at once", maxBackfillCompletions),
 		}
-		log.Errorf("error getting task: %s", err.Error())
-		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
-		return http.StatusInternalServerError, gin.H{
-			"error": "Error getting task",
+	}
+
+	completedDates := make([]time.Time, 0, len(req.CompletedDates))
+	for _, raw := range req.CompletedDates {
+		completedDate, errResponse := parseCompletedDate(raw)
+		if errResponse != nil {
+			telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", "Invalid completed date: "+raw, nil)
+			return http.StatusBadRequest, errResponse
 		}
+		completedDates = append(completedDates, completedDate)
 	}
+	slices.SortFunc(completedDates, time.Time.Compare)
 
-	if userID != task.CreatedBy {
-		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to update due date", nil)
-		return http.StatusNotFound, gin.H{"error": "Task not found"}
+	task, history, status, response := s.taskWithHistory(ctx, userID, taskID, 0)
+	if task == nil {
+		return status, response
 	}
 
-	if req.DueDate != "" {
-		rawDueDate, err := time.Parse(time.RFC3339, req.DueDate)
-		if err != nil {
-			log.Errorf("error parsing due date: %s", err.Error())
-			telemetry.TrackError(ctx, "task_update_due_date_failed", "task-service", err, nil)
+	if !task.IsActive {
+		return http.StatusConflict, gin.H{"error": "Task is archived"}
+	}
+	if task.HabitTarget > 0 {
+		telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", "Habit tasks cannot be backfilled", nil)
+		return http.StatusBadRequest, gin.H{
+			"error": "Habit tasks cannot be backfilled",
+		}
+	}
+	for _, entry := range history {
+		if entry.CompletedDate != nil && completedDates[0].Before(*entry.CompletedDate) {
+			telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", "Completion before the last recorded one", nil)
 			return http.StatusBadRequest, gin.H{
-				"error": "Due date must be in UTC format",
+				"error": "Completions cannot be earlier than the last recorded one",
 			}
 		}
+	}
 
-		rawDueDate = rawDueDate.UTC()
-		task.NextDueDate = &rawDueDate
+	backfill, err := tRepo.ScheduleBackfill(task, len(history), completedDates, time.Now().UTC(), s.userLocation(ctx, userID))
+	if err != nil {
+		if errors.Is(err, tRepo.ErrOccurrencesUsedUp) {
+			telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", err.Error(), nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Task has fewer occurrences left than completions",
+			}
+		}
+		if errors.Is(err, tRepo.ErrSeriesEnded) {
+			telemetry.TrackWarning(ctx, "task_backfill_failed", "task-service", err.Error(), nil)
+			if task.EndDate != nil {
+				return http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("Task ends on %s, before all completions", task.EndDate.UTC().Format(time.RFC3339)),
+				}
+			}
+			return http.StatusBadRequest, gin.H{
+				"error": "Task series ends before all completions",
+			}
+		}
+		log.Errorf("error scheduling next due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_backfill_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Error scheduling next due date: %s", err),
+		}
 	}
 
-	if err := s.t.UpsertTask(ctx, task); err != nil {
-		log.Errorf("error updating due date: %s", err.Error())
-		telemetry.TrackError(ctx, "task_update_due_date_failed", "task-service", err, nil)
+	if err := s.t.BackfillTask(ctx, task.ID, backfill); err != nil {
+		log.Errorf("error backfilling task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_backfill_failed", "task-service", err, nil)
 		return http.StatusInternalServerError, gin.H{
-			"error": "Error updating due date",
+			"error": "Error backfilling task",
+		}
+	}
+
+	updatedTask, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting updated task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error getting updated task",
 		}
 	}
 
 	go func(task *models.Task, logger *zap.SugaredLogger) {
 		ctx := logging.ContextWithLogger(context.Background(), logger)
 		s.n.GenerateNotifications(ctx, task)
-	}(task, log)
+	}(updatedTask, log)
 
 	s.ws.BroadcastToUser(userID, ws.WSResponse{
-		Action: "task_updated",
-		Data:   task,
+		Action: "task_completed",
+		Data:   updatedTask,
 	})
 
 	return http.StatusOK, gin.H{
-		"task": task,
+		"task": updatedTask,
 	}
 }
 
-func (s *TaskService) CompleteTask(ctx context.Context, userID, taskID int, endRecurrence bool) (int, interface{}) {
+// versionConflict is the response to an edit made to an outdated version of a
+// task. It carries the current task so the client can reconcile its copy.
+func versionConflict(current *models.Task) (int, interface{}) {
+	return http.StatusConflict, gin.H{
+		"error": "Task was changed by another client",
+		"task":  current,
+	}
+}
+
+// currentVersionConflict reloads a task found to have changed while an edit
+// was being saved and returns the conflict response for it.
+func (s *TaskService) currentVersionConflict(ctx context.Context, taskID int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
-	task, err := s.t.GetTask(ctx, taskID)
+	current, err := s.t.GetTask(ctx, taskID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return http.StatusNotFound, gin.H{"error": "Task not found"}
@@ -509,35 +2151,94 @@ func (s *TaskService) CompleteTask(ctx context.Context, userID, taskID int, endR
 			"error": "Error getting task",
 		}
 	}
+	return versionConflict(current)
+}
 
-	if userID != task.CreatedBy {
-		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to complete task", nil)
-		return http.StatusNotFound, gin.H{"error": "Task not found"}
+// parseCompletedDate parses a completion date received from a client, which
+// may not be in the future. On failure it returns the error response.
+func parseCompletedDate(raw string) (time.Time, gin.H) {
+	completedDate, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, gin.H{
+			"error": "Completed date must be in UTC format",
+		}
+	}
+
+	completedDate = completedDate.UTC()
+	if completedDate.After(time.Now().UTC()) {
+		return time.Time{}, gin.H{
+			"error": "Completed date cannot be in the future",
+		}
 	}
+	return completedDate, nil
+}
 
-	completedDate := time.Now().UTC()
-	var nextDueDate *time.Time = nil
+// completeHabit counts a completion toward the current period of a habit
+// task. The period is recorded and the task moves on only once its target is
+// met; a completion after the period ended counts toward the following one.
+// A backdated completion still counts toward the current period.
+func (s *TaskService) completeHabit(ctx context.Context, userID int, task *models.Task, completedDate time.Time) (int, interface{}) {
+	log := logging.FromContext(ctx)
 
-	if !endRecurrence {
-		nextDueDate, err = tRepo.ScheduleNextDueDate(task, completedDate)
-		if err != nil {
-			log.Errorf("error scheduling next due date: %s", err.Error())
+	if now := time.Now().UTC(); !task.NextDueDate.After(now) {
+		if err := s.closeHabitPeriod(ctx, task, now); err != nil {
+			log.Errorf("error closing habit period: %s", err.Error())
 			telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
 			return http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Error scheduling next due date: %s", err),
+				"error": "Error completing task",
+			}
+		}
+
+		reloaded, err := s.t.GetTask(ctx, task.ID)
+		if err != nil {
+			log.Errorf("error getting task: %s", err.Error())
+			telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": "Error getting task",
 			}
 		}
+		task = reloaded
 	}
 
-	if err := s.t.CompleteTask(ctx, task, userID, nextDueDate, &completedDate); err != nil {
-		log.Errorf("error completing task: %s", err.Error())
-		telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
-		return http.StatusInternalServerError, gin.H{
-			"error": "Error completing task",
+	// The series may have ended with the period that just closed.
+	if task.NextDueDate != nil {
+		if err := s.t.AddHabitProgress(ctx, task); err != nil {
+			log.Errorf("error adding habit progress: %s", err.Error())
+			telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+			return http.StatusInternalServerError, gin.H{
+				"error": "Error completing task",
+			}
+		}
+
+		if task.HabitProgress >= task.HabitTarget {
+			schedule, err := tRepo.ScheduleCatchUp(task, completedDate, time.Now().UTC(), s.userLocation(ctx, userID))
+			if err != nil {
+				log.Errorf("error scheduling next due date: %s", err.Error())
+				telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+				return http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("Error scheduling next due date: %s", err),
+				}
+			}
+
+			if err := s.keepBusinessDayRoll(ctx, task, schedule); err != nil {
+				log.Errorf("error saving business day roll: %s", err.Error())
+				telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+				return http.StatusInternalServerError, gin.H{
+					"error": "Error scheduling next due date",
+				}
+			}
+
+			if err := s.t.CompleteTask(ctx, task, userID, schedule.NextDueDate, &completedDate, schedule.Missed...); err != nil {
+				log.Errorf("error completing task: %s", err.Error())
+				telemetry.TrackError(ctx, "task_complete_failed", "task-service", err, nil)
+				return http.StatusInternalServerError, gin.H{
+					"error": "Error completing task",
+				}
+			}
 		}
 	}
 
-	updatedTask, err := s.t.GetTask(ctx, taskID)
+	updatedTask, err := s.t.GetTask(ctx, task.ID)
 	if err != nil {
 		log.Errorf("error getting updated task: %s", err.Error())
 		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
@@ -561,7 +2262,85 @@ func (s *TaskService) CompleteTask(ctx context.Context, userID, taskID int, endR
 	}
 }
 
-func (s *TaskService) RevertAction(ctx context.Context, userID, taskID, historyID int) (int, interface{}) {
+// closeHabitPeriod records the ended period of a habit task with the progress
+// it made and moves the task on to the period containing now. Periods that
+// passed entirely in between are recorded with no progress.
+func (s *TaskService) closeHabitPeriod(ctx context.Context, task *models.Task, now time.Time) error {
+	lapsed := *task
+	lapsed.CatchUp = models.CatchUpSkipMissed
+
+	schedule, err := tRepo.ScheduleCatchUp(&lapsed, now, now, s.userLocation(ctx, task.CreatedBy))
+	if err != nil {
+		return err
+	}
+
+	if err := s.keepBusinessDayRoll(ctx, task, schedule); err != nil {
+		return err
+	}
+
+	return s.t.CompleteTask(ctx, task, task.CreatedBy, schedule.NextDueDate, nil, schedule.Missed...)
+}
+
+// CloseLapsedHabitPeriods records the habit periods that ended without
+// reaching their target, so the tasks move on even if nobody completes them.
+func (s *TaskService) CloseLapsedHabitPeriods(ctx context.Context) error {
+	log := logging.FromContext(ctx)
+
+	tasks, err := s.t.GetLapsedHabitTasks(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := s.closeHabitPeriod(ctx, task, time.Now().UTC()); err != nil {
+			log.Errorf("failed to close habit period of task %d: %s", task.ID, err.Error())
+			telemetry.TrackError(ctx, "habit_period_close_failed", "task-service", err, map[string]string{
+				"task_id": fmt.Sprint(task.ID),
+			})
+			continue
+		}
+
+		updatedTask, err := s.t.GetTask(ctx, task.ID)
+		if err != nil {
+			log.Errorf("failed to get task %d: %s", task.ID, err.Error())
+			continue
+		}
+
+		s.n.GenerateNotifications(ctx, updatedTask)
+		s.ws.BroadcastToUser(task.CreatedBy, ws.WSResponse{
+			Action: "task_updated",
+			Data:   updatedTask,
+		})
+	}
+
+	return nil
+}
+
+// holidayCalendar loads the user's holiday calendar with the given ID, if
+// any. It returns gorm.ErrRecordNotFound if the calendar belongs to someone
+// else.
+func (s *TaskService) holidayCalendar(ctx context.Context, userID int, calendarID *int) (*models.HolidayCalendar, error) {
+	if calendarID == nil {
+		return nil, nil
+	}
+	return s.c.GetUserCalendar(ctx, userID, *calendarID)
+}
+
+// keepBusinessDayRoll stores the override that pins a next due date rolled to
+// a business day, so the series is still scheduled from its original date.
+// The task's current due date is left alone; the caller moves it on.
+func (s *TaskService) keepBusinessDayRoll(ctx context.Context, task *models.Task, schedule tRepo.Schedule) error {
+	roll := schedule.RollOverride(task.ID)
+	if roll == nil {
+		return nil
+	}
+	return s.t.SaveOccurrenceOverride(ctx, task, roll, task.NextDueDate)
+}
+
+// RevertAction undoes a history entry of one of the user's tasks and the
+// steps-1 entries recorded after it. It fails with a conflict if more entries
+// were recorded since, so that an undo never takes newer actions with it.
+func (s *TaskService) RevertAction(ctx context.Context, userID, taskID, historyID, steps int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 	task, err := s.t.GetTask(ctx, taskID)
 	if err != nil {
@@ -580,7 +2359,7 @@ func (s *TaskService) RevertAction(ctx context.Context, userID, taskID, historyI
 		return http.StatusNotFound, gin.H{"error": "Task not found"}
 	}
 
-	if err := s.t.RevertActivity(ctx, taskID, historyID); err != nil {
+	if err := s.t.RevertActivity(ctx, taskID, historyID, steps); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, tRepo.ErrActivityNotLatest) {
 			return http.StatusConflict, gin.H{
 				"error": "This action can no longer be reverted",
@@ -618,6 +2397,125 @@ func (s *TaskService) RevertAction(ctx context.Context, userID, taskID, historyI
 	}
 }
 
+// UpdateTaskHistory corrects the completion date of a history entry of one of
+// the user's tasks. Correcting the latest entry reschedules the task from the
+// corrected history.
+func (s *TaskService) UpdateTaskHistory(ctx context.Context, userID, taskID, historyID int, req models.UpdateTaskHistoryReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	completedDate, errResponse := parseCompletedDate(req.CompletedDate)
+	if errResponse != nil {
+		telemetry.TrackWarning(ctx, "task_history_update_failed", "task-service", "Invalid completed date: "+req.CompletedDate, nil)
+		return http.StatusBadRequest, errResponse
+	}
+
+	task, history, status, response := s.taskWithHistory(ctx, userID, taskID, historyID)
+	if task == nil {
+		return status, response
+	}
+
+	for _, entry := range history {
+		if entry.ID == historyID {
+			entry.CompletedDate = &completedDate
+		}
+	}
+
+	nextDueDate, err := tRepo.ScheduleFromHistory(task, history, s.userLocation(ctx, userID))
+	if err != nil {
+		log.Errorf("error scheduling next due date: %s", err.Error())
+		telemetry.TrackError(ctx, "task_history_update_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error scheduling next due date",
+		}
+	}
+
+	if err := s.t.UpdateActivity(ctx, taskID, historyID, completedDate, nextDueDate); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "History entry not found"}
+		}
+		log.Errorf("error updating task history: %s", err.Error())
+		telemetry.TrackError(ctx, "task_history_update_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error updating task history",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+// DeleteTaskHistory deletes a history entry of one of the user's tasks.
+// Deleting the latest entry reverts it, reopening the occurrence it closed;
+// older entries leave the task's schedule alone.
+func (s *TaskService) DeleteTaskHistory(ctx context.Context, userID, taskID, historyID int) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, history, status, response := s.taskWithHistory(ctx, userID, taskID, historyID)
+	if task == nil {
+		return status, response
+	}
+
+	latest := slices.MaxFunc(history, func(a, b *models.TaskHistory) int {
+		return a.ID - b.ID
+	})
+	if latest.ID == historyID {
+		return s.RevertAction(ctx, userID, taskID, historyID, 1)
+	}
+
+	if err := s.t.DeleteActivity(ctx, taskID, historyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http.StatusNotFound, gin.H{"error": "History entry not found"}
+		}
+		log.Errorf("error deleting task history: %s", err.Error())
+		telemetry.TrackError(ctx, "task_history_delete_failed", "task-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Error deleting task history",
+		}
+	}
+
+	return s.broadcastTaskUpdated(ctx, userID, taskID)
+}
+
+// taskWithHistory loads one of the user's tasks and its history, which must
+// include the given entry unless historyID is zero. If it cannot, the task is
+// nil and the status and response to return are set instead.
+func (s *TaskService) taskWithHistory(ctx context.Context, userID, taskID, historyID int) (*models.Task, []*models.TaskHistory, int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	task, err := s.t.GetTask(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, http.StatusNotFound, gin.H{"error": "Task not found"}
+		}
+		log.Errorf("error getting task: %s", err.Error())
+		telemetry.TrackError(ctx, "task_get_failed", "task-service", err, nil)
+		return nil, nil, http.StatusInternalServerError, gin.H{
+			"error": "Error getting task",
+		}
+	}
+
+	if userID != task.CreatedBy {
+		telemetry.TrackWarning(ctx, "task_not_found", "task-service", "User not allowed to change task history", nil)
+		return nil, nil, http.StatusNotFound, gin.H{"error": "Task not found"}
+	}
+
+	history, err := s.t.GetTaskHistory(ctx, taskID)
+	if err != nil {
+		log.Errorf("error getting task history: %s", err.Error())
+		telemetry.TrackError(ctx, "task_history_failed", "task-service", err, nil)
+		return nil, nil, http.StatusInternalServerError, gin.H{
+			"error": "Error getting task history",
+		}
+	}
+
+	if historyID != 0 && !slices.ContainsFunc(history, func(entry *models.TaskHistory) bool {
+		return entry.ID == historyID
+	}) {
+		return nil, nil, http.StatusNotFound, gin.H{"error": "History entry not found"}
+	}
+
+	return task, history, 0, nil
+}
+
 func (s *TaskService) GetTaskHistory(ctx context.Context, userID, taskID int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 
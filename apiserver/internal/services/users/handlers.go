@@ -36,6 +36,25 @@ func (h *UsersMessageHandler) updateNotificationSettings(ctx context.Context, us
 	}
 }
 
+func (h *UsersMessageHandler) updateTimezone(ctx context.Context, userID int, msg ws.WSMessage) *ws.WSResponse {
+	var req models.UpdateTimezoneReq
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		return &ws.WSResponse{
+			Status: http.StatusBadRequest,
+			Data: gin.H{
+				"error": "Invalid request data",
+			},
+		}
+	}
+
+	status, response := h.us.UpdateTimezone(ctx, userID, req)
+	return &ws.WSResponse{
+		Status: status,
+		Data:   response,
+	}
+}
+
 func UserMessages(ws *ws.WSServer, h *UsersMessageHandler) {
 	ws.RegisterHandler("update_notification_settings", h.updateNotificationSettings)
+	ws.RegisterHandler("update_timezone", h.updateTimezone)
 }
@@ -58,6 +58,38 @@ func (s *UserService) UpdateNotificationSettings(ctx context.Context, userID int
 	return http.StatusNoContent, gin.H{}
 }
 
+func (s *UserService) UpdateTimezone(ctx context.Context, userID int, req models.UpdateTimezoneReq) (int, interface{}) {
+	log := logging.FromContext(ctx)
+
+	// An empty time zone resets the user to UTC. "Local" is rejected because it
+	// would resolve to the server's zone rather than the user's.
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+			telemetry.TrackWarning(ctx, "timezone_update_failed", "user-service", "Invalid time zone: "+req.Timezone, nil)
+			return http.StatusBadRequest, gin.H{
+				"error": "Time zone must be a valid IANA time zone name",
+			}
+		}
+	}
+
+	if err := s.r.UpdateTimezone(ctx, userID, req.Timezone); err != nil {
+		log.Errorf("failed to update time zone: %s", err.Error())
+		telemetry.TrackError(ctx, "timezone_update_failed", "user-service", err, nil)
+		return http.StatusInternalServerError, gin.H{
+			"error": "Failed to update time zone",
+		}
+	}
+
+	s.ws.BroadcastToUser(userID, ws.WSResponse{
+		Action: "timezone_updated",
+		Data: gin.H{
+			"timezone": req.Timezone,
+		},
+	})
+
+	return http.StatusNoContent, gin.H{}
+}
+
 func (s *UserService) RequestDeletion(ctx context.Context, userID int) (int, interface{}) {
 	log := logging.FromContext(ctx)
 	if err := s.r.RequestDeletion(ctx, userID); err != nil {
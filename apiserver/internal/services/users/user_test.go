@@ -76,6 +76,30 @@ func (s *UserServiceTestSuite) TestCancelDeletion_Success() {
 	s.Nil(updated.DeletionRequestedAt)
 }
 
+func (s *UserServiceTestSuite) TestUpdateTimezone_Success() {
+	user := s.createUser()
+
+	status, _ := s.service.UpdateTimezone(context.Background(), user.ID, models.UpdateTimezoneReq{Timezone: "Europe/Paris"})
+	s.Equal(http.StatusNoContent, status)
+
+	var updated models.User
+	s.Require().NoError(s.DB.First(&updated, user.ID).Error)
+	s.Equal("Europe/Paris", updated.Timezone)
+}
+
+func (s *UserServiceTestSuite) TestUpdateTimezone_RejectsInvalidZone() {
+	user := s.createUser()
+
+	for _, tz := range []string{"Mars/Olympus_Mons", "Local"} {
+		status, _ := s.service.UpdateTimezone(context.Background(), user.ID, models.UpdateTimezoneReq{Timezone: tz})
+		s.Equal(http.StatusBadRequest, status, tz)
+	}
+
+	var updated models.User
+	s.Require().NoError(s.DB.First(&updated, user.ID).Error)
+	s.Empty(updated.Timezone)
+}
+
 func (s *UserServiceTestSuite) TestProcessDeletions_DeletesExpiredUsers() {
 	ctx := context.Background()
 	user := s.createUser()
@@ -0,0 +1,154 @@
+// Package ics reads the all-day events of an iCalendar (RFC 5545) file, which
+// is how public holiday calendars are usually published.
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of the dates returned by ParseDates.
+const DateLayout = "2006-01-02"
+
+// maxEventDays bounds how many days a single event may span, so that a
+// malformed DTEND cannot expand into an unbounded number of dates.
+const maxEventDays = 366
+
+// Date is a single day covered by an event.
+type Date struct {
+	Date    string
+	Summary string
+}
+
+type event struct {
+	start   string
+	end     string
+	summary string
+}
+
+// ParseDates returns every day covered by the events in an iCalendar file, in
+// file order. Timed events count for the day they start on; multi-day
+// all-day events are expanded into one entry per day, with DTEND exclusive as
+// RFC 5545 specifies. Recurring events are not expanded.
+func ParseDates(data string) ([]Date, error) {
+	lines, err := unfold(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []Date
+	var current *event
+	sawCalendar := false
+
+	for _, line := range lines {
+		name, value := splitLine(line)
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			sawCalendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			current = &event{}
+		case name == "END" && value == "VEVENT":
+			if current == nil {
+				return nil, errors.New("END:VEVENT without BEGIN:VEVENT")
+			}
+			expanded, err := current.dates()
+			if err != nil {
+				return nil, err
+			}
+			dates = append(dates, expanded...)
+			current = nil
+		case current == nil:
+			continue
+		case name == "DTSTART":
+			current.start = dateValue(value)
+		case name == "DTEND":
+			current.end = dateValue(value)
+		case name == "SUMMARY":
+			current.summary = unescape(value)
+		}
+	}
+
+	if !sawCalendar {
+		return nil, errors.New("not an iCalendar file")
+	}
+	if current != nil {
+		return nil, errors.New("unterminated VEVENT")
+	}
+
+	return dates, nil
+}
+
+func (e *event) dates() ([]Date, error) {
+	if e.start == "" {
+		return nil, errors.New("event without DTSTART")
+	}
+
+	start, err := time.Parse("20060102", e.start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DTSTART %q", e.start)
+	}
+
+	end := start.AddDate(0, 0, 1)
+	if e.end != "" {
+		end, err = time.Parse("20060102", e.end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DTEND %q", e.end)
+		}
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+	}
+
+	var dates []Date
+	for day := start; day.Before(end) && len(dates) < maxEventDays; day = day.AddDate(0, 0, 1) {
+		dates = append(dates, Date{Date: day.Format(DateLayout), Summary: e.summary})
+	}
+	return dates, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(data string) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// splitLine splits "NAME;PARAM=X:VALUE" into its upper-cased name and its
+// value. Parameters such as VALUE=DATE or TZID are not needed to read the day.
+func splitLine(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+
+	name, _, _ := strings.Cut(line[:colon], ";")
+	return strings.ToUpper(name), line[colon+1:]
+}
+
+// dateValue returns the YYYYMMDD part of a DATE or DATE-TIME value.
+func dateValue(value string) string {
+	if len(value) > 8 {
+		return value[:8]
+	}
+	return value
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
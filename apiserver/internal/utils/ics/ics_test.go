@@ -0,0 +1,55 @@
+package ics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const holidays = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20251225\r\n" +
+	"DTEND;VALUE=DATE:20251226\r\n" +
+	"SUMMARY:Christmas Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20251231\r\n" +
+	"DTEND;VALUE=DATE:20260102\r\n" +
+	"SUMMARY:New Year\\, observed\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20251003T000000\r\n" +
+	"SUMMARY:Tag der Deutschen\r\n" +
+	"  Einheit\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseDates(t *testing.T) {
+	dates, err := ParseDates(holidays)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Date{
+		{Date: "2025-12-25", Summary: "Christmas Day"},
+		{Date: "2025-12-31", Summary: "New Year, observed"},
+		{Date: "2026-01-01", Summary: "New Year, observed"},
+		{Date: "2025-10-03", Summary: "Tag der Deutschen Einheit"},
+	}, dates)
+}
+
+func TestParseDatesRejectsInvalidFiles(t *testing.T) {
+	invalid := []string{
+		"",
+		"hello world",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20251225\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No date\nEND:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2025-12-25\nEND:VEVENT\nEND:VCALENDAR",
+	}
+
+	for _, data := range invalid {
+		_, err := ParseDates(data)
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,216 @@
+// Package markdown reduces user supplied markdown to a subset that is safe to
+// render: raw HTML is removed and links may only point to web or mail
+// addresses. Code spans and fenced code blocks are left untouched, since
+// renderers show their contents literally.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?(-->|$)`)
+	htmlTag     = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(\s[^<>]*)?/?>|<[!?][^<>]*>`)
+	autolink    = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9+.-]*):[^\s<>]*>`)
+	refLink     = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*<?([^\s<>]*)>?.*$`)
+	codeSpan    = regexp.MustCompile("`+")
+)
+
+// safeSchemes are the URL schemes links may use. Relative URLs, which have
+// no scheme, are always allowed.
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Sanitize returns s with raw HTML, control characters and the targets of
+// links to unsafe schemes removed. Line endings are normalised to "\n" and surrounding blank
+// space is trimmed.
+func Sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, s)
+
+	var out []string
+	var block []string
+	fence := ""
+	flush := func() {
+		if len(block) > 0 {
+			out = append(out, sanitizeText(strings.Join(block, "\n")))
+			block = nil
+		}
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			out = append(out, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			flush()
+			fence = marker
+			out = append(out, line)
+			continue
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// fenceMarker returns the run of backticks or tildes opening a fenced code
+// block, or "" if line does not open one.
+func fenceMarker(line string) string {
+	for _, c := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, c) {
+			return line[:len(line)-len(strings.TrimLeft(line, c[:1]))]
+		}
+	}
+	return ""
+}
+
+// sanitizeText cleans a run of lines outside fenced code blocks, skipping the
+// contents of code spans.
+func sanitizeText(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		open := codeSpan.FindStringIndex(s)
+		if open == nil {
+			b.WriteString(sanitizeProse(s))
+			break
+		}
+		b.WriteString(sanitizeProse(s[:open[0]]))
+
+		// A code span ends at the next run of exactly as many backticks.
+		ticks := s[open[0]:open[1]]
+		rest := s[open[1]:]
+		end := -1
+		for _, loc := range codeSpan.FindAllStringIndex(rest, -1) {
+			if loc[1]-loc[0] == len(ticks) {
+				end = loc[1]
+				break
+			}
+		}
+		if end < 0 {
+			b.WriteString(ticks)
+			s = rest
+			continue
+		}
+		b.WriteString(ticks)
+		b.WriteString(rest[:end])
+		s = rest[end:]
+	}
+	return b.String()
+}
+
+func sanitizeProse(s string) string {
+	s = htmlComment.ReplaceAllString(s, "")
+	s = autolink.ReplaceAllStringFunc(s, func(m string) string {
+		if isSafeURL(m[1 : len(m)-1]) {
+			return m
+		}
+		return ""
+	})
+	// Removing one tag can join the text around it into another.
+	for {
+		stripped := htmlTag.ReplaceAllString(s, "")
+		if stripped == s {
+			break
+		}
+		s = stripped
+	}
+	s = stripUnsafeDestinations(s)
+
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if parts := refLink.FindStringSubmatch(line); parts != nil && !isSafeURL(parts[1]) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// stripUnsafeDestinations empties the destination of every inline link or
+// image whose URL is not safe, leaving its text in place.
+func stripUnsafeDestinations(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "](")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		start := i + 2
+		b.WriteString(s[:start])
+		s = s[start:]
+
+		dest := linkDestination(s)
+		if isSafeURL(strings.TrimSpace(dest)) {
+			b.WriteString(dest)
+		}
+		s = s[len(dest):]
+	}
+}
+
+// linkDestination returns the prefix of s, which follows the "](" of an
+// inline link, that holds the link destination: either an angle-bracketed
+// URL or a run of non-space characters with balanced parentheses.
+func linkDestination(s string) string {
+	lead := len(s) - len(strings.TrimLeft(s, " \t\n"))
+	rest := s[lead:]
+	if strings.HasPrefix(rest, "<") {
+		if end := strings.IndexAny(rest, ">\n"); end >= 0 && rest[end] == '>' {
+			return s[:lead+end+1]
+		}
+		return s[:lead]
+	}
+
+	depth := 0
+	for i, r := range rest {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth == 0 {
+				return s[:lead+i]
+			}
+			depth--
+		case unicode.IsSpace(r):
+			return s[:lead+i]
+		}
+	}
+	return s
+}
+
+// isSafeURL reports whether u is relative or uses one of the safe schemes.
+func isSafeURL(u string) bool {
+	// Renderers decode entities in URLs, so "javascript&#58;" is a scheme.
+	u = strings.TrimSpace(html.UnescapeString(u))
+	u = strings.Trim(u, "<>")
+	colon := strings.IndexByte(u, ':')
+	if colon < 0 {
+		return true
+	}
+	// A slash, '?' or '#' before the colon means it is part of a relative
+	// path, query or fragment rather than a scheme.
+	if strings.ContainsAny(u[:colon], "/?#") {
+		return true
+	}
+	return safeSchemes[strings.ToLower(u[:colon])]
+}
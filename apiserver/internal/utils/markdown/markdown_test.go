@@ -0,0 +1,67 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain markdown is unchanged",
+			in:   "# Filter\n\n- part **FX-220**\n- [manual](https://example.com/fx220.pdf)",
+			want: "# Filter\n\n- part **FX-220**\n- [manual](https://example.com/fx220.pdf)",
+		},
+		{
+			name: "line endings and control characters",
+			in:   "  Colour: RAL 7016\r\nFinish:\x00 matt\r\n\n",
+			want: "Colour: RAL 7016\nFinish: matt",
+		},
+		{
+			name: "raw html is removed",
+			in:   "Use <b>two</b> coats<script>alert(1)</script><!-- hidden -->.",
+			want: "Use two coatsalert(1).",
+		},
+		{
+			name: "tags rebuilt by stripping are removed too",
+			in:   "<<b>script>alert(1)<</b>/script>",
+			want: "alert(1)",
+		},
+		{
+			name: "unsafe inline links lose their target",
+			in:   "[click](javascript:alert(1)) ![x](data:image/png;base64,AAAA) [y](JavaScript&#58;alert(1) \"t\")",
+			want: "[click]() ![x]() [y]( \"t\")",
+		},
+		{
+			name: "relative and mail links are kept",
+			in:   "[docs](/tasks/1?tab=notes#a:b) [mail](mailto:me@example.com)",
+			want: "[docs](/tasks/1?tab=notes#a:b) [mail](mailto:me@example.com)",
+		},
+		{
+			name: "autolinks",
+			in:   "<https://example.com> <javascript:alert(1)>",
+			want: "<https://example.com>",
+		},
+		{
+			name: "unsafe reference definitions are dropped",
+			in:   "[a][x]\n\n[x]: vbscript:msgbox\n[y]: https://example.com",
+			want: "[a][x]\n\n[y]: https://example.com",
+		},
+		{
+			name: "code is left alone",
+			in:   "Run `<b>` here\n\n```html\n<script>x</script>\n```\n<i>done</i>",
+			want: "Run `<b>` here\n\n```html\n<script>x</script>\n```\ndone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.in))
+		})
+	}
+}
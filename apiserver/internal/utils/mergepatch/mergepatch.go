@@ -0,0 +1,73 @@
+// Package mergepatch applies JSON merge patches as defined by RFC 7396: the
+// members of a patch object replace those of the target, recursively for
+// objects, and null members remove them.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotObject is returned for a patch that is not a JSON object. Patching a
+// whole document with anything else would replace it outright, which is of no
+// use for editing a resource.
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Members decodes the top-level members of patch.
+func Members(patch []byte) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil || members == nil {
+		return nil, ErrNotObject
+	}
+	return members, nil
+}
+
+// Apply returns the JSON document target with patch merged into it.
+func Apply(target, patch []byte) ([]byte, error) {
+	if _, err := Members(patch); err != nil {
+		return nil, err
+	}
+
+	doc, err := decode(target)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(doc, p))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+			continue
+		}
+		t[name] = merge(t[name], value)
+	}
+	return t
+}
+
+// decode keeps numbers as written, so large integers survive the round trip.
+func decode(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,69 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{
+			name:   "members are replaced",
+			target: `{"title":"Pay rent","priority":1}`,
+			patch:  `{"title":"Pay the rent"}`,
+			want:   `{"title":"Pay the rent","priority":1}`,
+		},
+		{
+			name:   "null removes a member",
+			target: `{"title":"Pay rent","next_due_date":"2026-03-02T09:00:00Z"}`,
+			patch:  `{"next_due_date":null}`,
+			want:   `{"title":"Pay rent"}`,
+		},
+		{
+			name:   "objects are merged",
+			target: `{"frequency":{"type":"interval","every":2,"unit":"days"}}`,
+			patch:  `{"frequency":{"every":3,"unit":null}}`,
+			want:   `{"frequency":{"type":"interval","every":3}}`,
+		},
+		{
+			name:   "arrays are replaced",
+			target: `{"labels":[1,2,3]}`,
+			patch:  `{"labels":[2]}`,
+			want:   `{"labels":[2]}`,
+		},
+		{
+			name:   "an object replaces a scalar",
+			target: `{"notification":false}`,
+			patch:  `{"notification":{"enabled":true,"on_due":null}}`,
+			want:   `{"notification":{"enabled":true}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.target), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApplyKeepsLargeNumbers(t *testing.T) {
+	got, err := Apply([]byte(`{"id":9007199254740993}`), []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":9007199254740993}`, string(got))
+}
+
+func TestApplyRejectsNonObjects(t *testing.T) {
+	for _, patch := range []string{`null`, `[]`, `"title"`, `{`} {
+		_, err := Apply([]byte(`{"title":"Pay rent"}`), []byte(patch))
+		assert.ErrorIs(t, err, ErrNotObject, patch)
+	}
+}
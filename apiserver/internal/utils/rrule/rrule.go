@@ -0,0 +1,585 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq is the base recurrence period of a rule (the RFC 5545 FREQ part).
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal. An ordinal
+// of 0 matches every such weekday, a positive ordinal the nth one from the
+// start of the period and a negative ordinal the nth one from its end.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RFC 5545 recurrence rule. Only the parts that make sense
+// for day-granular task schedules are supported: FREQ, INTERVAL, COUNT,
+// UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS and WKST.
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+	DTStart    *time.Time
+
+	// untilLayout and dtstartLayout are the forms UNTIL and DTSTART were
+	// written in, so that String keeps floating and DATE values floating.
+	untilLayout   string
+	dtstartLayout string
+}
+
+// dateTimeLayout is the UTC form of an RFC 5545 DATE-TIME value.
+const (
+	dateTimeLayout = "20060102T150405Z"
+	floatingLayout = "20060102T150405"
+	dateLayout     = "20060102"
+)
+
+// searchHorizon bounds how far past the requested instant a search may scan
+// before concluding that the rule has no further occurrences. It keeps rules
+// that can never match (e.g. the 30th of February) from looping forever.
+const searchHorizon = 50 * 366 * 24 * time.Hour
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses a recurrence rule. The input may be a bare rule
+// ("FREQ=WEEKLY;BYDAY=MO"), a rule with an "RRULE:" prefix, or a DTSTART line
+// followed by an RRULE line, separated by a newline. Floating and DATE values
+// of DTSTART and UNTIL are read as UTC.
+func Parse(s string) (*Rule, error) {
+	return ParseInLocation(s, time.UTC)
+}
+
+// ParseInLocation is like Parse, but reads floating and DATE values of
+// DTSTART and UNTIL in loc. A DATE UNTIL includes the whole of its day.
+func ParseInLocation(s string, loc *time.Location) (*Rule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("recurrence rule cannot be empty")
+	}
+
+	var rule *Rule
+	var dtstart *time.Time
+	var dtstartLayout string
+
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "DTSTART:"):
+			t, layout, err := parseDateTime(line[len("DTSTART:"):], loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DTSTART: %s", err.Error())
+			}
+			dtstart = &t
+			dtstartLayout = layout
+		case strings.HasPrefix(upper, "DTSTART;"):
+			return nil, errors.New("DTSTART parameters are not supported, use a UTC value")
+		default:
+			if rule != nil {
+				return nil, errors.New("only a single RRULE is supported")
+			}
+
+			var err error
+			rule, err = parseRule(strings.TrimPrefix(upper, "RRULE:"), loc)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if rule == nil {
+		return nil, errors.New("recurrence rule is missing an RRULE")
+	}
+
+	rule.DTStart = dtstart
+	rule.dtstartLayout = dtstartLayout
+	return rule, nil
+}
+
+func parseRule(s string, loc *time.Location) (*Rule, error) {
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule part %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch Freq(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Freq(value)
+			default:
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseBoundedInt(value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseBoundedInt(value, 1, 10000)
+		case "UNTIL":
+			var until time.Time
+			until, rule.untilLayout, err = parseDateTime(value, loc)
+			if rule.untilLayout == dateLayout {
+				until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(value, -31, 31)
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(value, 1, 12)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(value, -366, 366)
+		case "WKST":
+			wd, ok := weekdayCodes[value]
+			if !ok {
+				err = fmt.Errorf("invalid WKST %q", value)
+			}
+			rule.WeekStart = wd
+		default:
+			err = fmt.Errorf("unsupported rule part %s", key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot be used together")
+	}
+
+	if len(rule.BySetPos) > 0 && len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 && len(rule.ByMonth) == 0 {
+		return nil, errors.New("BYSETPOS requires another BYxxx rule part")
+	}
+
+	if rule.Freq == Daily || rule.Freq == Weekly {
+		for _, d := range rule.ByDay {
+			if d.Ordinal != 0 {
+				return nil, fmt.Errorf("BYDAY ordinals are not allowed with FREQ=%s", rule.Freq)
+			}
+		}
+	}
+
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return nil, errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+
+	return rule, nil
+}
+
+// parseDateTime parses a DATE or DATE-TIME value, reading floating and DATE
+// values in loc, and returns the layout it was written in. UTC values are
+// reported with an empty layout.
+func parseDateTime(value string, loc *time.Location) (time.Time, string, error) {
+	if t, err := time.Parse(dateTimeLayout, value); err == nil {
+		return t, "", nil
+	}
+	for _, layout := range []string{floatingLayout, dateLayout} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("%q is not a valid date or date-time", value)
+}
+
+// formatDateTime writes t in the given layout, or as UTC if it is empty.
+func formatDateTime(t time.Time, layout string) string {
+	if layout == "" {
+		return t.UTC().Format(dateTimeLayout)
+	}
+	return t.Format(layout)
+}
+
+func parseBoundedInt(value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%q must be a number between %d and %d", value, lo, hi)
+	}
+	return n, nil
+}
+
+func parseIntList(value string, lo, hi int) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := parseBoundedInt(item, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("%q must not be zero", item)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY value %q", item)
+		}
+
+		wd, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY value %q", item)
+		}
+
+		ordinal := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid BYDAY ordinal %q", item)
+			}
+			ordinal = n
+		}
+
+		out = append(out, WeekdayNum{Ordinal: ordinal, Weekday: wd})
+	}
+	return out, nil
+}
+
+// String serializes the rule back to its textual form, with a DTSTART line
+// first when the rule is anchored.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+formatDateTime(*r.Until, r.untilLayout))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayNames[d.Weekday]
+			if d.Ordinal != 0 {
+				days[i] = strconv.Itoa(d.Ordinal) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+
+	rule := "RRULE:" + strings.Join(parts, ";")
+	if r.DTStart == nil {
+		return rule
+	}
+
+	return "DTSTART:" + formatDateTime(*r.DTStart, r.dtstartLayout) + "\n" + rule
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// Next returns the first occurrence of the rule strictly after the given
+// instant, with the series anchored at start. The second return value is
+// false once the series is exhausted by COUNT or UNTIL.
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+
+	r.iterate(start, after.Add(searchHorizon), func(t time.Time) bool {
+		if t.After(after) {
+			next = t
+			found = true
+			return false
+		}
+		return true
+	})
+
+	return next, found
+}
+
+// iterate walks the occurrences of the rule in chronological order, starting
+// from start, until fn returns false, the series ends, or the period being
+// expanded begins after horizon.
+func (r *Rule) iterate(start, horizon time.Time, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	count := 0
+	for i := 0; ; i++ {
+		periodStart, days := r.expand(start, i*interval)
+		if periodStart.After(horizon) {
+			return
+		}
+
+		for _, t := range r.applySetPos(days) {
+			if t.Before(start) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return
+			}
+
+			count++
+			if !fn(t) {
+				return
+			}
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// expand returns the first day of the period that lies offset periods after
+// the one containing start, and the candidate occurrences within it in
+// chronological order.
+func (r *Rule) expand(start time.Time, offset int) (time.Time, []time.Time) {
+	loc := start.Location()
+	y, m, d := start.Date()
+	hh, mm, ss := start.Clock()
+	ns := start.Nanosecond()
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, ns, loc)
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(y, m, d+offset)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day, day, day) {
+			days = append(days, day)
+		}
+		return day, days
+	case Weekly:
+		back := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first := at(y, m, d-back+7*offset)
+		for k := 0; k < 7; k++ {
+			day := first.AddDate(0, 0, k)
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchesMonth(day) && r.matchesWeekday(day, first, first) {
+				days = append(days, day)
+			}
+		}
+		return first, days
+	case Monthly:
+		first := at(y, m+time.Month(offset), 1)
+		return first, r.expandMonth(first, start.Day())
+	default:
+		first := at(y+offset, time.January, 1)
+		if len(r.ByMonth) > 0 || len(r.ByMonthDay) > 0 || len(r.ByDay) == 0 {
+			months := r.ByMonth
+			if len(months) == 0 && len(r.ByMonthDay) == 0 {
+				months = []int{int(m)}
+			}
+			if len(months) == 0 {
+				months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+
+			months = sortedCopy(months)
+			for _, month := range months {
+				days = append(days, r.expandMonth(at(first.Year(), time.Month(month), 1), start.Day())...)
+			}
+			return first, days
+		}
+
+		// BYDAY without BYMONTH or BYMONTHDAY: ordinals count within the year.
+		last := at(first.Year(), time.December, 31)
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			if r.matchesWeekday(day, first, last) {
+				days = append(days, day)
+			}
+		}
+		return first, days
+	}
+}
+
+// expandMonth returns the matching days of the month starting at first. When
+// neither BYMONTHDAY nor BYDAY is set, the day of the month of the series
+// start is used, and months that are too short are skipped.
+func (r *Rule) expandMonth(first time.Time, defaultDay int) []time.Time {
+	if !r.matchesMonth(first) {
+		return nil
+	}
+
+	last := first.AddDate(0, 1, -1)
+	var days []time.Time
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if defaultDay <= last.Day() {
+			days = append(days, first.AddDate(0, 0, defaultDay-1))
+		}
+		return days
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if r.matchesMonthDay(day) && r.matchesWeekday(day, first, last) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *Rule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == day.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md > 0 && day.Day() == md {
+			return true
+		}
+		if md < 0 && day.Day() == daysInMonth+md+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether day satisfies BYDAY, with ordinals counted
+// within the period spanning first to last.
+func (r *Rule) matchesWeekday(day, first, last time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	fromStart := daysBetween(first, day)/7 + 1
+	fromEnd := daysBetween(day, last)/7 + 1
+	for _, wd := range r.ByDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		if wd.Ordinal == 0 || wd.Ordinal == fromStart || -wd.Ordinal == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// daysBetween counts calendar days from a to b, ignoring time of day and
+// daylight saving shifts.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+
+	picked := make(map[int]bool)
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(days) + pos
+		}
+		if idx >= 0 && idx < len(days) {
+			picked[idx] = true
+		}
+	}
+
+	out := make([]time.Time, 0, len(picked))
+	for i, day := range days {
+		if picked[i] {
+			out = append(out, day)
+		}
+	}
+	return out
+}
+
+func sortedCopy(values []int) []int {
+	out := append([]int(nil), values...)
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,296 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+// occurrences collects up to n occurrences of the rule strictly after start.
+func occurrences(t *testing.T, rule string, start time.Time, n int) []time.Time {
+	t.Helper()
+
+	r, err := Parse(rule)
+	require.NoError(t, err)
+
+	var out []time.Time
+	after := start.Add(-time.Second)
+	for len(out) < n {
+		next, ok := r.Next(start, after)
+		if !ok {
+			break
+		}
+		out = append(out, next)
+		after = next
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse("FREQ=MONTHLY;INTERVAL=3;BYDAY=-1FR;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, Monthly, r.Freq)
+	assert.Equal(t, 3, r.Interval)
+	assert.Equal(t, 4, r.Count)
+	assert.Equal(t, []WeekdayNum{{Ordinal: -1, Weekday: time.Friday}}, r.ByDay)
+	assert.Nil(t, r.DTStart)
+
+	r, err = Parse("DTSTART:20250103T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20250301")
+	require.NoError(t, err)
+	require.NotNil(t, r.DTStart)
+	assert.Equal(t, date(2025, time.January, 3), *r.DTStart)
+	require.NotNil(t, r.Until)
+	assert.Equal(t, time.Date(2025, time.March, 1, 23, 59, 59, 999999999, time.UTC), *r.Until)
+}
+
+func TestParseInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	r, err := ParseInLocation("DTSTART:20250103T090000\nRRULE:FREQ=DAILY;UNTIL=20250105", loc)
+	require.NoError(t, err)
+	require.NotNil(t, r.DTStart)
+	assert.True(t, time.Date(2025, time.January, 3, 9, 0, 0, 0, loc).Equal(*r.DTStart))
+	require.NotNil(t, r.Until)
+	assert.True(t, time.Date(2025, time.January, 6, 0, 0, 0, 0, loc).Add(-time.Nanosecond).Equal(*r.Until))
+
+	// 09:00 in New York is 14:00 UTC, after midnight UTC on the UNTIL date.
+	var got []time.Time
+	after := r.DTStart.Add(-time.Second)
+	for {
+		next, ok := r.Next(*r.DTStart, after)
+		if !ok {
+			break
+		}
+		got = append(got, next)
+		after = next
+	}
+	require.Len(t, got, 3)
+	assert.True(t, time.Date(2025, time.January, 5, 9, 0, 0, 0, loc).Equal(got[2]))
+
+	assert.Equal(t, "DTSTART:20250103T090000\nRRULE:FREQ=DAILY;UNTIL=20250105", r.String())
+
+	r, err = ParseInLocation("FREQ=DAILY;UNTIL=20250105T090000Z", loc)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, time.January, 5, 9, 0, 0, 0, time.UTC).Equal(*r.Until))
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	invalid := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=3",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYWEEKNO=1",
+		"DTSTART;TZID=Europe/Paris:20250101T090000\nRRULE:FREQ=DAILY",
+	}
+
+	for _, rule := range invalid {
+		_, err := Parse(rule)
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	original := "DTSTART:20250103T090000Z\nRRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=5;BYDAY=2TU,-1FR;BYSETPOS=1;WKST=SU"
+
+	r, err := Parse(original)
+	require.NoError(t, err)
+	assert.Equal(t, original, r.String())
+
+	reparsed, err := Parse(r.String())
+	require.NoError(t, err)
+	assert.Equal(t, r, reparsed)
+}
+
+func TestNext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		expected []time.Time
+	}{
+		{
+			name:  "Daily with interval",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: date(2025, time.January, 30),
+			expected: []time.Time{
+				date(2025, time.January, 30),
+				date(2025, time.February, 1),
+				date(2025, time.February, 3),
+			},
+		},
+		{
+			name:  "Weekly on several days",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start: date(2025, time.January, 1), // Wednesday
+			expected: []time.Time{
+				date(2025, time.January, 1),
+				date(2025, time.January, 3),
+				date(2025, time.January, 6),
+				date(2025, time.January, 8),
+			},
+		},
+		{
+			name:  "Every other week on Tuesday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			start: date(2025, time.January, 1), // Tuesday of the first week precedes the start
+			expected: []time.Time{
+				date(2025, time.January, 14),
+				date(2025, time.January, 28),
+				date(2025, time.February, 11),
+			},
+		},
+		{
+			name:  "1st and 15th of each month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			start: date(2025, time.January, 10),
+			expected: []time.Time{
+				date(2025, time.January, 15),
+				date(2025, time.February, 1),
+				date(2025, time.February, 15),
+			},
+		},
+		{
+			name:  "Last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2024, time.January, 1),
+			expected: []time.Time{
+				date(2024, time.January, 31),
+				date(2024, time.February, 29),
+				date(2024, time.March, 31),
+			},
+		},
+		{
+			name:  "Last Friday of every quarter",
+			rule:  "FREQ=YEARLY;BYMONTH=3,6,9,12;BYDAY=-1FR",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.March, 28),
+				date(2025, time.June, 27),
+				date(2025, time.September, 26),
+				date(2025, time.December, 26),
+			},
+		},
+		{
+			name:  "Second Tuesday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.January, 14),
+				date(2025, time.February, 11),
+			},
+		},
+		{
+			name:  "Last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: date(2025, time.May, 1),
+			expected: []time.Time{
+				date(2025, time.May, 30),
+				date(2025, time.June, 30),
+			},
+		},
+		{
+			name:  "Monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY",
+			start: date(2025, time.January, 31),
+			expected: []time.Time{
+				date(2025, time.January, 31),
+				date(2025, time.March, 31),
+				date(2025, time.May, 31),
+			},
+		},
+		{
+			name:  "Yearly on a leap day",
+			rule:  "FREQ=YEARLY",
+			start: date(2024, time.February, 29),
+			expected: []time.Time{
+				date(2024, time.February, 29),
+				date(2028, time.February, 29),
+			},
+		},
+		{
+			name:  "First Monday of the year",
+			rule:  "FREQ=YEARLY;BYDAY=1MO",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.January, 6),
+				date(2026, time.January, 5),
+			},
+		},
+		{
+			name:  "Count limits the series",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.January, 1),
+				date(2025, time.January, 2),
+			},
+		},
+		{
+			name:  "Until limits the series",
+			rule:  "FREQ=WEEKLY;UNTIL=20250115T090000Z",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.January, 1),
+				date(2025, time.January, 8),
+				date(2025, time.January, 15),
+			},
+		},
+		{
+			name:  "Date-only until includes its last day",
+			rule:  "FREQ=DAILY;UNTIL=20250103",
+			start: date(2025, time.January, 1),
+			expected: []time.Time{
+				date(2025, time.January, 1),
+				date(2025, time.January, 2),
+				date(2025, time.January, 3),
+			},
+		},
+		{
+			name:     "Impossible rule never matches",
+			rule:     "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start:    date(2025, time.January, 1),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := occurrences(t, tc.rule, tc.start, len(tc.expected)+1)
+			if len(got) > len(tc.expected) {
+				got = got[:len(tc.expected)]
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestNextCountsFromSeriesStart(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;COUNT=3")
+	require.NoError(t, err)
+
+	start := date(2025, time.January, 1)
+
+	next, ok := r.Next(start, date(2025, time.January, 2))
+	require.True(t, ok)
+	assert.Equal(t, date(2025, time.January, 3), next)
+
+	_, ok = r.Next(start, date(2025, time.January, 3))
+	assert.False(t, ok)
+}
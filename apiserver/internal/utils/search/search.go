@@ -0,0 +1,401 @@
+// Package search parses the task search language, such as
+//
+//	label:home due:<7d overdue is:recurring "exact phrase" -label:work
+//
+// into a Query. A task matches a query when it matches every term; a term
+// prefixed with '-' matches the tasks the unprefixed term does not. Words and
+// quoted phrases match the task's text, while field:value terms filter on one
+// of its attributes:
+//
+//	label:NAME       carries the label NAME (quote names with spaces)
+//	due:[OP]DATE     is due relative to DATE, or due:none for no due date
+//	priority:[OP]P   has priority P, a number or none, low, medium, high, urgent
+//	is:STATE         overdue, recurring, once or blocked
+//	has:FIELD        notes, labels, due, notifications or checklist
+//
+// OP is one of <, <=, >, >= or = (the default). DATE is YYYY-MM-DD, today,
+// tomorrow, yesterday or a number of days or weeks from today such as 7d,
+// -1d or 2w. The bare word overdue is short for is:overdue.
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed search.
+type Query struct {
+	Terms []Term
+}
+
+// Term is one condition of a query.
+type Term struct {
+	Negated bool
+	Filter  Filter
+	// Offset is the position of the term in the query, in characters.
+	Offset int
+}
+
+// Filter is the condition a term places on a task. It is one of Text, Label,
+// Due, Priority, Is or Has.
+type Filter interface {
+	filter()
+}
+
+// Text matches tasks whose text contains Value, ignoring case.
+type Text struct {
+	Value string
+}
+
+// Label matches tasks carrying a label named Name, ignoring case.
+type Label struct {
+	Name string
+}
+
+// Due matches tasks by due date. None matches tasks without one; otherwise a
+// task matches when its due day compares to Date as Op says.
+type Due struct {
+	Op   Op
+	None bool
+	Date Date
+}
+
+// Priority matches tasks whose priority compares to Level as Op says.
+type Priority struct {
+	Op    Op
+	Level int
+}
+
+// Is matches tasks in the given state.
+type Is struct {
+	State State
+}
+
+// Has matches tasks with a value for the given field.
+type Has struct {
+	Field Field
+}
+
+func (Text) filter()     {}
+func (Label) filter()    {}
+func (Due) filter()      {}
+func (Priority) filter() {}
+func (Is) filter()       {}
+func (Has) filter()      {}
+
+// Op compares a task's value to the value of a term.
+type Op string
+
+const (
+	Equal        Op = "="
+	Less         Op = "<"
+	LessEqual    Op = "<="
+	Greater      Op = ">"
+	GreaterEqual Op = ">="
+)
+
+// State is a state an Is term matches.
+type State string
+
+const (
+	Overdue   State = "overdue"
+	Recurring State = "recurring"
+	Once      State = "once"
+	Blocked   State = "blocked"
+)
+
+// Field is a field a Has term matches.
+type Field string
+
+const (
+	Notes         Field = "notes"
+	Labels        Field = "labels"
+	DueDate       Field = "due"
+	Notifications Field = "notifications"
+	Checklist     Field = "checklist"
+)
+
+// priorityLevels maps priority names to their levels.
+var priorityLevels = map[string]int{
+	"none":   0,
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+	"urgent": 4,
+}
+
+// MaxPriority is the highest priority level a term may name.
+const MaxPriority = 4
+
+// Date is a calendar day, either absolute or a number of days from today.
+type Date struct {
+	Relative bool
+	Days     int
+	Year     int
+	Month    time.Month
+	Day      int
+}
+
+// Start returns the start of the day in loc, resolving relative days from
+// now.
+func (d Date) Start(now time.Time, loc *time.Location) time.Time {
+	if d.Relative {
+		now = now.In(loc)
+		return time.Date(now.Year(), now.Month(), now.Day()+d.Days, 0, 0, 0, 0, loc)
+	}
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// ParseError reports why a query could not be parsed and where.
+type ParseError struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+	Offset  int    `json:"offset"`
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at %q (offset %d)", e.Message, e.Token, e.Offset)
+}
+
+// maxTerms bounds how many terms a query may have.
+const maxTerms = 32
+
+// Parse parses a search query. An empty query has no terms and matches every
+// task.
+func Parse(query string) (*Query, error) {
+	p := &parser{input: []rune(query)}
+	q := &Query{}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) {
+			return q, nil
+		}
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if len(q.Terms) == maxTerms {
+			return nil, &ParseError{
+				Message: fmt.Sprintf("query has more than %d terms", maxTerms),
+				Token:   p.token(term.Offset),
+				Offset:  term.Offset,
+			}
+		}
+		q.Terms = append(q.Terms, term)
+	}
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// token returns the whitespace separated token starting at offset, for error
+// messages.
+func (p *parser) token(offset int) string {
+	end := offset
+	for end < len(p.input) && !unicode.IsSpace(p.input[end]) {
+		end++
+	}
+	return string(p.input[offset:end])
+}
+
+func (p *parser) term() (Term, error) {
+	term := Term{Offset: p.pos}
+	if p.input[p.pos] == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+		term.Negated = true
+		p.pos++
+	}
+
+	if p.input[p.pos] == '"' {
+		phrase, err := p.quoted()
+		if err != nil {
+			return term, err
+		}
+		if phrase == "" {
+			return term, &ParseError{Message: "empty phrase", Token: `""`, Offset: term.Offset}
+		}
+		term.Filter = Text{Value: phrase}
+		return term, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !unicode.IsSpace(p.input[p.pos]) && p.input[p.pos] != ':' && p.input[p.pos] != '"' {
+		p.pos++
+	}
+	word := string(p.input[start:p.pos])
+
+	if p.pos < len(p.input) && p.input[p.pos] == ':' && isFieldName(word) {
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return term, err
+		}
+		filter, err := fieldFilter(strings.ToLower(word), value)
+		if err != nil {
+			return term, &ParseError{Message: err.Error(), Token: string(p.input[term.Offset:p.pos]), Offset: term.Offset}
+		}
+		term.Filter = filter
+		return term, nil
+	}
+
+	// Anything else, colons and quotes included, is a plain word.
+	for p.pos < len(p.input) && !unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	word = string(p.input[start:p.pos])
+	if strings.EqualFold(word, string(Overdue)) {
+		term.Filter = Is{State: Overdue}
+		return term, nil
+	}
+	term.Filter = Text{Value: word}
+	return term, nil
+}
+
+// quoted reads a double-quoted string starting at the current position.
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.input) && p.input[p.pos] != '"' {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return "", &ParseError{Message: "unterminated quote", Token: string(p.input[start:]), Offset: start}
+	}
+	p.pos++
+	return strings.TrimSpace(string(p.input[start+1 : p.pos-1])), nil
+}
+
+// value reads the value of a field:value term, which may be quoted.
+func (p *parser) value() (string, error) {
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		return p.quoted()
+	}
+	start := p.pos
+	for p.pos < len(p.input) && !unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos]), nil
+}
+
+// isFieldName reports whether word, followed by a colon, names a field. Words
+// that are not made of letters, such as the 10 in 10:30, are plain text, but
+// an unknown field name is an error rather than silently searched for.
+func isFieldName(word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldFilter(field, value string) (Filter, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s", field)
+	}
+	lower := strings.ToLower(value)
+
+	switch field {
+	case "label":
+		return Label{Name: value}, nil
+
+	case "due":
+		if lower == "none" {
+			return Due{None: true}, nil
+		}
+		op, rest := splitOp(lower)
+		date, err := parseDate(rest)
+		if err != nil {
+			return nil, err
+		}
+		return Due{Op: op, Date: date}, nil
+
+	case "priority":
+		op, rest := splitOp(lower)
+		level, ok := priorityLevels[rest]
+		if !ok {
+			n, err := strconv.Atoi(rest)
+			if err != nil || n < 0 || n > MaxPriority {
+				return nil, fmt.Errorf("priority must be between 0 and %d or one of none, low, medium, high, urgent", MaxPriority)
+			}
+			level = n
+		}
+		return Priority{Op: op, Level: level}, nil
+
+	case "is":
+		switch state := State(lower); state {
+		case Overdue, Recurring, Once, Blocked:
+			return Is{State: state}, nil
+		}
+		return nil, fmt.Errorf("unknown state %q", value)
+
+	case "has":
+		switch f := Field(lower); f {
+		case Notes, Labels, DueDate, Notifications, Checklist:
+			return Has{Field: f}, nil
+		}
+		return nil, fmt.Errorf("unknown field %q", value)
+
+	default:
+		return nil, fmt.Errorf("unknown filter %q", field)
+	}
+}
+
+// splitOp splits a leading comparison operator off value, defaulting to Equal.
+func splitOp(value string) (Op, string) {
+	for _, op := range []Op{LessEqual, GreaterEqual, Less, Greater, Equal} {
+		if rest, ok := strings.CutPrefix(value, string(op)); ok {
+			return op, rest
+		}
+	}
+	return Equal, value
+}
+
+// maxRelativeDays bounds relative dates so that they stay within a sensible
+// range of years.
+const maxRelativeDays = 100 * 366
+
+func parseDate(value string) (Date, error) {
+	switch value {
+	case "today":
+		return Date{Relative: true}, nil
+	case "tomorrow":
+		return Date{Relative: true, Days: 1}, nil
+	case "yesterday":
+		return Date{Relative: true, Days: -1}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()}, nil
+	}
+
+	if len(value) > 1 {
+		unit := value[len(value)-1]
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && (unit == 'd' || unit == 'w') {
+			if unit == 'w' {
+				n *= 7
+			}
+			if n < -maxRelativeDays || n > maxRelativeDays {
+				return Date{}, fmt.Errorf("date %q is too far away", value)
+			}
+			return Date{Relative: true, Days: n}, nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD, today, tomorrow, yesterday or a number of days or weeks such as 7d", value)
+}
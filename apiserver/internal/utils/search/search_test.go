@@ -0,0 +1,90 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`label:home due:<7d overdue is:recurring "exact phrase" -label:work label:"Home office" 10:30 -`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Term{
+		{Filter: Label{Name: "home"}, Offset: 0},
+		{Filter: Due{Op: Less, Date: Date{Relative: true, Days: 7}}, Offset: 11},
+		{Filter: Is{State: Overdue}, Offset: 19},
+		{Filter: Is{State: Recurring}, Offset: 27},
+		{Filter: Text{Value: "exact phrase"}, Offset: 40},
+		{Negated: true, Filter: Label{Name: "work"}, Offset: 55},
+		{Filter: Label{Name: "Home office"}, Offset: 67},
+		{Filter: Text{Value: "10:30"}, Offset: 87},
+		{Filter: Text{Value: "-"}, Offset: 93},
+	}, q.Terms)
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Filter
+	}{
+		{"due:none", Due{None: true}},
+		{"due:2025-01-15", Due{Op: Equal, Date: Date{Year: 2025, Month: time.January, Day: 15}}},
+		{"due:>=today", Due{Op: GreaterEqual, Date: Date{Relative: true}}},
+		{"due:<=2w", Due{Op: LessEqual, Date: Date{Relative: true, Days: 14}}},
+		{"due:>-1d", Due{Op: Greater, Date: Date{Relative: true, Days: -1}}},
+		{"priority:HIGH", Priority{Op: Equal, Level: 3}},
+		{"priority:<2", Priority{Op: Less, Level: 2}},
+		{"has:checklist", Has{Field: Checklist}},
+		{"is:blocked", Is{State: Blocked}},
+		{`"overdue"`, Text{Value: "overdue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, err := Parse(tt.in)
+			require.NoError(t, err)
+			require.Len(t, q.Terms, 1)
+			assert.Equal(t, tt.want, q.Terms[0].Filter)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ParseError
+	}{
+		{`fence "paint`, ParseError{Message: "unterminated quote", Token: `"paint`, Offset: 6}},
+		{`fence lable:home`, ParseError{Message: `unknown filter "lable"`, Token: "lable:home", Offset: 6}},
+		{`due:soon`, ParseError{Token: "due:soon", Offset: 0}},
+		{`x -priority:9`, ParseError{Token: "-priority:9", Offset: 2}},
+		{`is:done`, ParseError{Message: `unknown state "done"`, Token: "is:done", Offset: 0}},
+		{`label:`, ParseError{Message: "missing value for label", Token: "label:", Offset: 0}},
+		{`""`, ParseError{Message: "empty phrase", Token: `""`, Offset: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			if tt.want.Message != "" {
+				assert.Equal(t, tt.want.Message, parseErr.Message)
+			}
+			assert.Equal(t, tt.want.Token, parseErr.Token)
+			assert.Equal(t, tt.want.Offset, parseErr.Offset)
+		})
+	}
+}
+
+func TestDateStart(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2025, time.March, 1, 3, 0, 0, 0, time.UTC) // still Feb 28 in New York
+
+	assert.Equal(t, time.Date(2025, time.March, 7, 0, 0, 0, 0, loc), Date{Relative: true, Days: 7}.Start(now, loc))
+	assert.Equal(t, time.Date(2025, time.January, 15, 0, 0, 0, 0, loc), Date{Year: 2025, Month: time.January, Day: 15}.Start(now, loc))
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -26,11 +27,15 @@ import (
 	"gorm.io/gorm"
 
 	apis "taskwiz.app/core/internal/apis"
+	cRepo "taskwiz.app/core/internal/repos/calendar"
+	fRepo "taskwiz.app/core/internal/repos/filter"
 	lRepo "taskwiz.app/core/internal/repos/label"
 	nRepo "taskwiz.app/core/internal/repos/notifier"
 	sRepo "taskwiz.app/core/internal/repos/session"
 	tRepo "taskwiz.app/core/internal/repos/task"
 	uRepo "taskwiz.app/core/internal/repos/user"
+	cService "taskwiz.app/core/internal/services/calendars"
+	fService "taskwiz.app/core/internal/services/filters"
 	lService "taskwiz.app/core/internal/services/labels"
 	logging "taskwiz.app/core/internal/services/logging"
 	notifier "taskwiz.app/core/internal/services/notifications"
@@ -88,11 +93,19 @@ func main() {
 		fx.Provide(lRepo.NewLabelRepository),
 		fx.Provide(lService.NewLabelService),
 		fx.Provide(lService.NewLabelsMessageHandler),
+		fx.Provide(cRepo.NewCalendarRepository),
+		fx.Provide(cService.NewCalendarService),
+		fx.Provide(cService.NewCalendarsMessageHandler),
+		fx.Provide(fRepo.NewFilterRepository),
+		fx.Provide(fService.NewFilterService),
+		fx.Provide(fService.NewFiltersMessageHandler),
 		fx.Provide(uService.NewUserService),
 		fx.Provide(uService.NewUsersMessageHandler),
 		fx.Provide(tService.NewTaskService),
 		fx.Provide(tService.NewTasksMessageHandler),
 		fx.Provide(apis.LabelsAPI),
+		fx.Provide(apis.CalendarsAPI),
+		fx.Provide(apis.FiltersAPI),
 		fx.Provide(apis.LogsAPI),
 
 		fx.Provide(frontend.NewHandler),
@@ -102,10 +115,14 @@ func main() {
 			apis.TaskRoutes,
 			apis.UserRoutes,
 			apis.LabelRoutes,
+			apis.CalendarRoutes,
+			apis.FilterRoutes,
 			apis.LogRoutes,
 			ws.Routes,
 			tService.TaskMessages,
 			lService.LabelMessages,
+			cService.CalendarMessages,
+			fService.FilterMessages,
 			uService.UserMessages,
 			frontend.Routes,
 			backend.Routes,
@@ -167,6 +184,8 @@ func newServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB, bgScheduler *sc
 		}
 		corsCfg.AddAllowHeaders("Authorization")
 		corsCfg.AddAllowHeaders("DNT")
+		corsCfg.AddAllowHeaders("If-Match")
+		corsCfg.AddExposeHeaders("ETag")
 		r.Use(cors.New(corsCfg))
 	}
 	r.Use(utils.SecurityHeaders(cfg))
@@ -67,9 +67,9 @@ export const DeleteTask = async (id: number) =>
     ws: (ws) => ws.request('delete_task', id),
   })
 
-export const SaveTask = async (task: Task) =>
+export const SaveTask = async (task: Task): Promise<SingleTaskResponse> =>
   await transport({
-    http: () => Request<void>(`/tasks/`, 'PUT', MarshallLabels(task)),
+    http: () => Request<SingleTaskResponse>(`/tasks/`, 'PUT', MarshallLabels(task)),
     ws: (ws) => ws.request('update_task', MarshallLabels(task)),
   })
 
@@ -82,15 +82,18 @@ export const GetTaskHistory = async (taskId: number) =>
 export const UpdateDueDate = async (
   id: number,
   due_date: string,
+  version?: number,
 ): Promise<SingleTaskResponse> =>
   await transport({
     http: () =>
       Request<SingleTaskResponse>(`/tasks/${id}/dueDate`, 'PUT', {
         due_date,
+        version,
       }),
     ws: (ws) =>
       ws.request('update_due_date', {
         id,
         due_date,
+        version,
       }),
   })
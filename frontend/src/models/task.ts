@@ -72,6 +72,9 @@ export interface Task {
   is_rolling: boolean
   labels: Label[]
   end_date: string | null
+  // The version the task was read at, sent back with edits. Unset for new
+  // tasks.
+  version?: number
 }
 
 export const newTask = (): Task => ({
@@ -44,6 +44,7 @@ export interface WSActionPayloads {
   update_due_date: {
     id: number
     due_date: string
+    version?: number
   }
   complete_task: {
     id: number
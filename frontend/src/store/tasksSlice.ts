@@ -94,13 +94,16 @@ export const createTask = createAsyncThunk(
 
 export const saveTask = createAsyncThunk(
   'tasks/saveTask',
-  async (task: Task) => await SaveTask(task),
+  async (task: Task) => {
+    const response = await SaveTask(task)
+    return response.task
+  },
 )
 
 export const updateDueDate = createAsyncThunk(
   'tasks/updateDueDate',
-  async ({ taskId, dueDate }: { taskId: number; dueDate: string }) => {
-    const response = await UpdateDueDate(taskId, dueDate)
+  async ({ taskId, dueDate, version }: { taskId: number; dueDate: string; version?: number }) => {
+    const response = await UpdateDueDate(taskId, dueDate, version)
     return response.task
   },
 )
@@ -300,7 +303,9 @@ const tasksSlice = createSlice({
       .addCase(saveTask.fulfilled, (state, action) => {
         state.status = 'succeeded'
 
-        const updatedTask = action.meta.arg
+        // Keep the labels as edited, which the response only has ids of,
+        // and take the version the edit moved the task to.
+        const updatedTask = { ...action.meta.arg, version: action.payload?.version }
         tasksSlice.caseReducers.taskUpserted(state, {
           payload: updatedTask,
           type: 'tasks/taskUpserted',
@@ -52,7 +52,7 @@ type MyTasksProps = {
   completeTask: (taskId: number, endRecurrence: boolean) => Promise<any>
   deleteTask: (taskId: number) => Promise<any>
   skipTask: (taskId: number) => Promise<any>
-  updateDueDate: (taskId: number, dueDate: string) => Promise<any>
+  updateDueDate: (taskId: number, dueDate: string, version?: number) => Promise<any>
   pushStatus: (status: Status) => void
 } & WithNavigate
 
@@ -219,7 +219,7 @@ class MyTasksImpl extends React.Component<MyTasksProps, MyTasksState> {
         return
       }
 
-      await this.props.updateDueDate(task.id, MarshallDate(newDate))
+      await this.props.updateDueDate(task.id, MarshallDate(newDate), task.version)
 
       this.onEvent('rescheduled')
     })
@@ -470,7 +470,8 @@ const mapDispatchToProps = (dispatch: AppDispatch) => ({
   completeTask: (taskId: number, endRecurrence: boolean) => dispatch(completeTask({ taskId, endRecurrence })),
   deleteTask: (taskId: number) => dispatch(deleteTask(taskId)),
   skipTask: (taskId: number) => dispatch(skipTask(taskId)),
-  updateDueDate: (taskId: number, dueDate: string) => dispatch(updateDueDate({ taskId, dueDate })),
+  updateDueDate: (taskId: number, dueDate: string, version?: number) =>
+    dispatch(updateDueDate({ taskId, dueDate, version })),
   pushStatus: (status: Status) => dispatch(pushStatus(status)),
 })
 
@@ -40,7 +40,7 @@ type TasksOverviewProps = {
   filterTasks: (searchQuery: string) => void
   completeTask: (taskId: number, endRecurrence: boolean) => Promise<any>
   deleteTask: (taskId: number) => Promise<any>
-  updateDueDate: (taskId: number, dueDate: string) => Promise<any>
+  updateDueDate: (taskId: number, dueDate: string, version?: number) => Promise<any>
   pushStatus: (status: Status) => void
 } & WithNavigate
 
@@ -109,7 +109,7 @@ class TasksOverviewImpl extends React.Component<TasksOverviewProps> {
         return
       }
 
-      await this.props.updateDueDate(task.id, MarshallDate(newDate))
+      await this.props.updateDueDate(task.id, MarshallDate(newDate), task.version)
 
       this.props.pushStatus({
         message: 'Task rescheduled',
@@ -345,7 +345,8 @@ const mapDispatchToProps = (dispatch: AppDispatch) => ({
   filterTasks: (searchQuery: string) => dispatch(filterTasks(searchQuery)),
   completeTask: (taskId: number, endRecurrence: boolean) => dispatch(completeTask({ taskId, endRecurrence })),
   deleteTask: (taskId: number) => dispatch(deleteTask(taskId)),
-  updateDueDate: (taskId: number, dueDate: string) => dispatch(updateDueDate({ taskId, dueDate })),
+  updateDueDate: (taskId: number, dueDate: string, version?: number) =>
+    dispatch(updateDueDate({ taskId, dueDate, version })),
   pushStatus: (status: Status) => dispatch(pushStatus(status)),
 })
 
@@ -27,6 +27,9 @@ public class Task
 
     [JsonPropertyName("labels")]
     public List<Label> Labels { get; set; } = new();
+
+    [JsonPropertyName("version")]
+    public int Version { get; set; }
 }
 
 public class Frequency
@@ -114,4 +117,7 @@ public class UpdateTaskRequest
 
     [JsonPropertyName("labels")]
     public List<int> Labels { get; set; } = new();
+
+    [JsonPropertyName("version")]
+    public int Version { get; set; }
 }
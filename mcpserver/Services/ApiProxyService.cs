@@ -1,6 +1,7 @@
 using System.Text;
 using System.Text.Json;
 using System.Text.Json.Serialization;
+using TaskWizard.McpServer.Models;
 
 namespace TaskWizard.McpServer.Services;
 
@@ -54,14 +55,17 @@ public class ApiProxyService(IHttpClientFactory httpClientFactory, IHttpContextA
 
     // Tasks
 
+    // The task list is paginated by cursor, but without a limit every matching
+    // task comes back in one page: { "tasks": [...], "next_cursor": null }.
+
     public Task<string> GetAllTasks() =>
         SendAsync(HttpMethod.Get, "api/v1/tasks/");
 
     public Task<string> GetTasksDueBefore(string before) =>
-        SendAsync(HttpMethod.Get, $"api/v1/tasks/due?before={Uri.EscapeDataString(before)}");
+        SendAsync(HttpMethod.Get, $"api/v1/tasks/?due_before={Uri.EscapeDataString(before)}");
 
     public Task<string> GetTasksByLabel(int labelId) =>
-        SendAsync(HttpMethod.Get, $"api/v1/tasks/label/{labelId}");
+        SendAsync(HttpMethod.Get, $"api/v1/tasks/?labels={labelId}");
 
     public Task<string> SearchTasksByTitle(string query) =>
         SendAsync(HttpMethod.Get, $"api/v1/tasks/search?q={Uri.EscapeDataString(query)}");
@@ -72,7 +76,9 @@ public class ApiProxyService(IHttpClientFactory httpClientFactory, IHttpContextA
     public Task<string> CreateTask(object request) =>
         SendAsync(HttpMethod.Post, "api/v1/tasks/", request);
 
-    public Task<string> UpdateTask(object request) =>
+    // An update names the version of the task it was made to, and is rejected
+    // with a conflict if the task has changed since.
+    public Task<string> UpdateTask(UpdateTaskRequest request) =>
         SendAsync(HttpMethod.Put, "api/v1/tasks/", request);
 
     public Task<string> DeleteTask(int id) =>
@@ -68,6 +68,7 @@ public class TaskTools(ApiProxyService api)
     public Task<string> UpdateTask(
         [Description("Task ID")] int id,
         [Description("Task title")] string title,
+        [Description("The task's version as returned by GetTask. The update is rejected with a conflict if the task has changed since")] int version,
         [Description("Next due date (ISO 8601 format, e.g. 2025-01-15T00:00:00Z)")] string? nextDueDate = null,
         [Description("End date (ISO 8601 format)")] string? endDate = null,
         [Description("Frequency type: once, daily, weekly, monthly, yearly")] string frequencyType = "once",
@@ -81,7 +82,8 @@ public class TaskTools(ApiProxyService api)
             EndDate = endDate,
             IsRolling = isRolling,
             Frequency = new Frequency { Type = frequencyType },
-            Labels = labels?.ToList() ?? []
+            Labels = labels?.ToList() ?? [],
+            Version = version
         });
 
     [McpServerTool, Description("Delete a task")]
@@ -110,8 +112,8 @@ public class TaskTools(ApiProxyService api)
         [Description("Label ID")] int labelId) =>
         api.GetTasksByLabel(labelId);
 
-    [McpServerTool, Description("Search active tasks by a case-insensitive substring of their title")]
+    [McpServerTool, Description("Search active tasks by words in their title or label names, best matches first. Returns the matching tasks, plus each match's score and highlighted snippet under results")]
     public Task<string> SearchTasksByTitle(
-        [Description("Substring to match against task titles (case-insensitive)")] string query) =>
+        [Description("Words to search for; each must start a word of the title or a label name (case-insensitive)")] string query) =>
         api.SearchTasksByTitle(query);
 }